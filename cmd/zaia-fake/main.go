@@ -0,0 +1,14 @@
+// Command zaia-fake is a stateful stand-in for the zaia CLI used by
+// hermetic end-to-end tests. Install or build it as "zaia"; the simulated
+// project lives in the file named by ZAIA_FAKE_STATE.
+package main
+
+import (
+	"os"
+
+	"github.com/zeropsio/zaia-mcp/internal/fakezerops"
+)
+
+func main() {
+	os.Exit(fakezerops.RunZaia(fakezerops.StatePath(), os.Args[1:], os.Stdout, os.Stderr))
+}
@@ -2,12 +2,23 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log/slog"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
+	"time"
 
+	"github.com/zeropsio/zaia-mcp/internal/audit"
+	"github.com/zeropsio/zaia-mcp/internal/config"
+	"github.com/zeropsio/zaia-mcp/internal/executor"
+	"github.com/zeropsio/zaia-mcp/internal/metrics"
 	"github.com/zeropsio/zaia-mcp/internal/server"
 )
 
@@ -19,10 +30,138 @@ func main() {
 }
 
 func run() error {
+	configPath := flag.String("config", config.DefaultPath(), "JSON config file (subprocess env, Zerops token, profiles)")
+	metricsAddr := flag.String("metrics-listen", "", "serve Prometheus metrics on this address at /metrics (e.g. 127.0.0.1:9464; empty: disabled)")
+	auditPath := flag.String("audit-log", defaultAuditPath(), "JSONL audit log of mutating tool calls (empty: in-memory only)")
+	transport := flag.String("transport", "stdio", "MCP transport: stdio or http (streamable HTTP)")
+	listen := flag.String("listen", "127.0.0.1:8080", "address for -transport http")
+	authToken := flag.String("auth-token", os.Getenv("ZAIA_MCP_AUTH_TOKEN"), "bearer token required by -transport http (default $ZAIA_MCP_AUTH_TOKEN)")
+	authTokenFile := flag.String("auth-token-file", "", "file with accepted bearer tokens, one per line")
+	allowedOrigins := flag.String("allowed-origins", "", "comma-separated browser origins allowed by -transport http")
+	readOnly := flag.Bool("read-only", false, "expose only read-only tools and reject calls that change the project")
+	enableTools := flag.String("enable-tools", "", "comma-separated tools to expose (default: all; zerops_ prefix optional)")
+	disableTools := flag.String("disable-tools", "", "comma-separated tools to hide")
+	projectSummary := flag.Bool("project-summary", false, "add the project's services (zaia discover at startup) to the server instructions")
+	minZaia := flag.String("min-zaia-version", "", "oldest zaia version the dependency check accepts (empty: any)")
+	minZcli := flag.String("min-zcli-version", "", "oldest zcli version the dependency check accepts (empty: any)")
+	instructionsBudget := flag.Int("instructions-budget", server.DefaultInstructionsBudget, "token budget of the server instructions (negative: unlimited)")
+	flag.Parse()
+
+	if *transport != "stdio" && *transport != "http" {
+		return fmt.Errorf("unknown -transport %q (stdio or http)", *transport)
+	}
+	enabled, disabled := splitList(*enableTools), splitList(*disableTools)
+	if err := server.ValidateToolNames(append(enabled, disabled...)...); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		return err
+	}
+	exec, err := server.ExecutorFromConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("config %s: %w", *configPath, err)
+	}
+
+	// Output spilled by earlier runs is only kept for a while.
+	executor.RemoveStaleSpills(executor.SpillTTL)
+
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
 	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
 
-	return server.NewWithLogger(logger).Run(ctx)
+	opts := server.Options{
+		Logger:       logger,
+		Metrics:      metrics.New(),
+		ReadOnly:     *readOnly,
+		EnableTools:  enabled,
+		DisableTools: disabled,
+
+		ProjectSummary:     *projectSummary,
+		InstructionsBudget: *instructionsBudget,
+		MinVersions:        map[string]string{"zaia": *minZaia, "zcli": *minZcli},
+	}
+	if *metricsAddr != "" {
+		stop, err := serveMetrics(*metricsAddr, opts.Metrics, logger)
+		if err != nil {
+			return err
+		}
+		defer stop()
+	}
+	if *auditPath != "" {
+		auditLog, err := audit.Open(*auditPath, audit.DefaultKeep)
+		if err != nil {
+			return err
+		}
+		defer auditLog.Close()
+		opts.AuditLog = auditLog
+	}
+
+	srv := server.NewWithOptions(exec, opts)
+	if *transport == "stdio" {
+		return srv.Run(ctx)
+	}
+	httpOpts, err := httpOptions(*authToken, *authTokenFile, *allowedOrigins)
+	if err != nil {
+		return err
+	}
+	return srv.RunHTTP(ctx, *listen, httpOpts)
+}
+
+// httpOptions builds the HTTP transport options from flag values.
+func httpOptions(token, tokenFile, origins string) (server.HTTPOptions, error) {
+	var opts server.HTTPOptions
+	if token != "" {
+		opts.Tokens = append(opts.Tokens, token)
+	}
+	if tokenFile != "" {
+		tokens, err := server.ReadTokenFile(tokenFile)
+		if err != nil {
+			return opts, err
+		}
+		opts.Tokens = append(opts.Tokens, tokens...)
+	}
+	opts.AllowedOrigins = splitList(origins)
+	return opts, nil
+}
+
+// splitList splits a comma-separated flag value, dropping empty items.
+func splitList(v string) []string {
+	var out []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// serveMetrics serves m at /metrics on addr until the returned stop is called.
+func serveMetrics(addr string, m *metrics.Metrics, logger *slog.Logger) (stop func(), err error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("metrics listener: %w", err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", m.Handler())
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("metrics server stopped", "error", err)
+		}
+	}()
+	logger.Info("serving metrics", "addr", ln.Addr().String())
+	return func() { _ = srv.Close() }, nil
+}
+
+// defaultAuditPath returns ~/.zaia-mcp/audit.jsonl, or "" if the home
+// directory is unknown.
+func defaultAuditPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".zaia-mcp", "audit.jsonl")
 }
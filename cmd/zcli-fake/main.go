@@ -0,0 +1,14 @@
+// Command zcli-fake is a stand-in for the zcli CLI used by hermetic
+// end-to-end tests. Install or build it as "zcli"; it shares the state
+// file of zaia-fake (ZAIA_FAKE_STATE).
+package main
+
+import (
+	"os"
+
+	"github.com/zeropsio/zaia-mcp/internal/fakezerops"
+)
+
+func main() {
+	os.Exit(fakezerops.RunZcli(fakezerops.StatePath(), os.Args[1:], os.Stdout, os.Stderr))
+}
@@ -145,6 +145,7 @@ func TestE2E_FullLifecycle(t *testing.T) {
 		text := s.mustCallSuccess("zerops_manage", map[string]interface{}{
 			"action":          "stop",
 			"serviceHostname": dbHost,
+			"confirm":         true,
 		})
 		processes := parseProcesses(t, text)
 		for _, p := range processes {
@@ -26,7 +26,7 @@ type session struct {
 func connectInMemory(t *testing.T) *session {
 	t.Helper()
 
-	exec := executor.NewCLIExecutor("", "")
+	exec := executor.NewCLIExecutor(zaiaBinary, zcliBinary)
 	srv := server.NewWithExecutor(exec)
 
 	ctx := t.Context()
@@ -95,7 +95,7 @@ func deleteTestService(t *testing.T, hostname string) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "zaia", "delete", "--service", hostname, "--confirm")
+	cmd := exec.CommandContext(ctx, zaiaBinary, "delete", "--service", hostname, "--confirm")
 	out, err := cmd.Output()
 	if err != nil {
 		t.Logf("cleanup: delete %s failed: %v", hostname, err)
@@ -0,0 +1,68 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/zeropsio/zaia-mcp/internal/fakezerops"
+)
+
+// fakeEnv switches the suite to the in-repo fake CLIs (cmd/zaia-fake,
+// cmd/zcli-fake) and a throwaway state file, so it runs without a Zerops
+// account or network.
+const fakeEnv = "ZAIA_E2E_FAKE"
+
+// zaiaBinary and zcliBinary are the CLIs under test: names resolved on the
+// PATH for a real account, absolute paths to the built fakes otherwise.
+var (
+	zaiaBinary = "zaia"
+	zcliBinary = "zcli"
+)
+
+func TestMain(m *testing.M) {
+	if os.Getenv(fakeEnv) != "1" {
+		os.Exit(m.Run())
+	}
+	dir, err := os.MkdirTemp("", "zaia-e2e-fake")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "e2e: %v\n", err)
+		os.Exit(1)
+	}
+	code := runWithFakes(m, dir)
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func runWithFakes(m *testing.M, dir string) int {
+	for _, b := range []struct {
+		target *string
+		name   string
+		pkg    string
+	}{
+		{&zaiaBinary, "zaia", "../cmd/zaia-fake"},
+		{&zcliBinary, "zcli", "../cmd/zcli-fake"},
+	} {
+		out := filepath.Join(dir, b.name)
+		if runtime.GOOS == "windows" {
+			out += ".exe"
+		}
+		build := exec.Command("go", "build", "-o", out, b.pkg)
+		build.Stdout, build.Stderr = os.Stderr, os.Stderr
+		if err := build.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "e2e: build %s: %v\n", b.pkg, err)
+			return 1
+		}
+		*b.target = out
+	}
+	if err := os.Setenv(fakezerops.StateEnv, filepath.Join(dir, "state.json")); err != nil {
+		fmt.Fprintf(os.Stderr, "e2e: %v\n", err)
+		return 1
+	}
+	return m.Run()
+}
@@ -2,10 +2,12 @@ module github.com/zeropsio/zaia-mcp
 
 go 1.24.0
 
-require github.com/modelcontextprotocol/go-sdk v1.2.0
+require (
+	github.com/google/jsonschema-go v0.3.0
+	github.com/modelcontextprotocol/go-sdk v1.2.0
+)
 
 require (
-	github.com/google/jsonschema-go v0.3.0 // indirect
 	github.com/yosida95/uritemplate/v3 v3.0.2 // indirect
 	golang.org/x/oauth2 v0.30.0 // indirect
 )
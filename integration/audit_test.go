@@ -0,0 +1,95 @@
+package integration
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zeropsio/zaia-mcp/internal/executor"
+)
+
+func TestFlow_MutationsAreAudited(t *testing.T) {
+	h := NewHarness(t)
+
+	h.Mock().WithZaiaResponse("discover", executor.SyncResult(`{"services":[]}`))
+	h.Mock().WithZaiaResponse("env set --service api SECRET=hunter2",
+		executor.AsyncResult(`[{"processId":"env-1","status":"PENDING"}]`))
+	h.Mock().WithZaiaResponse("stop --service db",
+		executor.ErrorResult("SERVICE_NOT_FOUND", "Service db not found", "", 1))
+
+	h.MustCallSuccess("zerops_discover", nil)
+	h.MustCallSuccess("zerops_env", map[string]interface{}{
+		"action":          "set",
+		"serviceHostname": "api",
+		"variables":       []interface{}{"SECRET=hunter2"},
+	})
+	h.MustCallError("zerops_manage", map[string]interface{}{
+		"action":          "stop",
+		"serviceHostname": "db",
+		"confirm":         true,
+	})
+
+	text := h.MustCallSuccess("zerops_audit", nil)
+	if strings.Contains(text, "hunter2") {
+		t.Fatalf("audit leaks secret value: %s", text)
+	}
+	var out struct {
+		Entries []struct {
+			Client     string         `json:"client"`
+			Tool       string         `json:"tool"`
+			Args       map[string]any `json:"args"`
+			ExitCode   *int           `json:"exitCode"`
+			ProcessIDs []string       `json:"processIds"`
+			IsError    bool           `json:"isError"`
+		} `json:"entries"`
+	}
+	if err := json.Unmarshal([]byte(text), &out); err != nil {
+		t.Fatalf("parse audit: %v", err)
+	}
+	if len(out.Entries) != 2 {
+		t.Fatalf("got %d audit entries, want 2 (discover is read-only): %s", len(out.Entries), text)
+	}
+
+	stop, set := out.Entries[0], out.Entries[1]
+	if stop.Tool != "zerops_manage" || !stop.IsError || stop.ExitCode == nil || *stop.ExitCode != 1 {
+		t.Errorf("manage entry: got %+v", stop)
+	}
+	if set.Tool != "zerops_env" || set.Client != "test-client" {
+		t.Errorf("env entry: got %+v", set)
+	}
+	if len(set.ProcessIDs) != 1 || set.ProcessIDs[0] != "env-1" {
+		t.Errorf("env entry process IDs: got %v", set.ProcessIDs)
+	}
+
+	filtered := h.MustCallSuccess("zerops_audit", map[string]interface{}{"serviceHostname": "api"})
+	if !strings.Contains(filtered, `"count":1`) {
+		t.Errorf("hostname filter: got %s", filtered)
+	}
+}
+
+func TestFlow_TimeoutDoesNotLeakSecrets(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "zaia")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nsleep 10\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cli := executor.NewCLIExecutor(script, script)
+	cli.Timeouts = map[string]time.Duration{"env": 50 * time.Millisecond}
+	h := newHarness(t, cli)
+
+	text := h.MustCallError("zerops_env", map[string]interface{}{
+		"action":          "set",
+		"serviceHostname": "api",
+		"variables":       []interface{}{"SECRET=hunter2"},
+	})
+	if !strings.Contains(text, "TIMEOUT") || strings.Contains(text, "hunter2") {
+		t.Errorf("timeout result: got %s", text)
+	}
+
+	audit := h.MustCallSuccess("zerops_audit", nil)
+	if !strings.Contains(audit, "timed out") || strings.Contains(audit, "hunter2") {
+		t.Errorf("audit entry: got %s", audit)
+	}
+}
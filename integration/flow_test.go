@@ -14,9 +14,11 @@ func TestAllToolsRegistered(t *testing.T) {
 	sort.Strings(tools)
 
 	expected := []string{
+		"zerops_audit",
 		"zerops_delete",
 		"zerops_deploy",
 		"zerops_discover",
+		"zerops_doctor",
 		"zerops_env",
 		"zerops_events",
 		"zerops_import",
@@ -24,8 +26,10 @@ func TestAllToolsRegistered(t *testing.T) {
 		"zerops_logs",
 		"zerops_manage",
 		"zerops_process",
+		"zerops_profiles",
 		"zerops_subdomain",
 		"zerops_validate",
+		"zerops_wait",
 	}
 
 	if len(tools) != len(expected) {
@@ -293,3 +297,26 @@ func TestFlow_KnowledgeSearch(t *testing.T) {
 		t.Errorf("unexpected query: %v", data["query"])
 	}
 }
+
+func TestFlow_Doctor(t *testing.T) {
+	h := NewHarness(t)
+
+	h.Mock().WithZaiaResponse("version", executor.SyncResult(`{"version":"1.4.0"}`))
+	h.Mock().WithZaiaResponse("discover",
+		executor.ErrorResult("AUTH_REQUIRED", "Not authenticated", "Run: zaia login <token>", 2))
+	h.Mock().WithZcliResponse("version", &executor.Result{Stdout: []byte("v1.0.38\n")})
+	h.Mock().WithZcliResponse("project list", &executor.Result{Stdout: []byte("myapp  ACTIVE\n")})
+
+	text := h.MustCallSuccess("zerops_doctor", nil)
+	var report map[string]interface{}
+	if err := json.Unmarshal([]byte(text), &report); err != nil {
+		t.Fatalf("parse doctor report: %v", err)
+	}
+	if report["ok"] != false {
+		t.Errorf("expected ok=false for unauthenticated zaia, got %v", report["ok"])
+	}
+	remediation, _ := report["remediation"].([]interface{})
+	if len(remediation) == 0 {
+		t.Error("expected remediation steps")
+	}
+}
@@ -2,6 +2,7 @@ package integration
 
 import (
 	"encoding/json"
+	"os"
 	"testing"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -9,6 +10,10 @@ import (
 	"github.com/zeropsio/zaia-mcp/internal/server"
 )
 
+// RecordEnv enables cassette recording: when set to "1", NewCassetteHarness
+// runs flows against the real zaia/zcli binaries and rewrites the cassette.
+const RecordEnv = "ZAIA_MCP_RECORD"
+
 // Harness provides a test harness for end-to-end MCP flows.
 type Harness struct {
 	t       *testing.T
@@ -17,11 +22,55 @@ type Harness struct {
 	session *mcp.ClientSession
 }
 
-// NewHarness creates a new test harness with a mock executor.
-func NewHarness(t *testing.T) *Harness {
+// NewHarness creates a new test harness with a mock executor, wrapped in
+// the given executor middlewares (first outermost).
+func NewHarness(t *testing.T, mws ...executor.Middleware) *Harness {
 	t.Helper()
 	mock := executor.NewMockExecutor()
-	srv := server.NewWithExecutor(mock)
+	h := newHarness(t, mock, mws...)
+	h.mock = mock
+	return h
+}
+
+// NewCassetteHarness creates a test harness that replays the JSONL cassette
+// at path in recorded order. With ZAIA_MCP_RECORD=1 it instead runs against
+// the real CLIs and records a fresh cassette to path.
+// Mock() returns nil for cassette harnesses.
+func NewCassetteHarness(t *testing.T, path string) *Harness {
+	t.Helper()
+	if os.Getenv(RecordEnv) == "1" {
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatalf("create cassette: %v", err)
+		}
+		rec := executor.NewRecordingExecutor(executor.NewCLIExecutor("", ""), f)
+		t.Cleanup(func() {
+			if err := rec.Err(); err != nil {
+				t.Errorf("recording cassette: %v", err)
+			}
+			if err := f.Close(); err != nil {
+				t.Errorf("close cassette: %v", err)
+			}
+		})
+		return newHarness(t, rec)
+	}
+
+	entries, err := executor.LoadCassette(path)
+	if err != nil {
+		t.Fatalf("load cassette: %v", err)
+	}
+	replay := executor.NewReplayExecutor(entries, executor.ReplayInOrder)
+	t.Cleanup(func() {
+		if n := replay.Remaining(); n > 0 && !t.Failed() {
+			t.Errorf("cassette %s: %d recorded calls were not replayed", path, n)
+		}
+	})
+	return newHarness(t, replay)
+}
+
+func newHarness(t *testing.T, exec executor.Executor, mws ...executor.Middleware) *Harness {
+	t.Helper()
+	srv := server.NewWithExecutorAndLogger(exec, nil, mws...)
 
 	ctx := t.Context()
 	t1, t2 := mcp.NewInMemoryTransports()
@@ -37,7 +86,6 @@ func NewHarness(t *testing.T) *Harness {
 
 	return &Harness{
 		t:       t,
-		mock:    mock,
 		srv:     srv,
 		session: session,
 	}
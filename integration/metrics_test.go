@@ -0,0 +1,36 @@
+package integration
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/zeropsio/zaia-mcp/internal/executor"
+	"github.com/zeropsio/zaia-mcp/internal/resources"
+)
+
+func TestFlow_MetricsResource(t *testing.T) {
+	h := NewHarness(t)
+	h.Mock().
+		WithZaiaResponse("discover", executor.SyncResult(`{"services":[]}`)).
+		WithZaiaResponse("stop --service db", executor.ErrorResult("SERVICE_NOT_FOUND", "Service db not found", "", 1))
+
+	h.MustCallSuccess("zerops_discover", nil)
+	h.MustCallError("zerops_manage", map[string]interface{}{"action": "stop", "serviceHostname": "db", "confirm": true})
+
+	result, err := h.session.ReadResource(t.Context(), &mcp.ReadResourceParams{URI: resources.MetricsURI})
+	if err != nil {
+		t.Fatalf("ReadResource: %v", err)
+	}
+	text := result.Contents[0].Text
+	for _, want := range []string{
+		`zaia_mcp_tool_calls_total{tool="zerops_discover",outcome="success"} 1`,
+		`zaia_mcp_tool_calls_total{tool="zerops_manage",outcome="error"} 1`,
+		`zaia_mcp_cli_executions_total{binary="zaia",command="stop",outcome="cli_error",code="SERVICE_NOT_FOUND"} 1`,
+		`zaia_mcp_tool_call_duration_seconds_count{tool="zerops_discover"} 1`,
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %q in:\n%s", want, text)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package integration
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/zeropsio/zaia-mcp/internal/executor"
+)
+
+func TestFlow_MiddlewaresSeeCallInfo(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		order []string
+		seen  []executor.CallInfo
+	)
+	record := func(name string) executor.Middleware {
+		return executor.Intercept(func(ctx context.Context, _ string, args []string, next executor.RunFunc) (*executor.Result, error) {
+			mu.Lock()
+			order = append(order, name)
+			if info, ok := executor.CallInfoFrom(ctx); ok && name == "outer" {
+				seen = append(seen, info)
+			}
+			mu.Unlock()
+			return next(ctx, args...)
+		})
+	}
+
+	h := NewHarness(t, record("outer"), record("inner"))
+	h.Mock().
+		WithZaiaResponse("discover", executor.SyncResult(`{"services":[]}`)).
+		WithZaiaResponse("restart --service api", executor.AsyncResult(`[{"processId":"p1","status":"PENDING"}]`))
+
+	h.MustCallSuccess("zerops_discover", nil)
+	h.MustCallSuccess("zerops_manage", map[string]interface{}{
+		"action":          "restart",
+		"serviceHostname": "api",
+	})
+
+	if want := []string{"outer", "inner", "outer", "inner"}; !slices.Equal(order, want) {
+		t.Errorf("middleware order: got %v, want %v", order, want)
+	}
+	want := []executor.CallInfo{
+		{Tool: "zerops_discover"},
+		{Tool: "zerops_manage", Hostname: "api", Mutating: true},
+	}
+	if !slices.Equal(seen, want) {
+		t.Errorf("call info: got %+v, want %+v", seen, want)
+	}
+}
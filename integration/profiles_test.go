@@ -0,0 +1,51 @@
+package integration
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/zeropsio/zaia-mcp/internal/executor"
+)
+
+func TestFlow_Profiles(t *testing.T) {
+	base := executor.NewMockExecutor().WithDefault(executor.SyncResult(`{"project":{"name":"base"}}`))
+	staging := executor.NewMockExecutor().WithDefault(executor.SyncResult(`{"project":{"name":"staging"}}`))
+	prod := executor.NewMockExecutor().
+		WithDefault(executor.SyncResult(`{"project":{"name":"prod"}}`)).
+		WithZcliResponse("push --projectId prod-id", executor.SyncResult(`{"status":"DEPLOYED"}`))
+
+	profiles, err := executor.NewProfileExecutor(base, "staging",
+		executor.Profile{Name: "staging", Description: "Staging", ProjectID: "stage-id", Exec: staging},
+		executor.Profile{Name: "prod", ProjectID: "prod-id", Exec: prod},
+	)
+	if err != nil {
+		t.Fatalf("NewProfileExecutor: %v", err)
+	}
+	h := newHarness(t, profiles)
+
+	var list struct {
+		Profiles []executor.ProfileInfo `json:"profiles"`
+	}
+	if err := json.Unmarshal([]byte(h.MustCallSuccess("zerops_profiles", nil)), &list); err != nil {
+		t.Fatalf("parse profiles: %v", err)
+	}
+	if len(list.Profiles) != 2 || list.Profiles[0].Name != "prod" || !list.Profiles[1].Default {
+		t.Errorf("profiles: got %+v", list.Profiles)
+	}
+
+	if text := h.MustCallSuccess("zerops_discover", nil); !strings.Contains(text, "staging") {
+		t.Errorf("default profile: got %s", text)
+	}
+	if text := h.MustCallSuccess("zerops_discover", map[string]interface{}{"profile": "prod"}); !strings.Contains(text, "prod") {
+		t.Errorf("prod profile: got %s", text)
+	}
+	h.MustCallSuccess("zerops_deploy", map[string]interface{}{"profile": "prod"})
+
+	if text := h.MustCallError("zerops_discover", map[string]interface{}{"profile": "dev"}); !strings.Contains(text, "unknown profile") {
+		t.Errorf("unknown profile: got %s", text)
+	}
+	if len(base.Calls) != 0 {
+		t.Errorf("base executor used %d times, want 0 with a default profile", len(base.Calls))
+	}
+}
@@ -0,0 +1,40 @@
+package integration
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestReplay_DiscoverRestartPoll(t *testing.T) {
+	h := NewCassetteHarness(t, "testdata/discover_restart.jsonl")
+
+	text := h.MustCallSuccess("zerops_discover", nil)
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(text), &data); err != nil {
+		t.Fatalf("parse discover: %v", err)
+	}
+	if data["project"] == nil {
+		t.Fatal("discover response missing 'project' field")
+	}
+
+	text = h.MustCallSuccess("zerops_manage", map[string]interface{}{
+		"action":          "restart",
+		"serviceHostname": "api",
+	})
+	var processes []map[string]interface{}
+	if err := json.Unmarshal([]byte(text), &processes); err != nil {
+		t.Fatalf("parse processes: %v", err)
+	}
+	processID, _ := processes[0]["processId"].(string)
+
+	for _, want := range []string{"RUNNING", "FINISHED"} {
+		text = h.MustCallSuccess("zerops_process", map[string]interface{}{
+			"processId": processID,
+		})
+		var proc map[string]interface{}
+		_ = json.Unmarshal([]byte(text), &proc)
+		if proc["status"] != want {
+			t.Errorf("got status %v, want %s", proc["status"], want)
+		}
+	}
+}
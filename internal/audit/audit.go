@@ -0,0 +1,172 @@
+// Package audit records mutating tool calls to an append-only JSONL file.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DefaultKeep is the default number of recent entries kept in memory.
+const DefaultKeep = 500
+
+// maxLine bounds a single audit line when reading the file back.
+const maxLine = 1024 * 1024
+
+// Entry is one audited tool call.
+type Entry struct {
+	Time       time.Time      `json:"time"`
+	Client     string         `json:"client,omitempty"` // MCP client name from initialize
+	Tool       string         `json:"tool"`
+	Args       map[string]any `json:"args,omitempty"`     // normalized, secrets redacted
+	ExitCode   *int           `json:"exitCode,omitempty"` // last CLI exit code; nil if no CLI ran
+	ProcessIDs []string       `json:"processIds,omitempty"`
+	DurationMs int64          `json:"durationMs"`
+	IsError    bool           `json:"isError"`
+	Error      string         `json:"error,omitempty"`
+}
+
+// Log appends entries to a JSONL file and keeps the most recent ones in
+// memory for querying. A Log without a file only keeps memory entries.
+type Log struct {
+	mu     sync.Mutex
+	file   *os.File
+	path   string
+	keep   int
+	recent []Entry
+}
+
+// NewMemoryLog creates a Log that keeps the last keep entries in memory only.
+func NewMemoryLog(keep int) *Log {
+	if keep <= 0 {
+		keep = DefaultKeep
+	}
+	return &Log{keep: keep}
+}
+
+// Open opens (or creates) the audit file at path and loads its last keep
+// entries so recent history survives restarts.
+func Open(path string, keep int) (*Log, error) {
+	l := NewMemoryLog(keep)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("audit: create directory: %w", err)
+	}
+	if err := l.load(path); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("audit: open: %w", err)
+	}
+	l.file = f
+	l.path = path
+	return l, nil
+}
+
+// load reads existing entries from path; unparsable lines are skipped.
+func (l *Log) load(path string) error {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("audit: read: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLine)
+	for scanner.Scan() {
+		var e Entry
+		if json.Unmarshal(scanner.Bytes(), &e) == nil {
+			l.remember(e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("audit: read: %w", err)
+	}
+	return nil
+}
+
+// Path returns the audit file path, or "" for memory-only logs.
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Append records an entry.
+func (l *Log) Append(e Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.remember(e)
+	if l.file == nil {
+		return nil
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("audit: encode: %w", err)
+	}
+	if _, err := l.file.Write(append(b, '\n')); err != nil {
+		return fmt.Errorf("audit: write: %w", err)
+	}
+	return nil
+}
+
+// remember adds e to the in-memory ring. Caller must hold l.mu (or own l).
+func (l *Log) remember(e Entry) {
+	l.recent = append(l.recent, e)
+	if len(l.recent) > l.keep {
+		l.recent = append(l.recent[:0], l.recent[len(l.recent)-l.keep:]...)
+	}
+}
+
+// Filter selects entries returned by Recent.
+type Filter struct {
+	Tool            string // exact tool name
+	ServiceHostname string // matches args.serviceHostname
+	Profile         string // matches args.profile
+	Limit           int    // max entries (default 20)
+}
+
+// Recent returns the newest matching entries, newest first.
+func (l *Log) Recent(f Filter) []Entry {
+	limit := f.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	out := make([]Entry, 0, min(limit, len(l.recent)))
+	for i := len(l.recent) - 1; i >= 0 && len(out) < limit; i-- {
+		e := l.recent[i]
+		if f.Tool != "" && e.Tool != f.Tool {
+			continue
+		}
+		if f.ServiceHostname != "" && e.Args["serviceHostname"] != f.ServiceHostname {
+			continue
+		}
+		if f.Profile != "" && e.Args["profile"] != f.Profile {
+			continue
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+// Close closes the audit file.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
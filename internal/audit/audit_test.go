@@ -0,0 +1,91 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLog_AppendAndRecent(t *testing.T) {
+	l := NewMemoryLog(10)
+	for _, e := range []Entry{
+		{Tool: "zerops_manage", Args: map[string]any{"serviceHostname": "api"}},
+		{Tool: "zerops_env", Args: map[string]any{"serviceHostname": "db"}},
+		{Tool: "zerops_manage", Args: map[string]any{"serviceHostname": "db"}},
+	} {
+		if err := l.Append(e); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+
+	all := l.Recent(Filter{})
+	if len(all) != 3 {
+		t.Fatalf("got %d entries, want 3", len(all))
+	}
+	if all[0].Args["serviceHostname"] != "db" || all[0].Tool != "zerops_manage" {
+		t.Errorf("newest entry first: got %+v", all[0])
+	}
+
+	if got := l.Recent(Filter{Tool: "zerops_manage"}); len(got) != 2 {
+		t.Errorf("tool filter: got %d entries, want 2", len(got))
+	}
+	if got := l.Recent(Filter{ServiceHostname: "db"}); len(got) != 2 {
+		t.Errorf("hostname filter: got %d entries, want 2", len(got))
+	}
+	if got := l.Recent(Filter{Limit: 1}); len(got) != 1 {
+		t.Errorf("limit: got %d entries, want 1", len(got))
+	}
+}
+
+func TestLog_KeepsLastN(t *testing.T) {
+	l := NewMemoryLog(2)
+	for _, tool := range []string{"a", "b", "c"} {
+		_ = l.Append(Entry{Tool: tool})
+	}
+	got := l.Recent(Filter{})
+	if len(got) != 2 || got[0].Tool != "c" || got[1].Tool != "b" {
+		t.Errorf("got %+v, want [c b]", got)
+	}
+}
+
+func TestOpen_PersistsAndReloads(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "audit.jsonl")
+	exitCode := 0
+
+	l, err := Open(path, 10)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	entry := Entry{
+		Time:       time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Tool:       "zerops_delete",
+		ExitCode:   &exitCode,
+		ProcessIDs: []string{"p1"},
+	}
+	if err := l.Append(entry); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read audit file: %v", err)
+	}
+	if !strings.Contains(string(data), `"tool":"zerops_delete"`) {
+		t.Errorf("audit file missing entry: %s", data)
+	}
+
+	reopened, err := Open(path, 10)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer reopened.Close()
+	got := reopened.Recent(Filter{})
+	if len(got) != 1 || got[0].ProcessIDs[0] != "p1" || *got[0].ExitCode != 0 {
+		t.Errorf("reloaded entries: got %+v", got)
+	}
+}
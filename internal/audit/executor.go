@@ -0,0 +1,73 @@
+package audit
+
+import (
+	"context"
+	"sync"
+
+	"github.com/zeropsio/zaia-mcp/internal/executor"
+)
+
+// Call collects executor outcomes for one audited tool call.
+type Call struct {
+	mu       sync.Mutex
+	exitCode *int
+}
+
+// ExitCode returns the exit code of the last CLI run during the call,
+// or nil if no CLI produced a result.
+func (c *Call) ExitCode() *int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.exitCode
+}
+
+func (c *Call) setExitCode(code int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.exitCode = &code
+}
+
+type callKey struct{}
+
+// WithCall returns a context that collects executor outcomes into a new Call.
+func WithCall(ctx context.Context) (context.Context, *Call) {
+	c := &Call{}
+	return context.WithValue(ctx, callKey{}, c), c
+}
+
+func callFrom(ctx context.Context) *Call {
+	c, _ := ctx.Value(callKey{}).(*Call)
+	return c
+}
+
+// WrapExecutor returns an executor that reports CLI exit codes to the Call
+// attached by WithCall. Calls without one pass through unchanged.
+func WrapExecutor(next executor.Executor) executor.Executor {
+	return &callExecutor{next: next}
+}
+
+type callExecutor struct {
+	next executor.Executor
+}
+
+func (e *callExecutor) Unwrap() executor.Executor {
+	return e.next
+}
+
+func (e *callExecutor) RunZaia(ctx context.Context, args ...string) (*executor.Result, error) {
+	result, err := e.next.RunZaia(ctx, args...)
+	observe(ctx, result)
+	return result, err
+}
+
+func (e *callExecutor) RunZcli(ctx context.Context, args ...string) (*executor.Result, error) {
+	result, err := e.next.RunZcli(ctx, args...)
+	observe(ctx, result)
+	return result, err
+}
+
+func observe(ctx context.Context, result *executor.Result) {
+	if c := callFrom(ctx); c != nil && result != nil {
+		c.setExitCode(result.ExitCode)
+	}
+}
@@ -0,0 +1,95 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/zeropsio/zaia-mcp/internal/executor"
+)
+
+// Redacted replaces secret values in audit entries.
+const Redacted = executor.Redacted
+
+// IsSecretKey reports whether values under name must always be redacted.
+func IsSecretKey(name string) bool {
+	return executor.IsSecretName(name)
+}
+
+// NormalizeArgs parses raw tool arguments, drops zero values and redacts
+// secrets:
+//   - "variables": KEY=value entries keep only the key
+//   - "content": replaced by its size and SHA-256 prefix (YAML may hold envSecrets)
+//   - any key that looks like a credential: replaced entirely
+func NormalizeArgs(raw json.RawMessage) map[string]any {
+	if len(raw) == 0 {
+		return nil
+	}
+	var args map[string]any
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return map[string]any{"_invalid": fmt.Sprintf("%d bytes", len(raw))}
+	}
+	out := make(map[string]any, len(args))
+	for k, v := range args {
+		if isZero(v) {
+			continue
+		}
+		switch {
+		case IsSecretKey(k):
+			out[k] = Redacted
+		case k == "variables":
+			out[k] = redactVariables(v)
+		case k == "content":
+			out[k] = summarize(v)
+		default:
+			out[k] = v
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+func isZero(v any) bool {
+	switch x := v.(type) {
+	case nil:
+		return true
+	case string:
+		return x == ""
+	case bool:
+		return !x
+	case float64:
+		return x == 0
+	case []any:
+		return len(x) == 0
+	case map[string]any:
+		return len(x) == 0
+	}
+	return false
+}
+
+// redactVariables keeps variable names and drops values from KEY=value entries.
+func redactVariables(v any) any {
+	list, ok := v.([]any)
+	if !ok {
+		return Redacted
+	}
+	out := make([]any, 0, len(list))
+	for _, item := range list {
+		s, ok := item.(string)
+		if !ok {
+			out = append(out, Redacted)
+			continue
+		}
+		out = append(out, executor.RedactVariable(s))
+	}
+	return out
+}
+
+func summarize(v any) any {
+	s, ok := v.(string)
+	if !ok {
+		return Redacted
+	}
+	return executor.SummarizeContent(s)
+}
@@ -0,0 +1,55 @@
+package audit
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeArgs(t *testing.T) {
+	raw := json.RawMessage(`{
+		"action": "set",
+		"serviceHostname": "api",
+		"project": false,
+		"variables": ["DB_PASSWORD=hunter2", "PORT=3000", "OLD_KEY"],
+		"content": "services:\n  - hostname: db\n    envSecrets:\n      S: x",
+		"apiToken": "abc",
+		"limit": 0
+	}`)
+	args := NormalizeArgs(raw)
+
+	if args["serviceHostname"] != "api" || args["action"] != "set" {
+		t.Errorf("plain args changed: %v", args)
+	}
+	for _, k := range []string{"project", "limit"} {
+		if _, ok := args[k]; ok {
+			t.Errorf("zero value %q not dropped", k)
+		}
+	}
+	vars, _ := args["variables"].([]any)
+	want := []any{"DB_PASSWORD=" + Redacted, "PORT=" + Redacted, "OLD_KEY"}
+	if len(vars) != len(want) {
+		t.Fatalf("got variables %v, want %v", vars, want)
+	}
+	for i := range want {
+		if vars[i] != want[i] {
+			t.Errorf("variables[%d]: got %v, want %v", i, vars[i], want[i])
+		}
+	}
+	if args["apiToken"] != Redacted {
+		t.Errorf("apiToken: got %v, want redacted", args["apiToken"])
+	}
+	content, _ := args["content"].(string)
+	if strings.Contains(content, "envSecrets") || !strings.Contains(content, "sha256:") {
+		t.Errorf("content not summarized: %q", content)
+	}
+}
+
+func TestNormalizeArgs_Empty(t *testing.T) {
+	if args := NormalizeArgs(nil); args != nil {
+		t.Errorf("got %v, want nil", args)
+	}
+	if args := NormalizeArgs(json.RawMessage(`{"confirm":false}`)); args != nil {
+		t.Errorf("got %v, want nil", args)
+	}
+}
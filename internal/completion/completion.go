@@ -0,0 +1,207 @@
+// Package completion implements MCP completion/complete for prompt
+// arguments and the zerops://docs/{+path} resource template.
+//
+// Service hostnames come from `zaia discover` and doc paths from
+// `zaia search`; both are cached by the executor chain (see
+// executor.CachingExecutor). Action and type fields use static enums.
+package completion
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/zeropsio/zaia-mcp/internal/executor"
+	"github.com/zeropsio/zaia-mcp/internal/resources"
+)
+
+const (
+	docsPrefix = "zerops://docs/"
+	// maxValues is the most values a completion may return (MCP limit).
+	maxValues = 100
+	// lookupTimeout bounds the CLI call behind a completion.
+	lookupTimeout = 5 * time.Second
+)
+
+// actions lists the action values per tool. Clients that complete tool
+// input send the tool name as a ref/prompt name.
+var actions = map[string][]string{
+	"zerops_manage":    {"start", "stop", "restart", "scale"},
+	"zerops_env":       {"get", "set", "delete"},
+	"zerops_subdomain": {"enable", "disable"},
+	"zerops_process":   {"status", "cancel"},
+}
+
+// enums lists static values per argument name.
+var enums = map[string][]string{
+	"severity": {"error", "warning", "info", "debug"},
+	"since":    {"30m", "1h", "24h", "7d"},
+	"cpuMode":  {"SHARED", "DEDICATED"},
+	"mode":     {"all", "any"},
+	"type":     {"zerops.yml", "import.yml"},
+	"databaseType": {
+		"postgresql@16", "postgresql@17", "mariadb@10.6", "valkey@7.2", "keydb@6",
+		"elasticsearch@8.16", "meilisearch@1.10", "typesense@27.1", "nats@2.10", "kafka@3.8",
+	},
+	"runtime": {
+		"nodejs@22", "nodejs@20", "bun@1.2", "deno@2", "go@1", "python@3.12",
+		"php-nginx@8.4", "php-apache@8.4", "java@21", "dotnet@9", "rust@1", "static",
+	},
+}
+
+// hostnameArgs are argument names that take an existing service hostname.
+var hostnameArgs = []string{"serviceHostname", "service"}
+
+// Completer answers completion requests.
+type Completer struct {
+	exec executor.Executor
+}
+
+// New creates a Completer that looks up hostnames and doc paths through exec.
+func New(exec executor.Executor) *Completer {
+	return &Completer{exec: exec}
+}
+
+// Complete implements mcp.ServerOptions.CompletionHandler. Unknown
+// arguments complete to no values; CLI failures are not reported as errors.
+// A profile argument in the request context selects the profile to query.
+func (c *Completer) Complete(ctx context.Context, req *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+	p := req.Params
+	var contextArgs map[string]string
+	if p.Context != nil {
+		contextArgs = p.Context.Arguments
+	}
+	ctx, cancel := context.WithTimeout(executor.WithProfile(ctx, contextArgs["profile"]), lookupTimeout)
+	defer cancel()
+
+	var values []string
+	switch {
+	case p.Ref == nil:
+	case p.Ref.Type == "ref/resource" && p.Ref.URI == resources.DocsTemplate && p.Argument.Name == "path":
+		values = c.docPaths(ctx, p.Argument.Value)
+	case p.Ref.Type == "ref/prompt":
+		values = filter(c.argumentValues(ctx, p.Ref.Name, p.Argument.Name), p.Argument.Value)
+	}
+	return result(values), nil
+}
+
+// argumentValues returns the candidates for argument name of the prompt
+// (or tool) ref.
+func (c *Completer) argumentValues(ctx context.Context, ref, name string) []string {
+	switch {
+	case slices.Contains(hostnameArgs, name):
+		return c.hostnames(ctx)
+	case name == "action":
+		return actions[ref]
+	case name == "profile":
+		return c.profiles()
+	}
+	return enums[name]
+}
+
+// hostnames returns the service hostnames of the project.
+func (c *Completer) hostnames(ctx context.Context) []string {
+	result, err := c.exec.RunZaia(ctx, "discover")
+	if err != nil || result.ExitCode != 0 {
+		return nil
+	}
+	var resp struct {
+		Data struct {
+			Services []struct {
+				Hostname string `json:"hostname"`
+			} `json:"services"`
+		} `json:"data"`
+	}
+	if json.Unmarshal(result.Stdout, &resp) != nil {
+		return nil
+	}
+	hostnames := make([]string, 0, len(resp.Data.Services))
+	for _, svc := range resp.Data.Services {
+		hostnames = append(hostnames, svc.Hostname)
+	}
+	slices.Sort(hostnames)
+	return hostnames
+}
+
+func (c *Completer) profiles() []string {
+	profiles, ok := executor.Find[*executor.ProfileExecutor](c.exec)
+	if !ok {
+		return nil
+	}
+	var names []string
+	for _, p := range profiles.Profiles() {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// docPaths searches the docs for the typed path. Paths starting with it
+// come first, followed by the remaining search results in ranked order.
+func (c *Completer) docPaths(ctx context.Context, value string) []string {
+	query := strings.Join(strings.FieldsFunc(value, func(r rune) bool {
+		return r == '/' || r == '-' || r == '_' || r == '.' || r == ' '
+	}), " ")
+	if query == "" {
+		return nil
+	}
+	result, err := c.exec.RunZaia(ctx, "search", query, "--limit", "20")
+	if err != nil || result.ExitCode != 0 {
+		return nil
+	}
+	var resp struct {
+		Data struct {
+			Results []struct {
+				URI string `json:"uri"`
+			} `json:"results"`
+		} `json:"data"`
+	}
+	if json.Unmarshal(result.Stdout, &resp) != nil {
+		return nil
+	}
+	var prefixed, others []string
+	for _, r := range resp.Data.Results {
+		path, ok := strings.CutPrefix(r.URI, docsPrefix)
+		if !ok || slices.Contains(prefixed, path) || slices.Contains(others, path) {
+			continue
+		}
+		if hasPrefixFold(path, value) {
+			prefixed = append(prefixed, path)
+		} else {
+			others = append(others, path)
+		}
+	}
+	return append(prefixed, others...)
+}
+
+// filter keeps the values starting with prefix (case-insensitive).
+func filter(values []string, prefix string) []string {
+	var out []string
+	for _, v := range values {
+		if hasPrefixFold(v, prefix) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
+
+func result(values []string) *mcp.CompleteResult {
+	total := len(values)
+	if values == nil {
+		values = []string{}
+	}
+	if total > maxValues {
+		values = values[:maxValues]
+	}
+	return &mcp.CompleteResult{Completion: mcp.CompletionResultDetails{
+		Values:  values,
+		Total:   total,
+		HasMore: total > maxValues,
+	}}
+}
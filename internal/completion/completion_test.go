@@ -0,0 +1,143 @@
+package completion_test
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/zeropsio/zaia-mcp/internal/completion"
+	"github.com/zeropsio/zaia-mcp/internal/executor"
+)
+
+const discoverJSON = `{"project":{"name":"demo"},"services":[{"hostname":"db"},{"hostname":"api"},{"hostname":"app"}]}`
+
+func connect(t *testing.T, exec executor.Executor) *mcp.ClientSession {
+	t.Helper()
+	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.0.1"},
+		&mcp.ServerOptions{CompletionHandler: completion.New(exec).Complete})
+
+	ctx := t.Context()
+	t1, t2 := mcp.NewInMemoryTransports()
+	if _, err := srv.Connect(ctx, t1, nil); err != nil {
+		t.Fatalf("server connect: %v", err)
+	}
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0.0.1"}, nil)
+	session, err := client.Connect(ctx, t2, nil)
+	if err != nil {
+		t.Fatalf("client connect: %v", err)
+	}
+	t.Cleanup(func() { session.Close() })
+	return session
+}
+
+func complete(t *testing.T, cs *mcp.ClientSession, ref *mcp.CompleteReference, name, value string) mcp.CompletionResultDetails {
+	t.Helper()
+	result, err := cs.Complete(t.Context(), &mcp.CompleteParams{
+		Ref:      ref,
+		Argument: mcp.CompleteParamsArgument{Name: name, Value: value},
+	})
+	if err != nil {
+		t.Fatalf("Complete(%s=%q): %v", name, value, err)
+	}
+	return result.Completion
+}
+
+func prompt(name string) *mcp.CompleteReference {
+	return &mcp.CompleteReference{Type: "ref/prompt", Name: name}
+}
+
+func TestComplete_Hostnames(t *testing.T) {
+	mock := executor.NewMockExecutor().WithZaiaResponse("discover", executor.SyncResult(discoverJSON))
+	cs := connect(t, mock)
+
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"", []string{"api", "app", "db"}},
+		{"a", []string{"api", "app"}},
+		{"AP", []string{"api", "app"}},
+		{"x", []string{}},
+	}
+	for _, tt := range tests {
+		got := complete(t, cs, prompt("debug-service"), "serviceHostname", tt.value)
+		if !slices.Equal(got.Values, tt.want) {
+			t.Errorf("serviceHostname=%q: got %v, want %v", tt.value, got.Values, tt.want)
+		}
+	}
+}
+
+func TestComplete_HostnamesUnavailable(t *testing.T) {
+	mock := executor.NewMockExecutor().
+		WithZaiaResponse("discover", executor.ErrorResult("AUTH_REQUIRED", "not logged in", "", 1))
+	cs := connect(t, mock)
+
+	if got := complete(t, cs, prompt("debug-service"), "serviceHostname", "a"); len(got.Values) != 0 {
+		t.Errorf("got %v, want no values", got.Values)
+	}
+}
+
+func TestComplete_StaticEnums(t *testing.T) {
+	cs := connect(t, executor.NewMockExecutor())
+
+	tests := []struct {
+		ref, arg, value string
+		want            []string
+	}{
+		{"zerops_manage", "action", "s", []string{"start", "stop", "scale"}},
+		{"zerops_env", "action", "", []string{"get", "set", "delete"}},
+		{"zerops_subdomain", "action", "d", []string{"disable"}},
+		{"zerops_logs", "severity", "w", []string{"warning"}},
+		{"zerops_wait", "mode", "a", []string{"all", "any"}},
+		{"add-database", "databaseType", "postgresql", []string{"postgresql@16", "postgresql@17"}},
+		{"debug-service", "since", "", []string{"30m", "1h", "24h", "7d"}},
+		{"debug-service", "unknown", "", []string{}},
+	}
+	for _, tt := range tests {
+		got := complete(t, cs, prompt(tt.ref), tt.arg, tt.value)
+		if !slices.Equal(got.Values, tt.want) {
+			t.Errorf("%s %s=%q: got %v, want %v", tt.ref, tt.arg, tt.value, got.Values, tt.want)
+		}
+	}
+}
+
+func TestComplete_DocPaths(t *testing.T) {
+	mock := executor.NewMockExecutor().
+		WithZaiaResponse("search services postgres --limit 20", executor.SyncResult(`{"results":[
+			{"uri":"zerops://docs/config/env-variables"},
+			{"uri":"zerops://docs/services/postgresql"},
+			{"uri":"zerops://docs/services/postgresql"}
+		]}`))
+	cs := connect(t, mock)
+
+	ref := &mcp.CompleteReference{Type: "ref/resource", URI: "zerops://docs/{+path}"}
+	got := complete(t, cs, ref, "path", "services/postgres")
+	want := []string{"services/postgresql", "config/env-variables"}
+	if !slices.Equal(got.Values, want) {
+		t.Errorf("got %v, want %v", got.Values, want)
+	}
+
+	if got := complete(t, cs, ref, "path", ""); len(got.Values) != 0 {
+		t.Errorf("empty path: got %v, want no values", got.Values)
+	}
+	if n := mock.CallCount("zaia", "search services postgres --limit 20"); n != 1 {
+		t.Errorf("search ran %d times, want 1", n)
+	}
+}
+
+func TestComplete_CapsValues(t *testing.T) {
+	var services []string
+	for i := range 150 {
+		services = append(services, fmt.Sprintf(`{"hostname":"svc%03d"}`, i))
+	}
+	mock := executor.NewMockExecutor().
+		WithZaiaResponse("discover", executor.SyncResult(`{"services":[`+strings.Join(services, ",")+`]}`))
+	cs := connect(t, mock)
+
+	got := complete(t, cs, prompt("zerops_logs"), "serviceHostname", "svc")
+	if len(got.Values) != 100 || got.Total != 150 || !got.HasMore {
+		t.Errorf("got %d values, total %d, hasMore %v; want 100, 150, true", len(got.Values), got.Total, got.HasMore)
+	}
+}
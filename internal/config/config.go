@@ -0,0 +1,186 @@
+// Package config loads the optional zaia-mcp configuration file.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/zeropsio/zaia-mcp/internal/executor"
+)
+
+// Config is the content of the configuration file (JSON).
+type Config struct {
+	Env Env `json:"env"`
+	// ZeropsToken, if set, is passed to zaia/zcli as executor.TokenEnvVar.
+	ZeropsToken *Secret `json:"zeropsToken,omitempty"`
+	// Profiles are named project targets selectable per tool call.
+	Profiles map[string]Profile `json:"profiles,omitempty"`
+	// DefaultProfile is used by calls without a profile. Empty means the
+	// top-level settings.
+	DefaultProfile string `json:"defaultProfile,omitempty"`
+}
+
+// Profile is one named Zerops project target. Unset fields fall back to
+// the top-level settings.
+type Profile struct {
+	Description string  `json:"description,omitempty"`
+	ProjectID   string  `json:"projectId,omitempty"`
+	ZeropsToken *Secret `json:"zeropsToken,omitempty"`
+	ZaiaBinary  string  `json:"zaiaBinary,omitempty"`
+	ZcliBinary  string  `json:"zcliBinary,omitempty"`
+}
+
+// Env adjusts which host environment variables reach zaia/zcli.
+type Env struct {
+	Allow []string `json:"allow,omitempty"` // added to executor.DefaultEnvAllow()
+	Deny  []string `json:"deny,omitempty"`  // removed even if allowed
+}
+
+// Secret is a value given inline, read from a file, or taken from a
+// variable of the MCP host environment. Exactly one source must be set.
+type Secret struct {
+	Value string `json:"value,omitempty"`
+	File  string `json:"file,omitempty"`
+	Env   string `json:"env,omitempty"`
+}
+
+// Resolve returns the secret value with surrounding whitespace trimmed.
+func (s *Secret) Resolve() (string, error) {
+	sources := 0
+	for _, v := range []string{s.Value, s.File, s.Env} {
+		if v != "" {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return "", errors.New("exactly one of value, file or env must be set")
+	}
+	switch {
+	case s.File != "":
+		data, err := os.ReadFile(expandHome(s.File))
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(data)), nil
+	case s.Env != "":
+		v, ok := os.LookupEnv(s.Env)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", s.Env)
+		}
+		return strings.TrimSpace(v), nil
+	default:
+		return strings.TrimSpace(s.Value), nil
+	}
+}
+
+// DefaultPath returns ~/.zaia-mcp/config.json, or "" if the home directory
+// is unknown.
+func DefaultPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".zaia-mcp", "config.json")
+}
+
+// Load reads the configuration at path. A missing file yields an empty
+// Config; unknown fields are rejected.
+func Load(path string) (*Config, error) {
+	cfg := &Config{}
+	if path == "" {
+		return cfg, nil
+	}
+	data, err := os.ReadFile(expandHome(path))
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(cfg); err != nil {
+		return nil, fmt.Errorf("parse config %s: %w", path, err)
+	}
+	if _, ok := cfg.Profiles[cfg.DefaultProfile]; cfg.DefaultProfile != "" && !ok {
+		return nil, fmt.Errorf("config %s: defaultProfile %q is not a configured profile", path, cfg.DefaultProfile)
+	}
+	return cfg, nil
+}
+
+// EnvPolicy returns the subprocess environment policy for the top-level
+// settings, with the Zerops token resolved and injected.
+func (c *Config) EnvPolicy() (executor.EnvPolicy, error) {
+	return c.envPolicy(c.ZeropsToken)
+}
+
+// ProfileEnvPolicy returns the subprocess environment policy for the named
+// profile: its token (or the top-level one) is injected. zaia has no
+// project flag; it acts on the project of the token, which
+// executor.ProfileExecutor checks against the profile's projectId.
+func (c *Config) ProfileEnvPolicy(name string) (executor.EnvPolicy, error) {
+	p, ok := c.Profiles[name]
+	if !ok {
+		return executor.EnvPolicy{}, fmt.Errorf("unknown profile %q", name)
+	}
+	token := p.ZeropsToken
+	if token == nil {
+		token = c.ZeropsToken
+	}
+	policy, err := c.envPolicy(token)
+	if err != nil {
+		return executor.EnvPolicy{}, fmt.Errorf("profile %s: %w", name, err)
+	}
+	return policy, nil
+}
+
+func (c *Config) envPolicy(token *Secret) (executor.EnvPolicy, error) {
+	policy := executor.EnvPolicy{
+		Allow:  append(executor.DefaultEnvAllow(), c.Env.Allow...),
+		Deny:   c.Env.Deny,
+		Inject: map[string]string{},
+	}
+	if token != nil {
+		value, err := token.Resolve()
+		if err != nil {
+			return executor.EnvPolicy{}, fmt.Errorf("zeropsToken: %w", err)
+		}
+		if value == "" {
+			return executor.EnvPolicy{}, errors.New("zeropsToken: empty value")
+		}
+		policy.Inject[executor.TokenEnvVar] = value
+	}
+	if len(policy.Inject) == 0 {
+		policy.Inject = nil
+	}
+	return policy, nil
+}
+
+// ProfileNames returns the configured profile names, sorted.
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func expandHome(path string) string {
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, rest)
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/zeropsio/zaia-mcp/internal/executor"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad_Missing(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), "nope.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	policy, err := cfg.EnvPolicy()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if policy.Inject != nil {
+		t.Errorf("no token configured, got inject %v", policy.Inject)
+	}
+	if !slices.Equal(policy.Allow, executor.DefaultEnvAllow()) {
+		t.Errorf("allow: got %v", policy.Allow)
+	}
+}
+
+func TestLoad_UnknownField(t *testing.T) {
+	_, err := Load(writeConfig(t, `{"zeropsTokn": {"value": "x"}}`))
+	if err == nil || !strings.Contains(err.Error(), "zeropsTokn") {
+		t.Fatalf("expected unknown field error, got %v", err)
+	}
+}
+
+func TestEnvPolicy_TokenSources(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("file-token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("MY_ZEROPS_TOKEN", "env-token")
+
+	tests := []struct {
+		name    string
+		config  string
+		want    string
+		wantErr string
+	}{
+		{"value", `{"zeropsToken": {"value": "inline-token"}}`, "inline-token", ""},
+		{"file", `{"zeropsToken": {"file": "` + filepath.ToSlash(tokenFile) + `"}}`, "file-token", ""},
+		{"env", `{"zeropsToken": {"env": "MY_ZEROPS_TOKEN"}}`, "env-token", ""},
+		{"unset env", `{"zeropsToken": {"env": "NOT_SET_XYZ"}}`, "", "NOT_SET_XYZ"},
+		{"two sources", `{"zeropsToken": {"value": "a", "env": "MY_ZEROPS_TOKEN"}}`, "", "exactly one"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Load(writeConfig(t, tt.config))
+			if err != nil {
+				t.Fatalf("load: %v", err)
+			}
+			policy, err := cfg.EnvPolicy()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := policy.Inject[executor.TokenEnvVar]; got != tt.want {
+				t.Errorf("token: got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnvPolicy_AllowDeny(t *testing.T) {
+	cfg, err := Load(writeConfig(t, `{"env": {"allow": ["NODE_EXTRA_CA_CERTS"], "deny": ["ZAIA_*"]}}`))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	policy, _ := cfg.EnvPolicy()
+	env, _ := policy.Apply([]string{"NODE_EXTRA_CA_CERTS=/ca.pem", "ZAIA_DEBUG=1", "OTHER=x"}, "")
+	if want := []string{"NODE_EXTRA_CA_CERTS=/ca.pem"}; !slices.Equal(env, want) {
+		t.Errorf("got %v, want %v", env, want)
+	}
+}
+
+func TestProfileEnvPolicy(t *testing.T) {
+	cfg, err := Load(writeConfig(t, `{
+		"zeropsToken": {"value": "base-token"},
+		"defaultProfile": "staging",
+		"profiles": {
+			"staging": {"projectId": "stage-id"},
+			"prod": {"projectId": "prod-id", "zeropsToken": {"value": "prod-token"}}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got := cfg.ProfileNames(); !slices.Equal(got, []string{"prod", "staging"}) {
+		t.Errorf("names: got %v", got)
+	}
+
+	staging, err := cfg.ProfileEnvPolicy("staging")
+	if err != nil {
+		t.Fatalf("staging: %v", err)
+	}
+	if staging.Inject[executor.TokenEnvVar] != "base-token" {
+		t.Errorf("staging inject: got %v", staging.Inject)
+	}
+	prod, err := cfg.ProfileEnvPolicy("prod")
+	if err != nil {
+		t.Fatalf("prod: %v", err)
+	}
+	if prod.Inject[executor.TokenEnvVar] != "prod-token" {
+		t.Errorf("prod inject: got %v", prod.Inject)
+	}
+}
+
+func TestLoad_UnknownDefaultProfile(t *testing.T) {
+	_, err := Load(writeConfig(t, `{"defaultProfile": "prod"}`))
+	if err == nil || !strings.Contains(err.Error(), "defaultProfile") {
+		t.Fatalf("expected defaultProfile error, got %v", err)
+	}
+}
@@ -0,0 +1,276 @@
+// Package doctor diagnoses the zaia/zcli dependencies of the MCP server.
+package doctor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zeropsio/zaia-mcp/internal/executor"
+)
+
+// probeTimeout bounds each individual probe command.
+const probeTimeout = 15 * time.Second
+
+// Auth states reported in BinaryReport.Auth.
+const (
+	AuthOK         = "authenticated"
+	AuthMissing    = "not_authenticated"
+	AuthUnknown    = "unknown"
+	AuthNotChecked = "not_checked"
+)
+
+// zcliAuthFailure matches zcli errors caused by a missing or invalid login.
+var zcliAuthFailure = regexp.MustCompile(`(?i)(unauthori[sz]ed|unauthenticated|not logged in|zcli login|invalid token)`)
+
+// BinaryReport describes one CLI dependency.
+type BinaryReport struct {
+	Name       string `json:"name"`
+	Configured string `json:"configured,omitempty"`
+	Path       string `json:"path,omitempty"`
+	Found      bool   `json:"found"`
+	Version    string `json:"version,omitempty"`
+	Outdated   bool   `json:"outdated,omitempty"`
+	Auth       string `json:"auth"`
+	AuthDetail string `json:"authDetail,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Report is the outcome of a dependency check.
+type Report struct {
+	OK          bool           `json:"ok"`
+	PATH        string         `json:"path,omitempty"`
+	Binaries    []BinaryReport `json:"binaries"`
+	Remediation []string       `json:"remediation,omitempty"`
+}
+
+// Checker probes the zaia and zcli binaries through an executor.
+type Checker struct {
+	exec executor.Executor
+	// MinVersions maps binary name to the minimum supported version
+	// (e.g. {"zaia": "1.2.0"}). Binaries without an entry are not version-checked.
+	MinVersions map[string]string
+}
+
+// NewChecker creates a Checker without minimum versions. Path information
+// is available when the executor chain contains an executor.Locator (the
+// real CLIExecutor).
+func NewChecker(exec executor.Executor) *Checker {
+	return &Checker{exec: exec, MinVersions: map[string]string{}}
+}
+
+// Check probes both binaries: location, version and auth state.
+// With executor.WithProfile in ctx it checks that profile's setup.
+func (c *Checker) Check(ctx context.Context) *Report {
+	report := &Report{}
+	zaia := BinaryReport{Name: "zaia", Found: true, Auth: AuthUnknown}
+	zcli := BinaryReport{Name: "zcli", Found: true, Auth: AuthNotChecked}
+
+	if loc, ok := executor.FindFor[executor.Locator](ctx, c.exec); ok {
+		report.PATH = loc.ResolvedPATH()
+		for _, b := range loc.Binaries() {
+			target := &zaia
+			if b.Name == "zcli" {
+				target = &zcli
+			}
+			target.Configured = b.Configured
+			target.Path = b.Path
+			if b.Err != nil {
+				target.Found = false
+				target.Error = b.Err.Error()
+			}
+		}
+	}
+
+	if zaia.Found {
+		c.probeVersion(ctx, &zaia, c.exec.RunZaia)
+		c.probeZaiaAuth(ctx, &zaia)
+	}
+	if zcli.Found {
+		c.probeVersion(ctx, &zcli, c.exec.RunZcli)
+		c.probeZcliAuth(ctx, &zcli)
+	}
+
+	report.Binaries = []BinaryReport{zaia, zcli}
+	report.Remediation = remediation(report)
+	report.OK = zaia.Found && zcli.Found && zaia.Auth == AuthOK && zcli.Auth == AuthOK && !zaia.Outdated && !zcli.Outdated
+	return report
+}
+
+type runFunc func(ctx context.Context, args ...string) (*executor.Result, error)
+
+func (c *Checker) probeVersion(ctx context.Context, b *BinaryReport, run runFunc) {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	result, err := run(ctx, "version")
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) || errors.Is(err, fs.ErrNotExist) {
+			b.Found = false
+		}
+		b.Error = err.Error()
+		return
+	}
+	b.Version = parseVersion(result.Stdout)
+	if minVersion := c.MinVersions[b.Name]; minVersion != "" && b.Version != "" {
+		b.Outdated = compareVersions(b.Version, minVersion) < 0
+	}
+}
+
+func (c *Checker) probeZaiaAuth(ctx context.Context, b *BinaryReport) {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	// The probe must reflect current credentials, not a cached discover.
+	result, err := c.exec.RunZaia(executor.WithFresh(ctx), "discover")
+	if err != nil {
+		b.AuthDetail = err.Error()
+		return
+	}
+	var resp struct {
+		Type  string `json:"type"`
+		Code  string `json:"code"`
+		Error string `json:"error"`
+		Data  struct {
+			Project struct {
+				Name string `json:"name"`
+			} `json:"project"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(result.Stdout, &resp); err != nil {
+		b.AuthDetail = fmt.Sprintf("unparsable discover output (exit code %d)", result.ExitCode)
+		return
+	}
+	switch {
+	case resp.Type == "sync":
+		b.Auth = AuthOK
+		if resp.Data.Project.Name != "" {
+			b.AuthDetail = "project: " + resp.Data.Project.Name
+		}
+	case resp.Code == "AUTH_REQUIRED" || resp.Code == "AUTH_INVALID":
+		b.Auth = AuthMissing
+		b.AuthDetail = resp.Error
+	default:
+		b.AuthDetail = strings.TrimSpace(resp.Code + " " + resp.Error)
+	}
+}
+
+// probeZcliAuth runs `zcli project list`, the cheapest zcli command that
+// needs a login and changes nothing.
+func (c *Checker) probeZcliAuth(ctx context.Context, b *BinaryReport) {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	b.Auth = AuthUnknown
+	result, err := c.exec.RunZcli(ctx, "project", "list")
+	if err != nil {
+		b.AuthDetail = err.Error()
+		return
+	}
+	if result.ExitCode == 0 {
+		b.Auth = AuthOK
+		return
+	}
+	output := bytes.TrimSpace(result.Stderr)
+	if len(output) == 0 {
+		output = bytes.TrimSpace(result.Stdout)
+	}
+	line, _, _ := bytes.Cut(output, []byte("\n"))
+	b.AuthDetail = fmt.Sprintf("zcli project list: exit code %d", result.ExitCode)
+	if len(line) > 0 {
+		b.AuthDetail += ": " + string(line)
+	}
+	if zcliAuthFailure.Match(output) {
+		b.Auth = AuthMissing
+	}
+}
+
+// parseVersion accepts either a ZAIA JSON envelope ({"data":{"version":...}})
+// or plain text output, of which the first version-looking token is used.
+func parseVersion(stdout []byte) string {
+	var resp struct {
+		Data struct {
+			Version string `json:"version"`
+		} `json:"data"`
+	}
+	if json.Unmarshal(stdout, &resp) == nil && resp.Data.Version != "" {
+		return resp.Data.Version
+	}
+	line, _, _ := bytes.Cut(bytes.TrimSpace(stdout), []byte("\n"))
+	for _, field := range strings.Fields(string(line)) {
+		v := strings.TrimPrefix(field, "v")
+		if v != "" && v[0] >= '0' && v[0] <= '9' {
+			return v
+		}
+	}
+	return strings.TrimSpace(string(line))
+}
+
+// compareVersions compares dotted numeric versions ("1.10.2" vs "1.9").
+// Non-numeric suffixes are ignored.
+func compareVersions(a, b string) int {
+	pa := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	pb := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < max(len(pa), len(pb)); i++ {
+		na, nb := versionPart(pa, i), versionPart(pb, i)
+		if na != nb {
+			if na < nb {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func versionPart(parts []string, i int) int {
+	if i >= len(parts) {
+		return 0
+	}
+	digits := parts[i]
+	if end := strings.IndexFunc(digits, func(r rune) bool { return r < '0' || r > '9' }); end >= 0 {
+		digits = digits[:end]
+	}
+	n, _ := strconv.Atoi(digits)
+	return n
+}
+
+func remediation(r *Report) []string {
+	var steps []string
+	for _, b := range r.Binaries {
+		switch {
+		case !b.Found:
+			steps = append(steps, fmt.Sprintf("Install %s and make sure it is on the PATH of your login shell (%s); or restart the MCP client after installing.", b.Name, installHint(b.Name)))
+		case b.Outdated:
+			steps = append(steps, fmt.Sprintf("Upgrade %s (found %s).", b.Name, b.Version))
+		}
+	}
+	for _, b := range r.Binaries {
+		if !b.Found {
+			continue
+		}
+		probe := map[string]string{"zaia": "zaia discover", "zcli": "zcli project list"}[b.Name]
+		switch {
+		case b.Auth == AuthMissing:
+			steps = append(steps, fmt.Sprintf("Authenticate %s: %s login <token> (token from Zerops GUI → Settings → Access Token Management).", b.Name, b.Name))
+		case b.Auth == AuthUnknown && b.AuthDetail != "":
+			steps = append(steps, fmt.Sprintf("%s auth check failed: %s. Run `%s` in a terminal to see the full error.", b.Name, b.AuthDetail, probe))
+		}
+	}
+	return steps
+}
+
+func installHint(name string) string {
+	if name == "zcli" {
+		return "npm i -g @zerops/zcli, or https://github.com/zeropsio/zcli/releases"
+	}
+	return "https://github.com/krls2020/zaia/releases"
+}
@@ -0,0 +1,136 @@
+package doctor
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/zeropsio/zaia-mcp/internal/executor"
+)
+
+// locatingMock adds Locator to a MockExecutor.
+type locatingMock struct {
+	*executor.MockExecutor
+	binaries []executor.Binary
+}
+
+func (m *locatingMock) ResolvedPATH() string        { return "/usr/local/bin:/usr/bin" }
+func (m *locatingMock) Binaries() []executor.Binary { return m.binaries }
+
+func TestCheck_Healthy(t *testing.T) {
+	mock := &locatingMock{
+		MockExecutor: executor.NewMockExecutor().
+			WithZaiaResponse("version", executor.SyncResult(`{"version":"1.4.0"}`)).
+			WithZaiaResponse("discover", executor.SyncResult(`{"project":{"name":"myapp"},"services":[]}`)).
+			WithZcliResponse("version", &executor.Result{Stdout: []byte("zcli version v1.0.38 (linux/amd64)\n")}).
+			WithZcliResponse("project list", &executor.Result{Stdout: []byte("myapp  ACTIVE\n")}),
+		binaries: []executor.Binary{
+			{Name: "zaia", Configured: "zaia", Path: "/usr/local/bin/zaia"},
+			{Name: "zcli", Configured: "zcli", Path: "/usr/bin/zcli"},
+		},
+	}
+	checker := NewChecker(mock)
+	checker.MinVersions = map[string]string{"zaia": "1.0.0", "zcli": "1.0.0"}
+	report := checker.Check(t.Context())
+
+	if !report.OK {
+		t.Errorf("expected OK, got %+v", report)
+	}
+	if report.PATH != "/usr/local/bin:/usr/bin" {
+		t.Errorf("got PATH %q", report.PATH)
+	}
+	zaia, zcli := report.Binaries[0], report.Binaries[1]
+	if zaia.Version != "1.4.0" || zaia.Auth != AuthOK || zaia.Path != "/usr/local/bin/zaia" {
+		t.Errorf("zaia: got %+v", zaia)
+	}
+	if !strings.Contains(zaia.AuthDetail, "myapp") {
+		t.Errorf("zaia auth detail: got %q", zaia.AuthDetail)
+	}
+	if zcli.Version != "1.0.38" || zcli.Auth != AuthOK {
+		t.Errorf("zcli: got %+v", zcli)
+	}
+	if len(report.Remediation) != 0 {
+		t.Errorf("unexpected remediation: %v", report.Remediation)
+	}
+}
+
+func TestCheck_MissingBinaryAndAuth(t *testing.T) {
+	mock := &locatingMock{
+		MockExecutor: executor.NewMockExecutor().
+			WithZaiaResponse("version", executor.SyncResult(`{"version":"1.0.0"}`)).
+			WithZaiaResponse("discover", executor.ErrorResult("AUTH_REQUIRED", "Not authenticated", "Run: zaia login", 2)),
+		binaries: []executor.Binary{
+			{Name: "zaia", Configured: "zaia", Path: "/usr/local/bin/zaia"},
+			{Name: "zcli", Configured: "zcli", Err: errors.New("executable file not found in resolved PATH")},
+		},
+	}
+	checker := NewChecker(mock)
+	checker.MinVersions["zaia"] = "1.2.0"
+	report := checker.Check(t.Context())
+
+	if report.OK {
+		t.Fatal("expected not OK")
+	}
+	zaia, zcli := report.Binaries[0], report.Binaries[1]
+	if zaia.Auth != AuthMissing || !zaia.Outdated {
+		t.Errorf("zaia: got %+v", zaia)
+	}
+	if zcli.Found {
+		t.Errorf("zcli: expected not found, got %+v", zcli)
+	}
+	joined := strings.Join(report.Remediation, "\n")
+	for _, want := range []string{"Install zcli", "Upgrade zaia", "zaia login"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("remediation missing %q: %v", want, report.Remediation)
+		}
+	}
+}
+
+func TestCheck_ZcliNotLoggedIn(t *testing.T) {
+	mock := executor.NewMockExecutor().
+		WithZaiaResponse("version", executor.SyncResult(`{"version":"1.4.0"}`)).
+		WithZaiaResponse("discover", executor.SyncResult(`{"project":{"name":"myapp"}}`)).
+		WithZcliResponse("version", &executor.Result{Stdout: []byte("zcli version v0.12.0\n")}).
+		WithZcliResponse("project list", &executor.Result{Stderr: []byte("Error: unauthenticated user, run zcli login\n"), ExitCode: 1})
+	checker := NewChecker(mock)
+	checker.MinVersions["zcli"] = "1.0.0"
+	report := checker.Check(t.Context())
+
+	zcli := report.Binaries[1]
+	if report.OK || zcli.Auth != AuthMissing || !zcli.Outdated || !strings.Contains(zcli.AuthDetail, "unauthenticated user") {
+		t.Errorf("got OK=%v zcli=%+v", report.OK, zcli)
+	}
+	joined := strings.Join(report.Remediation, "\n")
+	for _, want := range []string{"Upgrade zcli", "zcli login"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("remediation missing %q: %v", want, report.Remediation)
+		}
+	}
+}
+
+func TestCheck_ExecNotFound(t *testing.T) {
+	mock := executor.NewMockExecutor().
+		WithZaiaError("version", &exec.Error{Name: "zaia", Err: exec.ErrNotFound})
+	report := NewChecker(mock).Check(t.Context())
+	if report.Binaries[0].Found {
+		t.Errorf("zaia: expected not found, got %+v", report.Binaries[0])
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.10.0", "1.9.9", 1},
+		{"v1.2", "1.2.0", 0},
+		{"1.2.0-rc1", "1.2.1", -1},
+		{"2", "10", -1},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
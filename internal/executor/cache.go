@@ -0,0 +1,303 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultCacheEntries bounds the number of cached results.
+const defaultCacheEntries = 256
+
+// pendingTTL bounds how long a submitted process counts as pending when
+// nobody reads its terminal state through the cache.
+const pendingTTL = 30 * time.Minute
+
+// DefaultCacheTTLs returns the default TTL per cacheable zaia subcommand.
+// discover is short-lived (service status changes); search covers both
+// knowledge queries and `search --get` document fetches.
+func DefaultCacheTTLs() map[string]time.Duration {
+	return map[string]time.Duration{
+		"discover": 15 * time.Second,
+		"search":   10 * time.Minute,
+	}
+}
+
+type freshKey struct{}
+
+// WithFresh returns a context that makes CachingExecutor bypass cached
+// results for calls made with it. Fresh results are still stored.
+func WithFresh(ctx context.Context) context.Context {
+	return context.WithValue(ctx, freshKey{}, true)
+}
+
+func isFresh(ctx context.Context) bool {
+	fresh, _ := ctx.Value(freshKey{}).(bool)
+	return fresh
+}
+
+// CachingExecutor is a read-through TTL cache for discover and search calls.
+// Successful mutations invalidate discover results for the affected service
+// (and project-wide discover); project-scoped mutations, zcli push and
+// processes reaching a terminal state invalidate all discover results.
+// While processes started by a mutation are pending, discover calls they
+// affect bypass the cache, since service status changes when they end.
+type CachingExecutor struct {
+	next    Executor
+	ttls    map[string]time.Duration
+	maxSize int
+	now     func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+	pending map[string]pendingProcess // by process ID
+}
+
+// pendingProcess is a process started by a mutation that has not been
+// seen in a terminal state.
+type pendingProcess struct {
+	hostname string // --service of the mutation ("" for project-scoped)
+	started  time.Time
+}
+
+type cacheEntry struct {
+	result   *Result
+	hostname string // --service of a discover call ("" for project-wide)
+	stored   time.Time
+	expires  time.Time
+}
+
+// NewCachingExecutor wraps next with a cache using ttls (per subcommand;
+// nil uses DefaultCacheTTLs).
+func NewCachingExecutor(next Executor, ttls map[string]time.Duration) *CachingExecutor {
+	if ttls == nil {
+		ttls = DefaultCacheTTLs()
+	}
+	return &CachingExecutor{
+		next:    next,
+		ttls:    ttls,
+		maxSize: defaultCacheEntries,
+		now:     time.Now,
+		entries: make(map[string]*cacheEntry),
+		pending: make(map[string]pendingProcess),
+	}
+}
+
+// Unwrap returns the wrapped executor.
+func (c *CachingExecutor) Unwrap() Executor {
+	return c.next
+}
+
+// RunZaia implements Executor. A profile selected with WithProfile is
+// rejected before the cache is consulted: a ProfileExecutor above clears
+// it, so one reaching here is not configured.
+func (c *CachingExecutor) RunZaia(ctx context.Context, args ...string) (*Result, error) {
+	if name := ProfileFrom(ctx); name != "" {
+		return nil, &UnknownProfileError{Name: name}
+	}
+	ttl := c.ttlFor(args)
+	if ttl <= 0 {
+		result, err := c.next.RunZaia(ctx, args...)
+		if err == nil {
+			c.observe(args, result)
+		}
+		return result, err
+	}
+
+	if args[0] == "discover" && c.hasPending(ServiceHostname(args)) {
+		return c.next.RunZaia(ctx, args...)
+	}
+	key := cacheKey(args)
+	if !isFresh(ctx) {
+		if cached := c.get(key); cached != nil {
+			return cached, nil
+		}
+	}
+
+	result, err := c.next.RunZaia(ctx, args...)
+	if err == nil && result.ExitCode == 0 && result.StdoutOverflow == nil {
+		c.put(key, args, result, ttl)
+	}
+	return result, err
+}
+
+// RunZcli implements Executor. A successful push invalidates discover results.
+func (c *CachingExecutor) RunZcli(ctx context.Context, args ...string) (*Result, error) {
+	if name := ProfileFrom(ctx); name != "" {
+		return nil, &UnknownProfileError{Name: name}
+	}
+	result, err := c.next.RunZcli(ctx, args...)
+	if err == nil && result.ExitCode == 0 && len(args) > 0 && args[0] == "push" {
+		c.Invalidate("")
+	}
+	return result, err
+}
+
+// Invalidate drops cached discover results for hostname and project-wide
+// discover. An empty hostname drops all discover results.
+func (c *CachingExecutor) Invalidate(hostname string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, e := range c.entries {
+		if !strings.HasPrefix(key, "discover") {
+			continue
+		}
+		if hostname == "" || e.hostname == "" || e.hostname == hostname {
+			delete(c.entries, key)
+		}
+	}
+}
+
+func (c *CachingExecutor) ttlFor(args []string) time.Duration {
+	if len(args) == 0 {
+		return 0
+	}
+	return c.ttls[args[0]]
+}
+
+// observe invalidates cache entries after uncached commands succeed and
+// tracks the processes mutations start.
+func (c *CachingExecutor) observe(args []string, result *Result) {
+	if result == nil || result.ExitCode != 0 {
+		return
+	}
+	switch {
+	case IsMutating(args):
+		hostname := ServiceHostname(args)
+		c.Invalidate(hostname)
+		c.addPending(hostname, startedProcesses(result))
+	case len(args) > 1 && args[0] == "process" && processFinished(result):
+		c.mu.Lock()
+		delete(c.pending, args[1])
+		c.mu.Unlock()
+		c.Invalidate("")
+	}
+}
+
+func (c *CachingExecutor) addPending(hostname string, ids []string) {
+	now := c.now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, id := range ids {
+		c.pending[id] = pendingProcess{hostname: hostname, started: now}
+	}
+}
+
+// hasPending reports whether a pending process affects discover results
+// for hostname ("" for project-wide discover). Expired entries are dropped.
+func (c *CachingExecutor) hasPending(hostname string) bool {
+	now := c.now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	found := false
+	for id, p := range c.pending {
+		if now.Sub(p.started) >= pendingTTL {
+			delete(c.pending, id)
+			continue
+		}
+		if hostname == "" || p.hostname == "" || p.hostname == hostname {
+			found = true
+		}
+	}
+	return found
+}
+
+// startedProcesses returns the process IDs of an async zaia envelope.
+func startedProcesses(result *Result) []string {
+	var resp struct {
+		Type      string `json:"type"`
+		Processes []struct {
+			ProcessID string `json:"processId"`
+		} `json:"processes"`
+	}
+	if json.Unmarshal(result.Stdout, &resp) != nil || resp.Type != "async" {
+		return nil
+	}
+	ids := make([]string, 0, len(resp.Processes))
+	for _, p := range resp.Processes {
+		if p.ProcessID != "" {
+			ids = append(ids, p.ProcessID)
+		}
+	}
+	return ids
+}
+
+// processFinished reports whether a `zaia process` result is in a terminal state.
+func processFinished(result *Result) bool {
+	var resp struct {
+		Data struct {
+			Status string `json:"status"`
+		} `json:"data"`
+	}
+	if json.Unmarshal(result.Stdout, &resp) != nil {
+		return false
+	}
+	switch resp.Data.Status {
+	case "FINISHED", "FAILED", "CANCELED":
+		return true
+	}
+	return false
+}
+
+func (c *CachingExecutor) get(key string) *Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	if !c.now().Before(e.expires) {
+		delete(c.entries, key)
+		return nil
+	}
+	out := *e.result
+	out.CachedAt = e.stored
+	return &out
+}
+
+func (c *CachingExecutor) put(key string, args []string, result *Result, ttl time.Duration) {
+	now := c.now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= c.maxSize {
+		c.evict(now)
+	}
+	c.entries[key] = &cacheEntry{
+		result:   result,
+		hostname: ServiceHostname(args),
+		stored:   now,
+		expires:  now.Add(ttl),
+	}
+}
+
+// evict drops expired entries, then the oldest one if still full.
+// Caller must hold c.mu.
+func (c *CachingExecutor) evict(now time.Time) {
+	var oldestKey string
+	var oldest time.Time
+	for key, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, key)
+			continue
+		}
+		if oldestKey == "" || e.stored.Before(oldest) {
+			oldestKey, oldest = key, e.stored
+		}
+	}
+	if len(c.entries) >= c.maxSize && oldestKey != "" {
+		delete(c.entries, oldestKey)
+	}
+}
+
+// cacheKey joins args. The query of `search <query>` is normalized:
+// surrounding whitespace is trimmed and inner whitespace runs collapsed,
+// so "search  postgresql " hits "search postgresql". Other arguments are
+// kept as is.
+func cacheKey(args []string) string {
+	if len(args) > 1 && args[0] == "search" && !strings.HasPrefix(args[1], "--") {
+		args = append([]string{args[0], strings.Join(strings.Fields(args[1]), " ")}, args[2:]...)
+	}
+	return strings.Join(args, "\x00")
+}
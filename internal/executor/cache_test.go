@@ -0,0 +1,225 @@
+package executor
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestCache(mock *MockExecutor) (*CachingExecutor, *time.Time) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewCachingExecutor(mock, nil)
+	c.now = func() time.Time { return now }
+	return c, &now
+}
+
+func TestCachingExecutor_HitAndExpiry(t *testing.T) {
+	mock := NewMockExecutor().WithDefault(SyncResult(`{"services":[]}`))
+	c, now := newTestCache(mock)
+
+	first, _ := c.RunZaia(t.Context(), "discover")
+	second, _ := c.RunZaia(t.Context(), "discover")
+	if len(mock.Calls) != 1 {
+		t.Fatalf("got %d calls, want 1 (second served from cache)", len(mock.Calls))
+	}
+	if !first.CachedAt.IsZero() {
+		t.Error("first result should not be marked cached")
+	}
+	if second.CachedAt.IsZero() {
+		t.Error("second result should be marked cached")
+	}
+
+	*now = now.Add(16 * time.Second)
+	_, _ = c.RunZaia(t.Context(), "discover")
+	if len(mock.Calls) != 2 {
+		t.Errorf("got %d calls, want 2 after TTL expiry", len(mock.Calls))
+	}
+}
+
+func TestCachingExecutor_NormalizedKey(t *testing.T) {
+	mock := NewMockExecutor().WithDefault(SyncResult(`{}`))
+	c, _ := newTestCache(mock)
+
+	_, _ = c.RunZaia(t.Context(), "search", "postgresql  connection")
+	_, _ = c.RunZaia(t.Context(), "search", " postgresql connection ")
+	if len(mock.Calls) != 1 {
+		t.Errorf("got %d calls, want 1", len(mock.Calls))
+	}
+}
+
+func TestCachingExecutor_Fresh(t *testing.T) {
+	mock := NewMockExecutor().WithDefault(SyncResult(`{}`))
+	c, _ := newTestCache(mock)
+
+	_, _ = c.RunZaia(t.Context(), "discover")
+	_, _ = c.RunZaia(WithFresh(t.Context()), "discover")
+	_, _ = c.RunZaia(t.Context(), "discover")
+	if len(mock.Calls) != 2 {
+		t.Errorf("got %d calls, want 2 (fresh bypasses, then refreshed entry is reused)", len(mock.Calls))
+	}
+}
+
+func TestCachingExecutor_DoesNotCacheErrors(t *testing.T) {
+	mock := NewMockExecutor().WithDefault(ErrorResult("API_ERROR", "boom", "", 1))
+	c, _ := newTestCache(mock)
+
+	_, _ = c.RunZaia(t.Context(), "discover")
+	_, _ = c.RunZaia(t.Context(), "discover")
+	if len(mock.Calls) != 2 {
+		t.Errorf("got %d calls, want 2", len(mock.Calls))
+	}
+}
+
+func TestCachingExecutor_MutationInvalidates(t *testing.T) {
+	mock := NewMockExecutor().
+		WithZaiaResponse("discover", SyncResult(`{}`)).
+		WithZaiaResponse("restart --service api", AsyncResult(`[{"processId":"p1"}]`)).
+		WithZaiaResponse("process p1", SyncResult(`{"processId":"p1","status":"FINISHED"}`)).
+		WithZaiaResponse("search postgresql", SyncResult(`{}`))
+	c, _ := newTestCache(mock)
+
+	warm := func() {
+		_, _ = c.RunZaia(t.Context(), "discover")
+		_, _ = c.RunZaia(t.Context(), "discover", "--service", "api")
+		_, _ = c.RunZaia(t.Context(), "discover", "--service", "db")
+		_, _ = c.RunZaia(t.Context(), "search", "postgresql")
+	}
+	warm()
+	if len(c.entries) != 4 {
+		t.Fatalf("got %d cache entries, want 4", len(c.entries))
+	}
+
+	_, _ = c.RunZaia(t.Context(), "restart", "--service", "api")
+	if _, ok := c.entries[cacheKey([]string{"discover", "--service", "db"})]; !ok {
+		t.Error("unrelated service entry was invalidated")
+	}
+	if _, ok := c.entries[cacheKey([]string{"discover", "--service", "api"})]; ok {
+		t.Error("service entry not invalidated by mutation")
+	}
+	if _, ok := c.entries[cacheKey([]string{"discover"})]; ok {
+		t.Error("project-wide discover not invalidated by mutation")
+	}
+
+	warm()
+	_, _ = c.RunZaia(t.Context(), "process", "p1")
+	if len(c.entries) != 1 {
+		t.Errorf("got %d entries after finished process, want 1 (search only)", len(c.entries))
+	}
+}
+
+func TestCachingExecutor_PushInvalidates(t *testing.T) {
+	mock := NewMockExecutor().WithDefault(SyncResult(`{}`))
+	c, _ := newTestCache(mock)
+
+	_, _ = c.RunZaia(t.Context(), "discover")
+	_, _ = c.RunZcli(t.Context(), "push")
+	_, _ = c.RunZaia(t.Context(), "discover")
+	if len(mock.Calls) != 3 {
+		t.Errorf("got %d calls, want 3", len(mock.Calls))
+	}
+}
+
+func TestCachingExecutor_Eviction(t *testing.T) {
+	mock := NewMockExecutor().WithDefault(SyncResult(`{}`))
+	c, now := newTestCache(mock)
+	c.maxSize = 2
+
+	_, _ = c.RunZaia(t.Context(), "search", "a")
+	*now = now.Add(time.Second)
+	_, _ = c.RunZaia(t.Context(), "search", "b")
+	*now = now.Add(time.Second)
+	_, _ = c.RunZaia(t.Context(), "search", "c")
+
+	if len(c.entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(c.entries))
+	}
+	if _, ok := c.entries[cacheKey([]string{"search", "a"})]; ok {
+		t.Error("oldest entry was not evicted")
+	}
+}
+
+func TestCachingExecutor_BypassWhileProcessPending(t *testing.T) {
+	mock := NewMockExecutor().
+		WithZaiaResponse("discover", SyncResult(`{}`)).
+		WithZaiaResponse("stop --service api", AsyncResult(`[{"processId":"p1"}]`)).
+		WithZaiaResponse("process p1", SyncResult(`{"processId":"p1","status":"RUNNING"}`))
+	c, now := newTestCache(mock)
+
+	_, _ = c.RunZaia(t.Context(), "stop", "--service", "api")
+	discover := func(args ...string) {
+		_, _ = c.RunZaia(t.Context(), append([]string{"discover"}, args...)...)
+	}
+	for range 2 {
+		discover()
+		discover("--service", "api")
+		discover("--service", "db")
+	}
+	if n := mock.CallCount("zaia", "discover"); n != 2 {
+		t.Errorf("project discover ran %d times, want 2 (not cached while p1 is pending)", n)
+	}
+	if n := mock.CallCount("zaia", "discover --service api"); n != 2 {
+		t.Errorf("api discover ran %d times, want 2", n)
+	}
+	if n := mock.CallCount("zaia", "discover --service db"); n != 1 {
+		t.Errorf("db discover ran %d times, want 1 (unaffected by p1)", n)
+	}
+
+	_, _ = c.RunZaia(t.Context(), "process", "p1") // still running
+	discover()
+	if n := mock.CallCount("zaia", "discover"); n != 3 {
+		t.Errorf("project discover ran %d times, want 3", n)
+	}
+
+	*now = now.Add(pendingTTL)
+	discover()
+	discover()
+	if n := mock.CallCount("zaia", "discover"); n != 4 {
+		t.Errorf("project discover ran %d times, want 4 (cached again once p1 expired)", n)
+	}
+}
+
+func TestCachingExecutor_FinishedProcessResumesCaching(t *testing.T) {
+	mock := NewMockExecutor().
+		WithZaiaResponse("discover", SyncResult(`{}`)).
+		WithZaiaResponse("import", AsyncResult(`[{"processId":"p1"}]`)).
+		WithZaiaResponse("process p1", SyncResult(`{"processId":"p1","status":"FINISHED"}`))
+	c, _ := newTestCache(mock)
+
+	_, _ = c.RunZaia(t.Context(), "import", "--content", "services: []")
+	_, _ = c.RunZaia(t.Context(), "process", "p1")
+	_, _ = c.RunZaia(t.Context(), "discover")
+	_, _ = c.RunZaia(t.Context(), "discover")
+	if n := mock.CallCount("zaia", "discover"); n != 1 {
+		t.Errorf("discover ran %d times, want 1", n)
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	if cacheKey([]string{"search", " a  b "}) != cacheKey([]string{"search", "a b"}) {
+		t.Error("search query not normalized")
+	}
+	if cacheKey([]string{"search", "--get", "zerops://docs/a  b"}) == cacheKey([]string{"search", "--get", "zerops://docs/a b"}) {
+		t.Error("search --get URI was normalized")
+	}
+	if cacheKey([]string{"discover", "--service", "a b"}) == cacheKey([]string{"discover", "--service", "a  b"}) {
+		t.Error("discover flag value was normalized")
+	}
+}
+
+func TestCachingExecutor_RejectsUnconfiguredProfile(t *testing.T) {
+	mock := NewMockExecutor().WithDefault(SyncResult(`{"project":{"name":"default"}}`))
+	c, _ := newTestCache(mock)
+	_, _ = c.RunZaia(t.Context(), "discover")
+
+	_, err := c.RunZaia(WithProfile(t.Context(), "production"), "discover")
+	var unknown *UnknownProfileError
+	if !errors.As(err, &unknown) || unknown.Name != "production" {
+		t.Errorf("got %v, want UnknownProfileError instead of the cached discover", err)
+	}
+	if _, err := c.RunZcli(WithProfile(t.Context(), "production"), "push"); !errors.As(err, &unknown) {
+		t.Errorf("zcli: got %v, want UnknownProfileError", err)
+	}
+	if len(mock.Calls) != 1 {
+		t.Errorf("got %d calls, want only the first discover", len(mock.Calls))
+	}
+}
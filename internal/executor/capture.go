@@ -0,0 +1,109 @@
+package executor
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// DefaultMaxStdout is the default in-memory cap on captured stdout.
+	DefaultMaxStdout = 256 * 1024
+	// DefaultMaxStderr is the default in-memory cap on captured stderr.
+	DefaultMaxStderr = 64 * 1024
+)
+
+// SpillTTL is how long stdout spill files are kept. Older ones are removed
+// whenever a new one is created and by RemoveStaleSpills.
+const SpillTTL = time.Hour
+
+// spillPattern names stdout spill files in the temp dir.
+const spillPattern = "zaia-mcp-stdout-*.json"
+
+// Overflow describes subprocess output that exceeded the capture limit.
+type Overflow struct {
+	Size int64  // total bytes written by the process
+	Path string // temp file with the complete output ("" if not spilled)
+}
+
+// cappedWriter keeps the first limit bytes in memory. Once the limit is
+// exceeded, everything written so far and all further output goes to a
+// temp file so the complete output stays available on disk. Without a
+// pattern, output past the limit is only counted.
+type cappedWriter struct {
+	limit   int
+	pattern string // os.CreateTemp pattern; "" disables spilling
+	buf     bytes.Buffer
+	size    int64
+	file    *os.File
+	fileErr error
+}
+
+func newCappedWriter(limit int, pattern string) *cappedWriter {
+	return &cappedWriter{limit: limit, pattern: pattern}
+}
+
+// Write never fails, so the subprocess is not disturbed by spill errors;
+// output that cannot be spilled is dropped.
+func (w *cappedWriter) Write(p []byte) (int, error) {
+	w.size += int64(len(p))
+	buffered := w.buf.Len()
+
+	if w.file == nil && w.fileErr == nil && buffered+len(p) <= w.limit {
+		w.buf.Write(p)
+		return len(p), nil
+	}
+
+	if room := w.limit - buffered; room > 0 {
+		w.buf.Write(p[:min(room, len(p))])
+	}
+	if w.pattern == "" {
+		return len(p), nil
+	}
+	if w.file == nil && w.fileErr == nil {
+		w.file, w.fileErr = os.CreateTemp("", w.pattern)
+		if w.fileErr == nil {
+			_, w.fileErr = w.file.Write(w.buf.Bytes()[:buffered])
+		}
+	}
+	if w.fileErr == nil {
+		_, w.fileErr = w.file.Write(p)
+	}
+	return len(p), nil
+}
+
+// finish closes the spill file and returns the captured prefix together
+// with an Overflow description when the limit was exceeded.
+func (w *cappedWriter) finish() ([]byte, *Overflow) {
+	if w.size <= int64(w.limit) {
+		return w.buf.Bytes(), nil
+	}
+	overflow := &Overflow{Size: w.size}
+	if w.file != nil {
+		if err := w.file.Close(); err == nil && w.fileErr == nil {
+			overflow.Path = w.file.Name()
+		} else {
+			_ = os.Remove(w.file.Name())
+		}
+	}
+	return w.buf.Bytes(), overflow
+}
+
+// RemoveStaleSpills removes stdout spill files in the temp dir that are
+// older than maxAge, including those left by earlier server runs.
+func RemoveStaleSpills(maxAge time.Duration) {
+	removeStaleSpills(os.TempDir(), time.Now().Add(-maxAge))
+}
+
+func removeStaleSpills(dir string, before time.Time) {
+	// Earlier versions also spilled stderr.
+	for _, pattern := range []string{spillPattern, "zaia-mcp-stderr-*.log"} {
+		paths, _ := filepath.Glob(filepath.Join(dir, pattern))
+		for _, path := range paths {
+			if info, err := os.Stat(path); err == nil && info.ModTime().Before(before) {
+				_ = os.Remove(path)
+			}
+		}
+	}
+}
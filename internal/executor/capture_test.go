@@ -0,0 +1,142 @@
+package executor
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCappedWriter_UnderLimit(t *testing.T) {
+	w := newCappedWriter(10, "capture-test-*")
+	_, _ = w.Write([]byte("hello"))
+	_, _ = w.Write([]byte("world"))
+
+	data, overflow := w.finish()
+	if string(data) != "helloworld" {
+		t.Errorf("got %q, want %q", data, "helloworld")
+	}
+	if overflow != nil {
+		t.Errorf("unexpected overflow: %+v", overflow)
+	}
+}
+
+func TestCappedWriter_SpillsToFile(t *testing.T) {
+	w := newCappedWriter(8, "capture-test-*")
+	_, _ = w.Write([]byte("hello"))
+	_, _ = w.Write([]byte("world"))
+	_, _ = w.Write([]byte("!!"))
+
+	data, overflow := w.finish()
+	if string(data) != "hellowor" {
+		t.Errorf("got %q, want %q", data, "hellowor")
+	}
+	if overflow == nil {
+		t.Fatal("expected overflow")
+	}
+	if overflow.Size != 12 {
+		t.Errorf("got size %d, want 12", overflow.Size)
+	}
+	if overflow.Path == "" {
+		t.Fatal("expected spill file path")
+	}
+	t.Cleanup(func() { _ = os.Remove(overflow.Path) })
+
+	full, err := os.ReadFile(overflow.Path)
+	if err != nil {
+		t.Fatalf("reading spill file: %v", err)
+	}
+	if string(full) != "helloworld!!" {
+		t.Errorf("spill file: got %q, want %q", full, "helloworld!!")
+	}
+}
+
+func TestCLIExecutor_OutputLimit(t *testing.T) {
+	exec := NewCLIExecutor("sh", "sh")
+	exec.MaxStdout = 100
+
+	result, err := exec.RunZaia(t.Context(), "-c", "head -c 1000 /dev/zero")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Stdout) != 100 {
+		t.Errorf("got %d stdout bytes, want 100", len(result.Stdout))
+	}
+	if result.StdoutOverflow == nil {
+		t.Fatal("expected stdout overflow")
+	}
+	if result.StdoutOverflow.Size != 1000 {
+		t.Errorf("got overflow size %d, want 1000", result.StdoutOverflow.Size)
+	}
+	t.Cleanup(func() { _ = os.Remove(result.StdoutOverflow.Path) })
+
+	full, err := os.ReadFile(result.StdoutOverflow.Path)
+	if err != nil {
+		t.Fatalf("reading spill file: %v", err)
+	}
+	if !bytes.Equal(full, make([]byte, 1000)) {
+		t.Errorf("spill file has %d bytes, want 1000 zero bytes", len(full))
+	}
+	if result.StderrOverflow != nil {
+		t.Error("unexpected stderr overflow")
+	}
+}
+
+func TestCappedWriter_WithoutPatternCountsOnly(t *testing.T) {
+	w := newCappedWriter(4, "")
+	_, _ = w.Write([]byte("hello world"))
+
+	data, overflow := w.finish()
+	if string(data) != "hell" {
+		t.Errorf("got %q, want %q", data, "hell")
+	}
+	if overflow == nil || overflow.Size != 11 || overflow.Path != "" {
+		t.Errorf("got overflow %+v, want size 11 without a file", overflow)
+	}
+}
+
+func TestCLIExecutor_StderrNotSpilled(t *testing.T) {
+	exec := NewCLIExecutor("sh", "sh")
+	exec.MaxStderr = 10
+
+	result, err := exec.RunZaia(t.Context(), "-c", "head -c 1000 /dev/zero >&2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Stderr) != 10 {
+		t.Errorf("got %d stderr bytes, want 10", len(result.Stderr))
+	}
+	if o := result.StderrOverflow; o == nil || o.Size != 1000 || o.Path != "" {
+		t.Errorf("got stderr overflow %+v, want size 1000 without a file", o)
+	}
+}
+
+func TestRemoveStaleSpills(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-2 * SpillTTL)
+	files := map[string]bool{ // name -> removed
+		"zaia-mcp-stdout-1.json": true,
+		"zaia-mcp-stderr-1.log":  true,
+		"zaia-mcp-stdout-2.json": false, // recent
+		"other.json":             false,
+	}
+	for name, stale := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if stale || name == "other.json" {
+			_ = os.Chtimes(path, old, old)
+		}
+	}
+
+	removeStaleSpills(dir, time.Now().Add(-SpillTTL))
+
+	for name, removed := range files {
+		_, err := os.Stat(filepath.Join(dir, name))
+		if gone := os.IsNotExist(err); gone != removed {
+			t.Errorf("%s: removed=%v, want %v", name, gone, removed)
+		}
+	}
+}
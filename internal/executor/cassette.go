@@ -0,0 +1,414 @@
+package executor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxCassetteLine bounds a single cassette line (one recorded call).
+const maxCassetteLine = 16 * 1024 * 1024
+
+// CassetteEntry is one recorded executor call, stored as a JSONL line.
+// Secrets in args and output are redacted when recording (see redactArgs).
+type CassetteEntry struct {
+	Binary     string   `json:"binary"` // "zaia" or "zcli"
+	Args       []string `json:"args"`
+	Stdout     string   `json:"stdout"`
+	Stderr     string   `json:"stderr,omitempty"`
+	ExitCode   int      `json:"exitCode"`
+	Error      string   `json:"error,omitempty"`     // Go error returned by the executor
+	ErrorKind  string   `json:"errorKind,omitempty"` // one of the errorKind* values
+	TimeoutMs  int64    `json:"timeoutMs,omitempty"` // configured limit of a timeout error
+	DurationMs int64    `json:"durationMs"`
+}
+
+// Kinds of recorded errors that replay as the same error type.
+const (
+	errorKindTimeout  = "timeout"  // *TimeoutError
+	errorKindCanceled = "canceled" // context.Canceled
+	errorKindDeadline = "deadline" // context.DeadlineExceeded
+	errorKindNotFound = "notFound" // exec.ErrNotFound
+)
+
+// Key returns the lookup key "binary arg1 arg2 ...", matching the
+// MockExecutor key format.
+func (e *CassetteEntry) Key() string {
+	return callKey(e.Binary, e.Args)
+}
+
+// setError records err and its kind.
+func (e *CassetteEntry) setError(err error) {
+	e.Error = err.Error()
+	var timeoutErr *TimeoutError
+	switch {
+	case errors.As(err, &timeoutErr):
+		e.ErrorKind = errorKindTimeout
+		e.TimeoutMs = timeoutErr.Timeout.Milliseconds()
+	case errors.Is(err, context.Canceled):
+		e.ErrorKind = errorKindCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		e.ErrorKind = errorKindDeadline
+	case errors.Is(err, exec.ErrNotFound):
+		e.ErrorKind = errorKindNotFound
+	}
+}
+
+// replayError returns the recorded error as its recorded kind.
+func (e *CassetteEntry) replayError() error {
+	switch e.ErrorKind {
+	case errorKindTimeout:
+		return &TimeoutError{
+			Command: e.Key(),
+			Timeout: time.Duration(e.TimeoutMs) * time.Millisecond,
+			Elapsed: time.Duration(e.DurationMs) * time.Millisecond,
+		}
+	case errorKindCanceled:
+		return &replayedError{msg: e.Error, kind: context.Canceled}
+	case errorKindDeadline:
+		return &replayedError{msg: e.Error, kind: context.DeadlineExceeded}
+	case errorKindNotFound:
+		return &replayedError{msg: e.Error, kind: exec.ErrNotFound}
+	}
+	return errors.New(e.Error)
+}
+
+// replayedError is a recorded error message that unwraps to its kind.
+type replayedError struct {
+	msg  string
+	kind error
+}
+
+func (e *replayedError) Error() string { return e.msg }
+func (e *replayedError) Unwrap() error { return e.kind }
+
+func callKey(binary string, args []string) string {
+	return binary + " " + strings.Join(args, " ")
+}
+
+// RecordingExecutor wraps an Executor and appends every call to a JSONL cassette.
+type RecordingExecutor struct {
+	next Executor
+
+	mu  sync.Mutex
+	enc *json.Encoder
+	err error // first write error; recording stops after it
+}
+
+// NewRecordingExecutor records every call made through next to w.
+func NewRecordingExecutor(next Executor, w io.Writer) *RecordingExecutor {
+	return &RecordingExecutor{next: next, enc: json.NewEncoder(w)}
+}
+
+// Err returns the first error encountered while writing the cassette.
+func (r *RecordingExecutor) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// Unwrap returns the wrapped executor.
+func (r *RecordingExecutor) Unwrap() Executor {
+	return r.next
+}
+
+// RunZaia implements Executor.
+func (r *RecordingExecutor) RunZaia(ctx context.Context, args ...string) (*Result, error) {
+	start := time.Now()
+	result, err := r.next.RunZaia(ctx, args...)
+	r.record("zaia", args, result, err, time.Since(start))
+	return result, err
+}
+
+// RunZcli implements Executor.
+func (r *RecordingExecutor) RunZcli(ctx context.Context, args ...string) (*Result, error) {
+	start := time.Now()
+	result, err := r.next.RunZcli(ctx, args...)
+	r.record("zcli", args, result, err, time.Since(start))
+	return result, err
+}
+
+func (r *RecordingExecutor) record(binary string, args []string, result *Result, err error, d time.Duration) {
+	entry := CassetteEntry{
+		Binary:     binary,
+		Args:       redactArgs(args),
+		DurationMs: d.Milliseconds(),
+	}
+	if result != nil {
+		entry.Stdout = string(result.Stdout)
+		entry.Stderr = string(result.Stderr)
+		entry.ExitCode = result.ExitCode
+	}
+	if err != nil {
+		entry.setError(err)
+	}
+	entry.redactOutput(args)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err == nil {
+		r.err = r.enc.Encode(entry)
+	}
+}
+
+// ReadCassette parses JSONL cassette entries from r.
+func ReadCassette(r io.Reader) ([]CassetteEntry, error) {
+	var entries []CassetteEntry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxCassetteLine)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var e CassetteEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("cassette line %d: %w", line, err)
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading cassette: %w", err)
+	}
+	return entries, nil
+}
+
+// LoadCassette reads a JSONL cassette file.
+func LoadCassette(path string) ([]CassetteEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadCassette(f)
+}
+
+// ReplayMode selects how ReplayExecutor matches calls to cassette entries.
+type ReplayMode int
+
+const (
+	// ReplayInOrder requires calls to arrive in exactly the recorded order.
+	ReplayInOrder ReplayMode = iota
+	// ReplayByKey matches calls by binary and args. Repeated calls with the
+	// same key get the recorded entries in order; the last one is repeated
+	// once they run out.
+	ReplayByKey
+)
+
+// ReplayExecutor serves recorded cassette entries instead of running CLIs.
+type ReplayExecutor struct {
+	mode ReplayMode
+
+	mu      sync.Mutex
+	entries []CassetteEntry
+	next    int              // ReplayInOrder: index of the next expected entry
+	byKey   map[string][]int // ReplayByKey: entry indexes per key
+	served  map[string]int   // ReplayByKey: entries served per key
+}
+
+// NewReplayExecutor creates a replay executor over entries.
+func NewReplayExecutor(entries []CassetteEntry, mode ReplayMode) *ReplayExecutor {
+	r := &ReplayExecutor{
+		mode:    mode,
+		entries: entries,
+		byKey:   make(map[string][]int),
+		served:  make(map[string]int),
+	}
+	for i := range entries {
+		key := entries[i].Key()
+		r.byKey[key] = append(r.byKey[key], i)
+	}
+	return r
+}
+
+// RunZaia implements Executor.
+func (r *ReplayExecutor) RunZaia(ctx context.Context, args ...string) (*Result, error) {
+	return r.replay(ctx, "zaia", args)
+}
+
+// RunZcli implements Executor.
+func (r *ReplayExecutor) RunZcli(ctx context.Context, args ...string) (*Result, error) {
+	return r.replay(ctx, "zcli", args)
+}
+
+// Remaining returns the number of entries not yet served in ReplayInOrder mode.
+func (r *ReplayExecutor) Remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.entries) - r.next
+}
+
+func (r *ReplayExecutor) replay(ctx context.Context, binary string, args []string) (*Result, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	key := callKey(binary, redactArgs(args))
+
+	r.mu.Lock()
+	entry, err := r.lookup(key)
+	r.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.Error != "" {
+		return nil, entry.replayError()
+	}
+	result := &Result{
+		Stdout:   []byte(entry.Stdout),
+		Stderr:   []byte(entry.Stderr),
+		ExitCode: entry.ExitCode,
+	}
+	replayLines(ctx, result)
+	return result, nil
+}
+
+// lookup finds the entry for key. Caller must hold r.mu.
+func (r *ReplayExecutor) lookup(key string) (*CassetteEntry, error) {
+	switch r.mode {
+	case ReplayInOrder:
+		if r.next >= len(r.entries) {
+			return nil, fmt.Errorf("replay: unexpected call %q after cassette end (%d entries)", key, len(r.entries))
+		}
+		entry := &r.entries[r.next]
+		if entry.Key() != key {
+			return nil, fmt.Errorf("replay: call %d: got %q, want %q", r.next+1, key, entry.Key())
+		}
+		r.next++
+		return entry, nil
+	case ReplayByKey:
+		indexes := r.byKey[key]
+		if len(indexes) == 0 {
+			return nil, fmt.Errorf("replay: no recorded call for %q", key)
+		}
+		n := r.served[key]
+		r.served[key] = n + 1
+		return &r.entries[indexes[min(n, len(indexes)-1)]], nil
+	default:
+		return nil, fmt.Errorf("replay: unknown mode %d", r.mode)
+	}
+}
+
+// redactArgs returns args with secrets replaced the way audit entries
+// redact tool arguments: values of `env set` KEY=value assignments and of
+// secret-looking flags become Redacted, --content is summarized. Replay
+// applies it to incoming calls too, so redacted entries still match, and
+// TimeoutError uses it for its command line.
+func redactArgs(args []string) []string {
+	out := slices.Clone(args)
+	envSet := len(args) >= 2 && args[0] == "env" && args[1] == "set"
+	for i := 0; i < len(out); i++ {
+		arg := out[i]
+		if !strings.HasPrefix(arg, "--") {
+			if envSet && i >= 2 {
+				out[i] = RedactVariable(arg)
+			}
+			continue
+		}
+		flag, value, inline := strings.Cut(arg, "=")
+		var redact func(string) string
+		switch name := strings.TrimPrefix(flag, "--"); {
+		case name == "content":
+			redact = SummarizeContent
+		case IsSecretName(name):
+			redact = func(string) string { return Redacted }
+		default:
+			continue
+		}
+		switch {
+		case inline:
+			out[i] = flag + "=" + redact(value)
+		case i+1 < len(out):
+			i++
+			out[i] = redact(out[i])
+		}
+	}
+	return out
+}
+
+// redactOutput removes secrets from the recorded output and error: the
+// values args redacted (the CLI may echo them) and env var values in
+// stdout, e.g. of `discover --include-envs` or `env get`.
+func (e *CassetteEntry) redactOutput(args []string) {
+	var secrets []string
+	for i, redacted := range redactArgs(args) {
+		if redacted == args[i] {
+			continue
+		}
+		value := args[i]
+		if key, v, found := strings.Cut(value, "="); found && strings.HasPrefix(redacted, key+"=") {
+			value = v
+		}
+		if len(value) >= minSecretLen {
+			secrets = append(secrets, value)
+		}
+	}
+	if r := newRedactor(secrets); r != nil {
+		e.Stdout = string(r.bytes([]byte(e.Stdout)))
+		e.Stderr = string(r.bytes([]byte(e.Stderr)))
+		e.Error = string(r.bytes([]byte(e.Error)))
+	}
+	e.Stdout = redactEnvValues(e.Stdout)
+}
+
+// envFields are the JSON fields of CLI output that hold env vars.
+var envFields = map[string]bool{"envs": true, "envVars": true, "projectEnvs": true}
+
+// redactEnvValues redacts the values under envFields and secret-looking
+// fields of JSON stdout. Output without such values is returned as is.
+func redactEnvValues(stdout string) string {
+	dec := json.NewDecoder(strings.NewReader(stdout))
+	dec.UseNumber()
+	var v any
+	if dec.Decode(&v) != nil {
+		return stdout
+	}
+	changed := false
+	v = walkSecrets(v, false, &changed)
+	if !changed {
+		return stdout
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if enc.Encode(v) != nil {
+		return stdout
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// walkSecrets replaces string values in v with Redacted: all of them when
+// secret is set (except "key" fields naming an env var), otherwise those
+// under envFields and secret-looking fields.
+func walkSecrets(v any, secret bool, changed *bool) any {
+	switch x := v.(type) {
+	case map[string]any:
+		for k, item := range x {
+			if secret && k == "key" {
+				continue
+			}
+			x[k] = walkSecrets(item, secret || envFields[k] || IsSecretName(k), changed)
+		}
+	case []any:
+		for i, item := range x {
+			x[i] = walkSecrets(item, secret, changed)
+		}
+	case string:
+		if secret && x != Redacted {
+			*changed = true
+			return Redacted
+		}
+	}
+	return v
+}
@@ -0,0 +1,191 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordingExecutor_WritesCassette(t *testing.T) {
+	mock := NewMockExecutor().
+		WithZaiaResponse("discover", SyncResult(`{"services":[]}`)).
+		WithZcliResponse("push", ErrorResult("BUILD_FAILED", "build failed", "", 1))
+
+	var buf bytes.Buffer
+	rec := NewRecordingExecutor(mock, &buf)
+	_, _ = rec.RunZaia(t.Context(), "discover")
+	_, _ = rec.RunZcli(t.Context(), "push")
+	_, _ = rec.RunZaia(t.Context(), "unknown")
+
+	if err := rec.Err(); err != nil {
+		t.Fatalf("recording error: %v", err)
+	}
+	entries, err := ReadCassette(&buf)
+	if err != nil {
+		t.Fatalf("ReadCassette: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	if entries[0].Key() != "zaia discover" {
+		t.Errorf("entry 0: got key %q", entries[0].Key())
+	}
+	if entries[0].Stdout != `{"type":"sync","status":"ok","data":{"services":[]}}` {
+		t.Errorf("entry 0: got stdout %q", entries[0].Stdout)
+	}
+	if entries[1].Binary != "zcli" || entries[1].ExitCode != 1 {
+		t.Errorf("entry 1: got binary %q exit %d", entries[1].Binary, entries[1].ExitCode)
+	}
+	if entries[2].Error == "" {
+		t.Error("entry 2: expected recorded executor error")
+	}
+}
+
+func TestReplayExecutor_InOrder(t *testing.T) {
+	entries := []CassetteEntry{
+		{Binary: "zaia", Args: []string{"process", "p1"}, Stdout: `{"status":"PENDING"}`},
+		{Binary: "zaia", Args: []string{"process", "p1"}, Stdout: `{"status":"FINISHED"}`},
+	}
+	replay := NewReplayExecutor(entries, ReplayInOrder)
+
+	for _, want := range []string{`{"status":"PENDING"}`, `{"status":"FINISHED"}`} {
+		result, err := replay.RunZaia(t.Context(), "process", "p1")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(result.Stdout) != want {
+			t.Errorf("got %q, want %q", result.Stdout, want)
+		}
+	}
+	if replay.Remaining() != 0 {
+		t.Errorf("got %d remaining, want 0", replay.Remaining())
+	}
+	if _, err := replay.RunZaia(t.Context(), "process", "p1"); err == nil {
+		t.Error("expected error after cassette end")
+	}
+}
+
+func TestReplayExecutor_InOrderMismatch(t *testing.T) {
+	entries := []CassetteEntry{{Binary: "zaia", Args: []string{"discover"}}}
+	replay := NewReplayExecutor(entries, ReplayInOrder)
+
+	_, err := replay.RunZaia(t.Context(), "logs", "--service", "api")
+	if err == nil || !strings.Contains(err.Error(), `want "zaia discover"`) {
+		t.Errorf("got error %v, want mismatch error", err)
+	}
+}
+
+func TestReplayExecutor_ByKey(t *testing.T) {
+	entries := []CassetteEntry{
+		{Binary: "zaia", Args: []string{"process", "p1"}, Stdout: "first"},
+		{Binary: "zaia", Args: []string{"discover"}, Stdout: "discover"},
+		{Binary: "zaia", Args: []string{"process", "p1"}, Stdout: "second"},
+		{Binary: "zcli", Args: []string{"push"}, Error: "exec: \"zcli\": executable file not found"},
+	}
+	replay := NewReplayExecutor(entries, ReplayByKey)
+
+	for _, want := range []string{"discover", "first", "second", "second"} {
+		args := []string{"process", "p1"}
+		if want == "discover" {
+			args = []string{"discover"}
+		}
+		result, err := replay.RunZaia(t.Context(), args...)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(result.Stdout) != want {
+			t.Errorf("got %q, want %q", result.Stdout, want)
+		}
+	}
+
+	if _, err := replay.RunZcli(t.Context(), "push"); err == nil {
+		t.Error("expected recorded error to be replayed")
+	}
+	if _, err := replay.RunZaia(t.Context(), "events"); err == nil {
+		t.Error("expected error for unrecorded call")
+	}
+}
+
+func TestReadCassette_InvalidLine(t *testing.T) {
+	_, err := ReadCassette(strings.NewReader("{\"binary\":\"zaia\"}\nnot json\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("got error %v, want line 2 parse error", err)
+	}
+}
+
+func TestRecordingExecutor_RedactsSecrets(t *testing.T) {
+	importYAML := "services:\n  - hostname: db\n    envSecrets:\n      S: s3cr3t-value\n"
+	mock := NewMockExecutor().
+		WithZaiaResponse("env set", ErrorResult("INVALID_ARGS", "bad value hunter2-secret", "", 2)).
+		WithZaiaResponse("import", AsyncResult(`[{"processId":"p1"}]`)).
+		WithZaiaResponse("discover", SyncResult(`{"services":[{"hostname":"db","containers":3,"envs":{"DB_PASSWORD":"hunter2-secret","PORT":"5432"}}]}`)).
+		WithZaiaResponse("env get", SyncResult(`{"envs":[{"key":"PORT","value":"5432"}]}`))
+
+	var buf bytes.Buffer
+	rec := NewRecordingExecutor(mock, &buf)
+	_, _ = rec.RunZaia(t.Context(), "env", "set", "--service", "api", "DB_PASSWORD=hunter2-secret", "PORT=5432")
+	_, _ = rec.RunZaia(t.Context(), "import", "--content", importYAML)
+	_, _ = rec.RunZaia(t.Context(), "discover", "--include-envs")
+	_, _ = rec.RunZaia(t.Context(), "env", "get", "--service", "db")
+
+	cassette := buf.String()
+	for _, secret := range []string{"hunter2-secret", "s3cr3t-value", "5432"} {
+		if strings.Contains(cassette, secret) {
+			t.Errorf("cassette contains %q:\n%s", secret, cassette)
+		}
+	}
+	entries, err := ReadCassette(&buf)
+	if err != nil {
+		t.Fatalf("ReadCassette: %v", err)
+	}
+	if got := strings.Join(entries[0].Args, " "); got != "env set --service api DB_PASSWORD=<redacted> PORT=<redacted>" {
+		t.Errorf("env set args = %q", got)
+	}
+	if !strings.Contains(entries[2].Stdout, `"containers":3`) || !strings.Contains(entries[3].Stdout, `"key":"PORT"`) {
+		t.Errorf("redaction dropped non-secret output: %s / %s", entries[2].Stdout, entries[3].Stdout)
+	}
+
+	// Replay redacts incoming calls the same way, so they still match.
+	replay := NewReplayExecutor(entries, ReplayByKey)
+	if _, err := replay.RunZaia(t.Context(), "import", "--content", importYAML); err != nil {
+		t.Errorf("replay import: %v", err)
+	}
+	if _, err := replay.RunZaia(t.Context(), "import", "--content", "services: []\n"); err == nil {
+		t.Error("replay matched an import of different content")
+	}
+}
+
+func TestReplayExecutor_ErrorKinds(t *testing.T) {
+	mock := NewMockExecutor().
+		WithZaiaError("logs", &TimeoutError{Command: "zaia logs", Timeout: time.Minute, Elapsed: time.Minute}).
+		WithZaiaError("discover", fmt.Errorf("run zaia: %w", context.Canceled)).
+		WithZaiaError("events", &exec.Error{Name: "zaia", Err: exec.ErrNotFound})
+
+	var buf bytes.Buffer
+	rec := NewRecordingExecutor(mock, &buf)
+	for _, cmd := range []string{"logs", "discover", "events"} {
+		_, _ = rec.RunZaia(t.Context(), cmd)
+	}
+	entries, err := ReadCassette(&buf)
+	if err != nil {
+		t.Fatalf("ReadCassette: %v", err)
+	}
+	replay := NewReplayExecutor(entries, ReplayInOrder)
+
+	_, err = replay.RunZaia(t.Context(), "logs")
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) || timeoutErr.Timeout != time.Minute || timeoutErr.Command != "zaia logs" {
+		t.Errorf("logs: got %#v, want *TimeoutError with a 1m limit", err)
+	}
+	if _, err := replay.RunZaia(t.Context(), "discover"); !errors.Is(err, context.Canceled) || err.Error() != "run zaia: context canceled" {
+		t.Errorf("discover: got %v, want context.Canceled", err)
+	}
+	if _, err := replay.RunZaia(t.Context(), "events"); !errors.Is(err, exec.ErrNotFound) {
+		t.Errorf("events: got %v, want exec.ErrNotFound", err)
+	}
+}
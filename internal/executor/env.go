@@ -0,0 +1,192 @@
+package executor
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// TokenEnvVar is the variable through which a configured Zerops token is
+// passed to zaia and zcli.
+const TokenEnvVar = "ZEROPS_TOKEN"
+
+// Redacted replaces secret values in CLI output.
+const Redacted = "<redacted>"
+
+// minSecretLen keeps short values of secret-looking host variables (e.g.
+// "1", "true") from being redacted everywhere in CLI output.
+const minSecretLen = 8
+
+// secretName matches host variable names whose values are redacted from
+// CLI output when passed through.
+var secretName = regexp.MustCompile(`(?i)(token|secret|password|passwd|apikey|api_key|credential)`)
+
+// IsSecretName reports whether values of a variable, flag or argument
+// called name are always redacted.
+func IsSecretName(name string) bool {
+	return secretName.MatchString(name)
+}
+
+// RedactVariable drops the value of a KEY=value assignment; other
+// strings are returned unchanged.
+func RedactVariable(kv string) string {
+	if key, _, found := strings.Cut(kv, "="); found {
+		return key + "=" + Redacted
+	}
+	return kv
+}
+
+// SummarizeContent replaces content that may hold secrets (e.g. import
+// YAML with envSecrets) by its size and SHA-256 prefix, which still tells
+// different contents apart.
+func SummarizeContent(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return fmt.Sprintf("<%d bytes, sha256:%s>", len(s), hex.EncodeToString(sum[:6]))
+}
+
+// EnvPolicy controls which variables of the MCP host environment reach
+// zaia/zcli subprocesses. Patterns are exact names or prefixes ending in
+// "*" (e.g. "LC_*"); on Windows they match case-insensitively.
+type EnvPolicy struct {
+	Allow  []string          // passed through (nil: DefaultEnvAllow())
+	Deny   []string          // removed even if allowed
+	Inject map[string]string // set explicitly, overriding host values; values are redacted from output
+}
+
+// DefaultEnvAllow returns the variables passed through by default: what
+// the CLIs need to locate home/config dirs, temp space, locale, proxies
+// and CA bundles, plus ZEROPS_*, ZCLI_* and ZAIA_*.
+func DefaultEnvAllow() []string {
+	return []string{
+		"PATH", "HOME", "USER", "LOGNAME", "SHELL", "TMPDIR", "TMP", "TEMP", "TZ", "TERM",
+		"LANG", "LC_*", "XDG_*",
+		"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "ALL_PROXY",
+		"http_proxy", "https_proxy", "no_proxy", "all_proxy",
+		"SSL_CERT_FILE", "SSL_CERT_DIR",
+		// Windows
+		"SYSTEMROOT", "WINDIR", "COMSPEC", "PATHEXT", "APPDATA", "LOCALAPPDATA",
+		"USERPROFILE", "HOMEDRIVE", "HOMEPATH", "PROGRAMDATA", "PROGRAMFILES",
+		"ZEROPS_*", "ZCLI_*", "ZAIA_*",
+	}
+}
+
+// DefaultEnvPolicy returns the policy used by NewCLIExecutor.
+func DefaultEnvPolicy() EnvPolicy {
+	return EnvPolicy{Allow: DefaultEnvAllow()}
+}
+
+// Apply filters environ ("KEY=value" pairs) through the policy, sets path
+// as PATH (if non-empty) and adds injected variables. It also returns the
+// values to redact from output: injected values and values of passed-through
+// variables with secret-looking names.
+func (p EnvPolicy) Apply(environ []string, path string) (env, secrets []string) {
+	allow := p.Allow
+	if allow == nil {
+		allow = DefaultEnvAllow()
+	}
+	env = make([]string, 0, len(environ)+len(p.Inject)+1)
+	for _, kv := range environ {
+		name, value, _ := strings.Cut(kv, "=")
+		if name == "" || !matchesAny(name, allow) || matchesAny(name, p.Deny) || p.injects(name) {
+			continue
+		}
+		if path != "" && envNameEqual(name, "PATH") {
+			continue
+		}
+		env = append(env, kv)
+		if secretName.MatchString(name) && len(value) >= minSecretLen {
+			secrets = append(secrets, value)
+		}
+	}
+	if path != "" {
+		env = append(env, "PATH="+path)
+	}
+	names := make([]string, 0, len(p.Inject))
+	for name := range p.Inject {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		value := p.Inject[name]
+		env = append(env, name+"="+value)
+		if value != "" {
+			secrets = append(secrets, value)
+		}
+	}
+	return env, secrets
+}
+
+func (p EnvPolicy) injects(name string) bool {
+	for injected := range p.Inject {
+		if envNameEqual(name, injected) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAny(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if len(name) >= len(prefix) && envNameEqual(name[:len(prefix)], prefix) {
+				return true
+			}
+		} else if envNameEqual(name, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+func envNameEqual(a, b string) bool {
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+// redactor replaces secret values in CLI output.
+type redactor struct {
+	r *strings.Replacer
+}
+
+func newRedactor(secrets []string) *redactor {
+	if len(secrets) == 0 {
+		return nil
+	}
+	// Longest first so a secret containing another is replaced whole.
+	sorted := slices.Clone(secrets)
+	slices.SortFunc(sorted, func(a, b string) int { return len(b) - len(a) })
+	pairs := make([]string, 0, 2*len(sorted))
+	for _, s := range sorted {
+		pairs = append(pairs, s, Redacted)
+	}
+	return &redactor{r: strings.NewReplacer(pairs...)}
+}
+
+func (r *redactor) bytes(b []byte) []byte {
+	if r == nil || len(b) == 0 {
+		return b
+	}
+	return []byte(r.r.Replace(string(b)))
+}
+
+// file redacts a spill file in place.
+func (r *redactor) file(o *Overflow) {
+	if r == nil || o == nil {
+		return
+	}
+	data, err := os.ReadFile(o.Path)
+	if err != nil {
+		return
+	}
+	if redacted := r.bytes(data); !bytes.Equal(redacted, data) {
+		_ = os.WriteFile(o.Path, redacted, 0o600)
+	}
+}
@@ -0,0 +1,87 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestEnvPolicy_Apply(t *testing.T) {
+	environ := []string{
+		"PATH=/usr/bin",
+		"HOME=/home/u",
+		"LC_ALL=C",
+		"AWS_SECRET_ACCESS_KEY=aws-secret-value",
+		"ZEROPS_TOKEN=host-token-value",
+		"ZCLI_API_TOKEN=zcli-token-value",
+		"ZAIA_DEBUG=1",
+	}
+	p := EnvPolicy{
+		Deny:   []string{"ZAIA_DEBUG"},
+		Inject: map[string]string{"ZEROPS_TOKEN": "injected-token"},
+	}
+	env, secrets := p.Apply(environ, "/resolved/bin")
+
+	want := []string{
+		"HOME=/home/u",
+		"LC_ALL=C",
+		"ZCLI_API_TOKEN=zcli-token-value",
+		"PATH=/resolved/bin",
+		"ZEROPS_TOKEN=injected-token",
+	}
+	if !slices.Equal(env, want) {
+		t.Errorf("env:\n got %v\nwant %v", env, want)
+	}
+	if want := []string{"zcli-token-value", "injected-token"}; !slices.Equal(secrets, want) {
+		t.Errorf("secrets: got %v, want %v", secrets, want)
+	}
+}
+
+func TestEnvPolicy_CustomAllow(t *testing.T) {
+	p := EnvPolicy{Allow: []string{"*"}, Deny: []string{"AWS_*"}}
+	env, _ := p.Apply([]string{"FOO=1", "AWS_REGION=eu", "PATH=/bin"}, "")
+	if want := []string{"FOO=1", "PATH=/bin"}; !slices.Equal(env, want) {
+		t.Errorf("got %v, want %v", env, want)
+	}
+}
+
+func TestRedactor(t *testing.T) {
+	r := newRedactor([]string{"abc", "abcdef"})
+	if got := string(r.bytes([]byte("x abcdef abc"))); got != "x <redacted> <redacted>" {
+		t.Errorf("got %q", got)
+	}
+	var none *redactor
+	if got := string(none.bytes([]byte("abc"))); got != "abc" {
+		t.Errorf("nil redactor changed output: %q", got)
+	}
+
+	path := filepath.Join(t.TempDir(), "out")
+	if err := os.WriteFile(path, []byte("token=abcdef\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	r.file(&Overflow{Path: path})
+	data, _ := os.ReadFile(path)
+	if string(data) != "token=<redacted>\n" {
+		t.Errorf("spill file not redacted: %q", data)
+	}
+}
+
+func TestCLIExecutor_EnvFilteredAndRedacted(t *testing.T) {
+	t.Setenv("UNRELATED_EDITOR_SECRET", "should-not-leak")
+	exec := NewCLIExecutorWithEnv("sh", "sh", EnvPolicy{
+		Inject: map[string]string{TokenEnvVar: "zerops-test-token"},
+	})
+
+	result, err := exec.RunZaia(t.Context(), "-c", `echo "$UNRELATED_EDITOR_SECRET|$ZEROPS_TOKEN"; echo "$ZEROPS_TOKEN" >&2`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.TrimSpace(string(result.Stdout)); got != "|"+Redacted {
+		t.Errorf("stdout: got %q", got)
+	}
+	if got := strings.TrimSpace(string(result.Stderr)); got != Redacted {
+		t.Errorf("stderr: got %q", got)
+	}
+}
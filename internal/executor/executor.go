@@ -1,12 +1,15 @@
 package executor
 
 import (
-	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
+	"sync"
+	"time"
 )
 
 // Result holds the output of a CLI subprocess execution.
@@ -14,6 +17,17 @@ type Result struct {
 	Stdout   []byte
 	Stderr   []byte
 	ExitCode int
+
+	// StdoutOverflow/StderrOverflow are set when the output exceeded the
+	// capture limit; Stdout/Stderr then hold only the leading bytes. Only
+	// stdout is spilled to a file: StderrOverflow has no Path.
+	StdoutOverflow *Overflow
+	StderrOverflow *Overflow
+
+	// Attempts is the number of runs made by RetryExecutor (0 when not retried).
+	Attempts int
+	// CachedAt is set when CachingExecutor served the result from cache.
+	CachedAt time.Time
 }
 
 // Executor defines how ZAIA-MCP calls CLI subprocesses.
@@ -27,29 +41,91 @@ type Executor interface {
 const (
 	defaultZaiaBinary = "zaia"
 	defaultZcliBinary = "zcli"
+
+	// DefaultTimeout applies to subcommands without an entry in Timeouts.
+	DefaultTimeout = 2 * time.Minute
+
+	// waitDelay bounds how long Wait blocks on output pipes after the
+	// process group has been killed.
+	waitDelay = 10 * time.Second
 )
 
+// DefaultTimeouts returns the default per-subcommand timeouts.
+// Keys are the first CLI argument (e.g. "discover" for `zaia discover`,
+// "push" for `zcli push`).
+func DefaultTimeouts() map[string]time.Duration {
+	return map[string]time.Duration{
+		"discover":  30 * time.Second,
+		"search":    30 * time.Second,
+		"validate":  30 * time.Second,
+		"process":   30 * time.Second,
+		"cancel":    30 * time.Second,
+		"logs":      60 * time.Second,
+		"events":    60 * time.Second,
+		"env":       60 * time.Second,
+		"start":     60 * time.Second,
+		"stop":      60 * time.Second,
+		"restart":   60 * time.Second,
+		"scale":     60 * time.Second,
+		"delete":    60 * time.Second,
+		"subdomain": 60 * time.Second,
+		"import":    2 * time.Minute,
+		"push":      15 * time.Minute,
+	}
+}
+
+// TimeoutError is returned when a CLI subprocess exceeds its timeout.
+// The whole process group has been killed by the time it is returned.
+type TimeoutError struct {
+	Command string        // command line with secret arguments redacted, e.g. "zaia logs --service api"
+	Timeout time.Duration // configured timeout
+	Elapsed time.Duration // wall time until the process was killed
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s timed out after %s (timeout %s)", e.Command, e.Elapsed.Round(time.Millisecond), e.Timeout)
+}
+
 // CLIExecutor implements Executor using exec.CommandContext.
+// Each subprocess runs in its own process group, which is killed as a whole
+// on timeout or context cancellation.
 type CLIExecutor struct {
-	ZaiaBinary string   // path to zaia binary (default: "zaia")
-	ZcliBinary string   // path to zcli binary (default: "zcli")
-	env        []string // process environment with resolved PATH
+	ZaiaBinary     string                   // path to zaia binary (default: "zaia")
+	ZcliBinary     string                   // path to zcli binary (default: "zcli")
+	Timeouts       map[string]time.Duration // per-subcommand timeouts (default: DefaultTimeouts())
+	DefaultTimeout time.Duration            // fallback timeout (default: DefaultTimeout)
+	MaxStdout      int                      // in-memory stdout cap in bytes (default: DefaultMaxStdout)
+	MaxStderr      int                      // in-memory stderr cap in bytes (default: DefaultMaxStderr)
+	env            []string                 // filtered process environment with resolved PATH
+	redact         *redactor                // strips secret env values from output
 }
 
 // NewCLIExecutor creates a new CLIExecutor with the given binary paths.
-// Empty strings use defaults ("zaia" and "zcli").
+// Empty strings use defaults ("zaia" and "zcli"). Subprocesses get the
+// host environment filtered by DefaultEnvPolicy.
 func NewCLIExecutor(zaiaBinary, zcliBinary string) *CLIExecutor {
+	return NewCLIExecutorWithEnv(zaiaBinary, zcliBinary, DefaultEnvPolicy())
+}
+
+// NewCLIExecutorWithEnv is NewCLIExecutor with an explicit environment policy.
+// Values of injected and secret-looking variables are redacted from output.
+func NewCLIExecutorWithEnv(zaiaBinary, zcliBinary string, policy EnvPolicy) *CLIExecutor {
 	if zaiaBinary == "" {
 		zaiaBinary = defaultZaiaBinary
 	}
 	if zcliBinary == "" {
 		zcliBinary = defaultZcliBinary
 	}
-	env := buildEnvWithResolvedPATH()
+	env, secrets := policy.Apply(os.Environ(), shellPATH())
 	return &CLIExecutor{
-		ZaiaBinary: zaiaBinary,
-		ZcliBinary: zcliBinary,
-		env:        env,
+		ZaiaBinary:     zaiaBinary,
+		ZcliBinary:     zcliBinary,
+		Timeouts:       DefaultTimeouts(),
+		DefaultTimeout: DefaultTimeout,
+		MaxStdout:      DefaultMaxStdout,
+		MaxStderr:      DefaultMaxStderr,
+		env:            env,
+		redact:         newRedactor(secrets),
 	}
 }
 
@@ -63,26 +139,74 @@ func (e *CLIExecutor) RunZcli(ctx context.Context, args ...string) (*Result, err
 	return e.run(ctx, e.ZcliBinary, args...)
 }
 
+// timeoutFor returns the timeout for the given subcommand arguments.
+func (e *CLIExecutor) timeoutFor(args []string) time.Duration {
+	if len(args) > 0 {
+		if d, ok := e.Timeouts[args[0]]; ok && d > 0 {
+			return d
+		}
+	}
+	if e.DefaultTimeout > 0 {
+		return e.DefaultTimeout
+	}
+	return DefaultTimeout
+}
+
 func (e *CLIExecutor) run(ctx context.Context, binary string, args ...string) (*Result, error) {
-	cmd := exec.CommandContext(ctx, binary, args...)
-	cmd.Env = e.env
+	if name := ProfileFrom(ctx); name != "" {
+		// Reached only without a ProfileExecutor in the chain.
+		return nil, &UnknownProfileError{Name: name}
+	}
+	timeout := e.timeoutFor(args)
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	cmd := exec.CommandContext(runCtx, binary, args...)
+	cmd.Env = e.env
+	cmd.WaitDelay = waitDelay
+	setProcessGroup(cmd)
+
+	stdout := newCappedWriter(orDefault(e.MaxStdout, DefaultMaxStdout), spillPattern)
+	stderr := newCappedWriter(orDefault(e.MaxStderr, DefaultMaxStderr), "")
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	var lines []*lineWriter
+	if fn := lineHandlerFrom(ctx); fn != nil {
+		sink := &lineSink{fn: fn, redact: e.redact}
+		lines = []*lineWriter{sink.writer(Stdout), sink.writer(Stderr)}
+		cmd.Stdout = io.MultiWriter(stdout, lines[0])
+		cmd.Stderr = io.MultiWriter(stderr, lines[1])
+	}
 
+	start := time.Now()
 	err := cmd.Run()
+	for _, w := range lines {
+		w.flush()
+	}
 
-	result := &Result{
-		Stdout: stdout.Bytes(),
-		Stderr: stderr.Bytes(),
+	result := &Result{}
+	result.Stdout, result.StdoutOverflow = stdout.finish()
+	result.Stderr, result.StderrOverflow = stderr.finish()
+	result.Stdout = e.redact.bytes(result.Stdout)
+	result.Stderr = e.redact.bytes(result.Stderr)
+	e.redact.file(result.StdoutOverflow)
+	if result.StdoutOverflow != nil {
+		RemoveStaleSpills(SpillTTL)
 	}
 
 	if err != nil {
-		// Check context cancellation first
+		// Check context cancellation first: caller cancellation wins over our timeout.
 		if ctx.Err() != nil {
 			return result, ctx.Err()
 		}
+		if errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+			return result, &TimeoutError{
+				Command: strings.Join(append([]string{binary}, redactArgs(args)...), " "),
+				Timeout: timeout,
+				Elapsed: time.Since(start),
+			}
+		}
 		var exitErr *exec.ExitError
 		if errors.As(err, &exitErr) {
 			result.ExitCode = exitErr.ExitCode()
@@ -96,6 +220,16 @@ func (e *CLIExecutor) run(ctx context.Context, binary string, args ...string) (*
 	return result, nil
 }
 
+func orDefault(v, def int) int {
+	if v > 0 {
+		return v
+	}
+	return def
+}
+
+// shellPATH resolves the login shell PATH once per process.
+var shellPATH = sync.OnceValue(resolveShellPATH)
+
 // resolveShellPATH runs the user's login shell to get the full PATH,
 // including paths added by tools like nvm, homebrew, etc. that are
 // configured in shell profiles but not available to MCP servers.
@@ -114,24 +248,3 @@ func resolveShellPATH() string {
 	}
 	return resolved
 }
-
-// buildEnvWithResolvedPATH returns a copy of the current process environment
-// with PATH replaced by the value from the user's login shell.
-func buildEnvWithResolvedPATH() []string {
-	resolvedPATH := resolveShellPATH()
-	env := os.Environ()
-	result := make([]string, 0, len(env))
-	found := false
-	for _, e := range env {
-		if strings.HasPrefix(e, "PATH=") {
-			result = append(result, "PATH="+resolvedPATH)
-			found = true
-		} else {
-			result = append(result, e)
-		}
-	}
-	if !found {
-		result = append(result, "PATH="+resolvedPATH)
-	}
-	return result
-}
@@ -2,6 +2,9 @@ package executor
 
 import (
 	"context"
+	"errors"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -51,6 +54,90 @@ func TestCLIExecutor_ContextCancellation(t *testing.T) {
 	}
 }
 
+func TestCLIExecutor_Timeout(t *testing.T) {
+	exec := NewCLIExecutor("sleep", "sleep")
+	exec.Timeouts = map[string]time.Duration{"10": 50 * time.Millisecond}
+
+	_, err := exec.RunZaia(t.Context(), "10")
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("got error %v, want *TimeoutError", err)
+	}
+	if timeoutErr.Command != "sleep 10" {
+		t.Errorf("got command %q, want %q", timeoutErr.Command, "sleep 10")
+	}
+	if timeoutErr.Timeout != 50*time.Millisecond {
+		t.Errorf("got timeout %v, want 50ms", timeoutErr.Timeout)
+	}
+	if timeoutErr.Elapsed < 50*time.Millisecond {
+		t.Errorf("elapsed %v shorter than timeout", timeoutErr.Elapsed)
+	}
+}
+
+func TestCLIExecutor_TimeoutRedactsCommand(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "zaia")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nsleep 10\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	exec := NewCLIExecutor(script, script)
+	exec.Timeouts = map[string]time.Duration{"env": 50 * time.Millisecond}
+
+	_, err := exec.RunZaia(t.Context(), "env", "set", "--service", "api", "API_KEY=hunter2")
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("got error %v, want *TimeoutError", err)
+	}
+	if strings.Contains(err.Error(), "hunter2") {
+		t.Errorf("timeout error leaks the value: %v", err)
+	}
+	if want := script + " env set --service api API_KEY=" + Redacted; timeoutErr.Command != want {
+		t.Errorf("got command %q, want %q", timeoutErr.Command, want)
+	}
+}
+
+func TestCLIExecutor_DefaultTimeout(t *testing.T) {
+	exec := NewCLIExecutor("sleep", "sleep")
+	exec.Timeouts = nil
+	exec.DefaultTimeout = 50 * time.Millisecond
+
+	_, err := exec.RunZcli(t.Context(), "10")
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("got error %v, want *TimeoutError", err)
+	}
+}
+
+func TestCLIExecutor_CancellationIsNotTimeout(t *testing.T) {
+	exec := NewCLIExecutor("sleep", "sleep")
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := exec.RunZaia(ctx, "10")
+	var timeoutErr *TimeoutError
+	if errors.As(err, &timeoutErr) {
+		t.Fatal("caller cancellation should not be reported as TimeoutError")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestCLIExecutor_TimeoutFor(t *testing.T) {
+	exec := NewCLIExecutor("", "")
+	if got := exec.timeoutFor([]string{"push"}); got != 15*time.Minute {
+		t.Errorf("push: got %v, want 15m", got)
+	}
+	if got := exec.timeoutFor([]string{"discover", "--service", "api"}); got != 30*time.Second {
+		t.Errorf("discover: got %v, want 30s", got)
+	}
+	if got := exec.timeoutFor([]string{"unknown"}); got != DefaultTimeout {
+		t.Errorf("unknown: got %v, want %v", got, DefaultTimeout)
+	}
+	if got := exec.timeoutFor(nil); got != DefaultTimeout {
+		t.Errorf("no args: got %v, want %v", got, DefaultTimeout)
+	}
+}
+
 func TestCLIExecutor_Stderr(t *testing.T) {
 	exec := NewCLIExecutor("sh", "sh")
 	result, err := exec.RunZaia(t.Context(), "-c", "echo stderr_msg >&2")
@@ -0,0 +1,198 @@
+package executor
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultMaxConcurrent is the default cap on concurrently running CLI subprocesses.
+const DefaultMaxConcurrent = 4
+
+// mutatingCommands lists zaia subcommands that change project state.
+// `env` is mutating only for set/delete (see IsMutating).
+var mutatingCommands = map[string]bool{
+	"start":     true,
+	"stop":      true,
+	"restart":   true,
+	"scale":     true,
+	"delete":    true,
+	"import":    true,
+	"subdomain": true,
+}
+
+// IsMutating reports whether zaia args describe a state-changing command.
+// Dry-run imports are read-only.
+func IsMutating(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	switch args[0] {
+	case "env":
+		return len(args) > 1 && (args[1] == "set" || args[1] == "delete")
+	case "import":
+		return !hasFlag(args, "--dry-run")
+	default:
+		return mutatingCommands[args[0]]
+	}
+}
+
+// ServiceHostname returns the value of the --service flag, or "" when the
+// command is project-scoped.
+func ServiceHostname(args []string) string {
+	for i := 0; i < len(args)-1; i++ {
+		if args[i] == "--service" {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+func hasFlag(args []string, flag string) bool {
+	for _, a := range args {
+		if a == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// LimitedExecutor wraps an Executor with a global cap on concurrent
+// subprocesses and per-service serialization of mutating zaia commands.
+// Read-only commands only compete for global slots; mutating commands on
+// the same service hostname run one at a time. A project-scoped mutation
+// (import, env --project) conflicts with every other mutation: it waits
+// for running ones and holds back new ones until it is done.
+//
+// Only the CLI call is serialized. Mutating commands return once zaia has
+// submitted the async processes, so the lock is released while those
+// processes still run on Zerops; callers that must not overlap them wait
+// for the processes (waitForCompletion, zerops_wait) before the next call.
+type LimitedExecutor struct {
+	next  Executor
+	slots chan struct{}
+
+	mu             sync.Mutex
+	services       map[string]bool // service hostnames with a running mutation
+	project        bool            // a project-scoped mutation is running
+	projectWaiting int             // queued project-scoped mutations
+	changed        chan struct{}   // closed and replaced when a mutation ends
+}
+
+// NewLimitedExecutor wraps next with at most maxConcurrent running calls.
+// maxConcurrent <= 0 uses DefaultMaxConcurrent.
+func NewLimitedExecutor(next Executor, maxConcurrent int) *LimitedExecutor {
+	if maxConcurrent <= 0 {
+		maxConcurrent = DefaultMaxConcurrent
+	}
+	return &LimitedExecutor{
+		next:     next,
+		slots:    make(chan struct{}, maxConcurrent),
+		services: make(map[string]bool),
+		changed:  make(chan struct{}),
+	}
+}
+
+// Unwrap returns the wrapped executor.
+func (l *LimitedExecutor) Unwrap() Executor {
+	return l.next
+}
+
+// RunZaia implements Executor.
+func (l *LimitedExecutor) RunZaia(ctx context.Context, args ...string) (*Result, error) {
+	if IsMutating(args) {
+		release, err := l.lockService(ctx, ServiceHostname(args))
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+	}
+	if err := l.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer l.release()
+	return l.next.RunZaia(ctx, args...)
+}
+
+// RunZcli implements Executor.
+func (l *LimitedExecutor) RunZcli(ctx context.Context, args ...string) (*Result, error) {
+	if err := l.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer l.release()
+	return l.next.RunZcli(ctx, args...)
+}
+
+func (l *LimitedExecutor) acquire(ctx context.Context) error {
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *LimitedExecutor) release() {
+	<-l.slots
+}
+
+// lockService blocks until a mutation of hostname ("" for the project)
+// may run. The returned func releases the lock.
+func (l *LimitedExecutor) lockService(ctx context.Context, hostname string) (func(), error) {
+	l.mu.Lock()
+	if hostname == "" {
+		l.projectWaiting++
+	}
+	for !l.mayRun(hostname) {
+		changed := l.changed
+		l.mu.Unlock()
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			l.mu.Lock()
+			if hostname == "" {
+				l.projectWaiting--
+				l.notify() // service mutations held back by it may run
+			}
+			l.mu.Unlock()
+			return nil, ctx.Err()
+		}
+		l.mu.Lock()
+	}
+	if hostname == "" {
+		l.projectWaiting--
+		l.project = true
+	} else {
+		l.services[hostname] = true
+	}
+	l.mu.Unlock()
+
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if hostname == "" {
+			l.project = false
+		} else {
+			delete(l.services, hostname)
+		}
+		l.notify()
+	}, nil
+}
+
+// mayRun reports whether a mutation of hostname can start. Queued project
+// mutations go first so a stream of service mutations cannot starve them.
+// Caller must hold l.mu.
+func (l *LimitedExecutor) mayRun(hostname string) bool {
+	if l.project {
+		return false
+	}
+	if hostname == "" {
+		return len(l.services) == 0
+	}
+	return l.projectWaiting == 0 && !l.services[hostname]
+}
+
+// notify wakes all waiting mutations. Caller must hold l.mu.
+func (l *LimitedExecutor) notify() {
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
@@ -0,0 +1,247 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// gateExecutor blocks every call until release is closed and tracks
+// the peak number of concurrent calls.
+type gateExecutor struct {
+	release chan struct{}
+	started chan string
+	running atomic.Int32
+	peak    atomic.Int32
+}
+
+func newGateExecutor() *gateExecutor {
+	return &gateExecutor{
+		release: make(chan struct{}),
+		started: make(chan string, 100),
+	}
+}
+
+func (g *gateExecutor) run(ctx context.Context, args []string) (*Result, error) {
+	n := g.running.Add(1)
+	defer g.running.Add(-1)
+	for {
+		p := g.peak.Load()
+		if n <= p || g.peak.CompareAndSwap(p, n) {
+			break
+		}
+	}
+	key := ""
+	if len(args) > 0 {
+		key = args[len(args)-1]
+	}
+	g.started <- key
+	select {
+	case <-g.release:
+		return &Result{}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (g *gateExecutor) RunZaia(ctx context.Context, args ...string) (*Result, error) {
+	return g.run(ctx, args)
+}
+
+func (g *gateExecutor) RunZcli(ctx context.Context, args ...string) (*Result, error) {
+	return g.run(ctx, args)
+}
+
+func TestIsMutating(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"discover"}, false},
+		{[]string{"logs", "--service", "api"}, false},
+		{[]string{"start", "--service", "api"}, true},
+		{[]string{"scale", "--service", "api", "--min-cpu", "1"}, true},
+		{[]string{"env", "get", "--service", "api"}, false},
+		{[]string{"env", "set", "--service", "api", "A=1"}, true},
+		{[]string{"env", "delete", "--project", "A"}, true},
+		{[]string{"import", "--content", "x"}, true},
+		{[]string{"import", "--content", "x", "--dry-run"}, false},
+		{[]string{"delete", "--service", "api", "--confirm"}, true},
+		{[]string{"subdomain", "enable", "--service", "api"}, true},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := IsMutating(tt.args); got != tt.want {
+			t.Errorf("IsMutating(%v) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestServiceHostname(t *testing.T) {
+	if got := ServiceHostname([]string{"env", "set", "--service", "api", "A=1"}); got != "api" {
+		t.Errorf("got %q, want %q", got, "api")
+	}
+	if got := ServiceHostname([]string{"import", "--content", "x"}); got != "" {
+		t.Errorf("got %q, want empty", got)
+	}
+	if got := ServiceHostname([]string{"logs", "--service"}); got != "" {
+		t.Errorf("dangling flag: got %q, want empty", got)
+	}
+}
+
+func TestLimitedExecutor_CapsConcurrency(t *testing.T) {
+	gate := newGateExecutor()
+	l := NewLimitedExecutor(gate, 2)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = l.RunZaia(t.Context(), "discover")
+		}()
+	}
+	<-gate.started
+	<-gate.started
+	select {
+	case <-gate.started:
+		t.Fatal("third call started while two slots are held")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(gate.release)
+	wg.Wait()
+
+	if peak := gate.peak.Load(); peak != 2 {
+		t.Errorf("peak concurrency %d, want 2", peak)
+	}
+}
+
+func TestLimitedExecutor_SerializesMutationsPerService(t *testing.T) {
+	gate := newGateExecutor()
+	l := NewLimitedExecutor(gate, 10)
+
+	var wg sync.WaitGroup
+	for _, args := range [][]string{
+		{"start", "--service", "api"},
+		{"restart", "--service", "api"},
+		{"start", "--service", "db"},
+		{"logs", "--service", "api"},
+	} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = l.RunZaia(t.Context(), args...)
+		}()
+	}
+
+	// One api mutation, the db mutation and the read-only logs call run;
+	// the second api mutation waits.
+	for i := 0; i < 3; i++ {
+		<-gate.started
+	}
+	select {
+	case <-gate.started:
+		t.Fatal("second mutation on api started concurrently")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(gate.release)
+	wg.Wait()
+}
+
+func TestLimitedExecutor_QueuedCallCancelled(t *testing.T) {
+	gate := newGateExecutor()
+	l := NewLimitedExecutor(gate, 1)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = l.RunZaia(t.Context(), "stop", "--service", "api")
+	}()
+	<-gate.started
+
+	ctx, cancel := context.WithTimeout(t.Context(), 20*time.Millisecond)
+	defer cancel()
+	_, err := l.RunZaia(ctx, "start", "--service", "api")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want DeadlineExceeded", err)
+	}
+
+	close(gate.release)
+	<-done
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.services) != 0 {
+		t.Errorf("service locks leaked: %d entries", len(l.services))
+	}
+}
+
+func TestLimitedExecutor_ProjectMutationConflictsWithServices(t *testing.T) {
+	gate := newGateExecutor()
+	l := NewLimitedExecutor(gate, 10)
+
+	var wg sync.WaitGroup
+	run := func(args ...string) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = l.RunZaia(t.Context(), args...)
+		}()
+	}
+	run("start", "--service", "api")
+	if got := <-gate.started; got != "api" {
+		t.Fatalf("started %q, want api", got)
+	}
+	run("import", "--content", "project")
+	time.Sleep(20 * time.Millisecond) // let the import queue up
+	run("start", "--service", "db")
+
+	select {
+	case got := <-gate.started:
+		t.Fatalf("%q started while a service mutation runs and an import waits", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	gate.release <- struct{}{} // api done: the import runs, db still waits
+	if got := <-gate.started; got != "project" {
+		t.Fatalf("started %q, want the import", got)
+	}
+	select {
+	case got := <-gate.started:
+		t.Fatalf("%q started during the project mutation", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(gate.release)
+	if got := <-gate.started; got != "db" {
+		t.Errorf("started %q, want db", got)
+	}
+	wg.Wait()
+}
+
+func TestLimitedExecutor_CancelledProjectMutationUnblocksServices(t *testing.T) {
+	gate := newGateExecutor()
+	l := NewLimitedExecutor(gate, 10)
+	defer close(gate.release)
+
+	go func() { _, _ = l.RunZaia(t.Context(), "start", "--service", "api") }()
+	<-gate.started
+
+	ctx, cancel := context.WithTimeout(t.Context(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := l.RunZaia(ctx, "env", "set", "--project", "A=1"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, want DeadlineExceeded", err)
+	}
+
+	go func() { _, _ = l.RunZaia(t.Context(), "start", "--service", "db") }()
+	select {
+	case got := <-gate.started:
+		if got != "db" {
+			t.Errorf("started %q, want db", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("service mutation still blocked by a cancelled project mutation")
+	}
+}
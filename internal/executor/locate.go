@@ -0,0 +1,110 @@
+package executor
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Binary describes where a configured CLI binary resolves on disk.
+type Binary struct {
+	Name       string // logical name: "zaia" or "zcli"
+	Configured string // configured binary name or path
+	Path       string // resolved absolute path ("" if not found)
+	Err        error  // lookup failure
+}
+
+// Locator is implemented by executors that run real binaries and can
+// report how they are resolved.
+type Locator interface {
+	// ResolvedPATH returns the PATH passed to subprocesses.
+	ResolvedPATH() string
+	// Binaries resolves the zaia and zcli binaries against ResolvedPATH.
+	Binaries() []Binary
+}
+
+// Unwrapper is implemented by executors that wrap another executor.
+type Unwrapper interface {
+	Unwrap() Executor
+}
+
+// Find walks the Unwrap chain starting at e and returns the first executor
+// that implements T.
+func Find[T any](e Executor) (T, bool) {
+	for e != nil {
+		if t, ok := e.(T); ok {
+			return t, true
+		}
+		u, ok := e.(Unwrapper)
+		if !ok {
+			break
+		}
+		e = u.Unwrap()
+	}
+	var zero T
+	return zero, false
+}
+
+// ResolvedPATH implements Locator.
+func (e *CLIExecutor) ResolvedPATH() string {
+	for _, kv := range e.env {
+		if v, ok := strings.CutPrefix(kv, "PATH="); ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// Binaries implements Locator.
+func (e *CLIExecutor) Binaries() []Binary {
+	path := e.ResolvedPATH()
+	out := make([]Binary, 0, 2)
+	for _, b := range []struct{ name, configured string }{
+		{defaultZaiaBinary, e.ZaiaBinary},
+		{defaultZcliBinary, e.ZcliBinary},
+	} {
+		resolved, err := lookPathIn(b.configured, path)
+		out = append(out, Binary{Name: b.name, Configured: b.configured, Path: resolved, Err: err})
+	}
+	return out
+}
+
+// errNotFound is returned by lookPathIn when no executable matches.
+var errNotFound = errors.New("executable file not found in resolved PATH")
+
+// lookPathIn is exec.LookPath against an explicit PATH value instead of
+// the MCP server's own environment.
+func lookPathIn(name, path string) (string, error) {
+	if strings.ContainsRune(name, filepath.Separator) || strings.Contains(name, "/") {
+		if isExecutable(name) {
+			return filepath.Abs(name)
+		}
+		return "", errNotFound
+	}
+	candidates := []string{name}
+	if runtime.GOOS == "windows" && filepath.Ext(name) == "" {
+		candidates = append(candidates, name+".exe")
+	}
+	for _, dir := range filepath.SplitList(path) {
+		if dir == "" {
+			continue
+		}
+		for _, c := range candidates {
+			p := filepath.Join(dir, c)
+			if isExecutable(p) {
+				return p, nil
+			}
+		}
+	}
+	return "", errNotFound
+}
+
+func isExecutable(p string) bool {
+	info, err := os.Stat(p)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	return runtime.GOOS == "windows" || info.Mode()&0o111 != 0
+}
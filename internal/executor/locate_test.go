@@ -0,0 +1,68 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestLookPathIn(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses unix permission bits")
+	}
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "zaia")
+	if err := os.WriteFile(bin, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "plain"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	path := "/nonexistent" + string(filepath.ListSeparator) + dir
+
+	got, err := lookPathIn("zaia", path)
+	if err != nil || got != bin {
+		t.Errorf("lookPathIn(zaia) = %q, %v; want %q", got, err, bin)
+	}
+	if _, err := lookPathIn("plain", path); err == nil {
+		t.Error("non-executable file should not resolve")
+	}
+	if _, err := lookPathIn("missing", path); err == nil {
+		t.Error("missing binary should not resolve")
+	}
+	if got, err := lookPathIn(bin, ""); err != nil || got != bin {
+		t.Errorf("absolute path: got %q, %v", got, err)
+	}
+}
+
+func TestFind_ThroughWrappers(t *testing.T) {
+	cli := NewCLIExecutor("sh", "nonexistent-binary-xyz")
+	wrapped := NewRecordingExecutor(NewLimitedExecutor(cli, 1), &nopWriter{})
+
+	loc, ok := Find[Locator](wrapped)
+	if !ok {
+		t.Fatal("Locator not found through wrappers")
+	}
+	if loc.ResolvedPATH() == "" {
+		t.Error("ResolvedPATH is empty")
+	}
+	bins := loc.Binaries()
+	if len(bins) != 2 {
+		t.Fatalf("got %d binaries, want 2", len(bins))
+	}
+	if bins[0].Name != "zaia" || bins[0].Err != nil || bins[0].Path == "" {
+		t.Errorf("zaia (sh): got %+v", bins[0])
+	}
+	if bins[1].Name != "zcli" || bins[1].Err == nil {
+		t.Errorf("zcli (missing): got %+v", bins[1])
+	}
+
+	if _, ok := Find[Locator](NewMockExecutor()); ok {
+		t.Error("MockExecutor should not be a Locator")
+	}
+}
+
+type nopWriter struct{}
+
+func (*nopWriter) Write(p []byte) (int, error) { return len(p), nil }
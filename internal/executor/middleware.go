@@ -0,0 +1,114 @@
+package executor
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Middleware wraps an Executor with cross-cutting behavior (logging,
+// timing, policies). Middlewares should implement Unwrapper so Find can
+// reach the executors they wrap; Intercept does this for you.
+type Middleware func(Executor) Executor
+
+// Chain wraps next with mws. The first middleware is the outermost: it sees
+// each call first and its result last.
+func Chain(next Executor, mws ...Middleware) Executor {
+	for i := len(mws) - 1; i >= 0; i-- {
+		if mws[i] != nil {
+			next = mws[i](next)
+		}
+	}
+	return next
+}
+
+// RunFunc runs one invocation of a CLI binary.
+type RunFunc func(ctx context.Context, args ...string) (*Result, error)
+
+// InterceptFunc handles one CLI invocation. binary is "zaia" or "zcli";
+// next runs the invocation on the wrapped executor.
+type InterceptFunc func(ctx context.Context, binary string, args []string, next RunFunc) (*Result, error)
+
+// Intercept returns a Middleware that routes every zaia and zcli
+// invocation through fn.
+func Intercept(fn InterceptFunc) Middleware {
+	return func(next Executor) Executor {
+		return &interceptExecutor{next: next, fn: fn}
+	}
+}
+
+type interceptExecutor struct {
+	next Executor
+	fn   InterceptFunc
+}
+
+// Unwrap returns the wrapped executor.
+func (e *interceptExecutor) Unwrap() Executor {
+	return e.next
+}
+
+// RunZaia implements Executor.
+func (e *interceptExecutor) RunZaia(ctx context.Context, args ...string) (*Result, error) {
+	return e.fn(ctx, defaultZaiaBinary, args, e.next.RunZaia)
+}
+
+// RunZcli implements Executor.
+func (e *interceptExecutor) RunZcli(ctx context.Context, args ...string) (*Result, error) {
+	return e.fn(ctx, defaultZcliBinary, args, e.next.RunZcli)
+}
+
+// CallInfo describes the tool call on whose behalf CLI commands run.
+type CallInfo struct {
+	Tool     string // MCP tool name, e.g. "zerops_manage"
+	Hostname string // serviceHostname argument ("" if none)
+	Mutating bool   // whether the call changes project state
+}
+
+type callInfoKey struct{}
+
+// WithCallInfo returns a context carrying info for middlewares.
+func WithCallInfo(ctx context.Context, info CallInfo) context.Context {
+	return context.WithValue(ctx, callInfoKey{}, info)
+}
+
+// CallInfoFrom returns the CallInfo attached by WithCallInfo.
+func CallInfoFrom(ctx context.Context) (CallInfo, bool) {
+	info, ok := ctx.Value(callInfoKey{}).(CallInfo)
+	return info, ok
+}
+
+// Logging returns a Middleware that logs each CLI invocation with its
+// subcommand, duration, exit code and the originating tool call: at debug
+// level, or warn level when the CLI fails or exits non-zero.
+// Only the subcommand is logged; arguments may carry secrets.
+func Logging(logger *slog.Logger) Middleware {
+	return Intercept(func(ctx context.Context, binary string, args []string, next RunFunc) (*Result, error) {
+		start := time.Now()
+		result, err := next(ctx, args...)
+		level := slog.LevelDebug
+		if err != nil || (result != nil && result.ExitCode != 0) {
+			level = slog.LevelWarn
+		}
+		if !logger.Enabled(ctx, level) {
+			return result, err
+		}
+		attrs := []any{"binary", binary, "durationMs", time.Since(start).Milliseconds()}
+		if len(args) > 0 {
+			attrs = append(attrs, "command", args[0])
+		}
+		if info, ok := CallInfoFrom(ctx); ok {
+			attrs = append(attrs, "tool", info.Tool, "hostname", info.Hostname, "mutating", info.Mutating)
+		}
+		if result != nil {
+			attrs = append(attrs, "exitCode", result.ExitCode)
+			if code := ErrorCode(result); code != "" {
+				attrs = append(attrs, "code", code)
+			}
+		}
+		if err != nil {
+			attrs = append(attrs, "error", err)
+		}
+		logger.Log(ctx, level, "cli call", attrs...)
+		return result, err
+	})
+}
@@ -0,0 +1,92 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestChain_Order(t *testing.T) {
+	var order []string
+	tag := func(name string) Middleware {
+		return Intercept(func(ctx context.Context, binary string, args []string, next RunFunc) (*Result, error) {
+			order = append(order, name+":"+binary)
+			return next(ctx, args...)
+		})
+	}
+	mock := NewMockExecutor().WithDefault(SyncResult(`{}`))
+	e := Chain(mock, tag("a"), nil, tag("b"))
+
+	_, _ = e.RunZaia(t.Context(), "discover")
+	_, _ = e.RunZcli(t.Context(), "push")
+
+	want := []string{"a:zaia", "b:zaia", "a:zcli", "b:zcli"}
+	if !slices.Equal(order, want) {
+		t.Errorf("got %v, want %v", order, want)
+	}
+	if got, ok := Find[*MockExecutor](e); !ok || got != mock {
+		t.Error("Find did not reach the wrapped mock through middlewares")
+	}
+}
+
+func TestChain_Empty(t *testing.T) {
+	mock := NewMockExecutor()
+	if Chain(mock) != mock {
+		t.Error("Chain without middlewares should return next unchanged")
+	}
+}
+
+func TestCallInfo(t *testing.T) {
+	if _, ok := CallInfoFrom(t.Context()); ok {
+		t.Error("expected no call info on a bare context")
+	}
+	want := CallInfo{Tool: "zerops_manage", Hostname: "api", Mutating: true}
+	got, ok := CallInfoFrom(WithCallInfo(t.Context(), want))
+	if !ok || got != want {
+		t.Errorf("got %+v, %v; want %+v", got, ok, want)
+	}
+}
+
+func TestLogging(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	mock := NewMockExecutor().WithDefault(SyncResult(`{}`))
+	e := Chain(mock, Logging(logger))
+
+	ctx := WithCallInfo(t.Context(), CallInfo{Tool: "zerops_env", Hostname: "api", Mutating: true})
+	_, _ = e.RunZaia(ctx, "env", "set", "--service", "api", "SECRET=hunter2")
+
+	out := buf.String()
+	for _, want := range []string{"command=env", "tool=zerops_env", "hostname=api", "mutating=true", "exitCode=0"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log missing %q: %s", want, out)
+		}
+	}
+	if strings.Contains(out, "hunter2") {
+		t.Errorf("log leaks arguments: %s", out)
+	}
+}
+
+func TestLogging_FailureAtWarn(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	mock := NewMockExecutor().
+		WithZaiaResponse("discover", SyncResult(`{}`)).
+		WithZaiaResponse("logs", ErrorResult("SERVICE_NOT_FOUND", "no such service", "", 1))
+	e := Chain(mock, Logging(logger))
+
+	_, _ = e.RunZaia(t.Context(), "discover")
+	if buf.Len() != 0 {
+		t.Errorf("successful call logged at warn: %s", buf.String())
+	}
+	_, _ = e.RunZaia(t.Context(), "logs", "--service", "nope")
+	out := buf.String()
+	for _, want := range []string{"level=WARN", "command=logs", "exitCode=1", "code=SERVICE_NOT_FOUND"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log missing %q: %s", want, out)
+		}
+	}
+}
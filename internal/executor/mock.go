@@ -3,18 +3,34 @@ package executor
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
+	"sync"
 )
 
-// MockExecutor returns configurable responses for testing.
+// MockExecutor returns configurable responses for testing. It is safe for
+// concurrent use.
+//
+// Calls are resolved in this order: expectations (ExpectZaia/ExpectZcli) in
+// the order they were added, errors and responses registered by exact
+// command, the longest registered response key that is a prefix of the
+// command (on an argument boundary), then the default response. The
+// resolved output is replayed to a WithLineHandler callback, stderr first.
 type MockExecutor struct {
+	mu sync.Mutex
 	// responses maps "binary arg1 arg2 ..." to Result
 	responses map[string]*Result
 	// errors maps "binary arg1 arg2 ..." to error
 	errors map[string]error
 	// defaultResponse is returned when no specific mapping exists
 	defaultResponse *Result
-	// calls records all calls made (for assertions)
+	expectations    []*Expectation
+	unexpected      []MockCall
+	onUnexpected    TB
+
+	// Calls records all calls made (for assertions). Read it only after
+	// the calls under test have returned; use CallLog while calls may
+	// still be in flight.
 	Calls []MockCall
 }
 
@@ -24,6 +40,13 @@ type MockCall struct {
 	Args   []string
 }
 
+// TB is the subset of testing.TB used by MockExecutor.
+type TB interface {
+	Helper()
+	Errorf(format string, args ...any)
+	Cleanup(func())
+}
+
 // NewMockExecutor creates a new mock executor.
 func NewMockExecutor() *MockExecutor {
 	return &MockExecutor{
@@ -32,43 +55,151 @@ func NewMockExecutor() *MockExecutor {
 	}
 }
 
-// WithResponse configures a response for a specific command.
+// WithZaiaResponse configures a response for a specific zaia command.
 // key format: "arg1 arg2 ..." (without binary name)
 func (m *MockExecutor) WithZaiaResponse(args string, result *Result) *MockExecutor {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.responses["zaia "+args] = result
 	return m
 }
 
 // WithZcliResponse configures a response for a specific zcli command.
 func (m *MockExecutor) WithZcliResponse(args string, result *Result) *MockExecutor {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.responses["zcli "+args] = result
 	return m
 }
 
 // WithZaiaError configures an error for a specific zaia command.
 func (m *MockExecutor) WithZaiaError(args string, err error) *MockExecutor {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.errors["zaia "+args] = err
 	return m
 }
 
 // WithDefault sets a default response for unmatched commands.
 func (m *MockExecutor) WithDefault(result *Result) *MockExecutor {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.defaultResponse = result
 	return m
 }
 
+// FailOnUnexpected makes every call that matches nothing fail t, and checks
+// at cleanup that all expectations were met (see AssertExpectations).
+// Unmatched calls return an error in any mode.
+func (m *MockExecutor) FailOnUnexpected(t TB) *MockExecutor {
+	t.Helper()
+	m.mu.Lock()
+	m.onUnexpected = t
+	m.mu.Unlock()
+	t.Cleanup(func() { m.AssertExpectations(t) })
+	return m
+}
+
+// ExpectZaia adds an expectation for zaia calls whose arguments match args.
+// Each element is either a literal string or an ArgMatcher.
+func (m *MockExecutor) ExpectZaia(args ...any) *Expectation {
+	return m.expect("zaia", args)
+}
+
+// ExpectZcli adds an expectation for zcli calls whose arguments match args.
+func (m *MockExecutor) ExpectZcli(args ...any) *Expectation {
+	return m.expect("zcli", args)
+}
+
+func (m *MockExecutor) expect(binary string, args []any) *Expectation {
+	matchers := make([]ArgMatcher, len(args))
+	for i, a := range args {
+		switch v := a.(type) {
+		case string:
+			matchers[i] = Eq(v)
+		case ArgMatcher:
+			matchers[i] = v
+		default:
+			panic(fmt.Sprintf("mock: argument %d must be a string or ArgMatcher, got %T", i, a))
+		}
+	}
+	e := &Expectation{m: m, binary: binary, matchers: matchers, times: -1}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.expectations = append(m.expectations, e)
+	return e
+}
+
+// CallLog returns a copy of the calls made so far.
+func (m *MockExecutor) CallLog() []MockCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.Calls)
+}
+
+// CallCount returns how many calls were made to binary with exactly args
+// ("arg1 arg2 ...").
+func (m *MockExecutor) CallCount(binary, args string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for _, c := range m.Calls {
+		if c.Binary == binary && strings.Join(c.Args, " ") == args {
+			n++
+		}
+	}
+	return n
+}
+
+// Unexpected returns the calls that matched no expectation or response.
+func (m *MockExecutor) Unexpected() []MockCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.unexpected)
+}
+
+// AssertExpectations reports unexpected calls and expectations whose call
+// count (Times) was not met.
+func (m *MockExecutor) AssertExpectations(t TB) {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, c := range m.unexpected {
+		t.Errorf("mock: unexpected call: %s %s", c.Binary, strings.Join(c.Args, " "))
+	}
+	for _, e := range m.expectations {
+		if e.times >= 0 && e.calls != e.times {
+			t.Errorf("mock: expected %s to be called %d times, got %d", e, e.times, e.calls)
+		}
+	}
+}
+
 // RunZaia implements Executor.
 func (m *MockExecutor) RunZaia(ctx context.Context, args ...string) (*Result, error) {
-	return m.resolve(ctx, "zaia", args...)
+	result, err := m.resolve("zaia", args...)
+	replayLines(ctx, result)
+	return result, err
 }
 
 // RunZcli implements Executor.
 func (m *MockExecutor) RunZcli(ctx context.Context, args ...string) (*Result, error) {
-	return m.resolve(ctx, "zcli", args...)
+	result, err := m.resolve("zcli", args...)
+	replayLines(ctx, result)
+	return result, err
 }
 
-func (m *MockExecutor) resolve(_ context.Context, binary string, args ...string) (*Result, error) {
-	m.Calls = append(m.Calls, MockCall{Binary: binary, Args: args})
+func (m *MockExecutor) resolve(binary string, args ...string) (*Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	call := MockCall{Binary: binary, Args: slices.Clone(args)}
+	m.Calls = append(m.Calls, call)
+
+	for _, e := range m.expectations {
+		if e.exhausted() || !e.matches(binary, args) {
+			continue
+		}
+		return e.next()
+	}
 
 	key := binary + " " + strings.Join(args, " ")
 
@@ -80,23 +211,173 @@ func (m *MockExecutor) resolve(_ context.Context, binary string, args ...string)
 		return result, nil
 	}
 
-	// Try prefix matching: if the full key doesn't match, check if any registered
-	// key is a prefix of the actual command. Note: Go map iteration order is
-	// non-deterministic, so avoid registering multiple overlapping prefixes
-	// (e.g. "zaia discover" and "zaia discover --service") — use exact keys instead.
-	for k, result := range m.responses {
-		if strings.HasPrefix(key, k) {
-			return result, nil
+	// Prefix matching: the longest registered key that is a prefix of the
+	// command on an argument boundary wins, so overlapping keys such as
+	// "zaia discover" and "zaia discover --service" resolve deterministically.
+	best := ""
+	for k := range m.responses {
+		if len(k) > len(best) && strings.HasPrefix(key, k+" ") {
+			best = k
 		}
 	}
+	if best != "" {
+		return m.responses[best], nil
+	}
 
 	if m.defaultResponse != nil {
 		return m.defaultResponse, nil
 	}
 
+	m.unexpected = append(m.unexpected, call)
+	if m.onUnexpected != nil {
+		m.onUnexpected.Helper()
+		m.onUnexpected.Errorf("mock: unexpected call: %s", key)
+	}
 	return nil, fmt.Errorf("mock: no response configured for %q", key)
 }
 
+// Expectation is a call pattern with a sequence of responses.
+type Expectation struct {
+	m        *MockExecutor
+	binary   string
+	matchers []ArgMatcher
+	steps    []mockStep
+	times    int // exact number of calls, -1 for unlimited
+	calls    int
+}
+
+type mockStep struct {
+	result *Result
+	err    error
+}
+
+// Return appends responses to the sequence. Calls consume the sequence in
+// order; once exhausted, the last response repeats (unless Times caps it).
+func (e *Expectation) Return(results ...*Result) *Expectation {
+	e.m.mu.Lock()
+	defer e.m.mu.Unlock()
+	for _, r := range results {
+		e.steps = append(e.steps, mockStep{result: r})
+	}
+	return e
+}
+
+// ReturnError appends an error response to the sequence.
+func (e *Expectation) ReturnError(err error) *Expectation {
+	e.m.mu.Lock()
+	defer e.m.mu.Unlock()
+	e.steps = append(e.steps, mockStep{err: err})
+	return e
+}
+
+// Times limits the expectation to exactly n calls: after n calls it no
+// longer matches (so a later expectation can take over), and
+// AssertExpectations fails if fewer were made.
+func (e *Expectation) Times(n int) *Expectation {
+	e.m.mu.Lock()
+	defer e.m.mu.Unlock()
+	e.times = n
+	return e
+}
+
+// Once is Times(1).
+func (e *Expectation) Once() *Expectation {
+	return e.Times(1)
+}
+
+// Calls returns how many calls matched the expectation.
+func (e *Expectation) Calls() int {
+	e.m.mu.Lock()
+	defer e.m.mu.Unlock()
+	return e.calls
+}
+
+func (e *Expectation) String() string {
+	parts := []string{e.binary}
+	for _, m := range e.matchers {
+		parts = append(parts, m.String())
+	}
+	return strings.Join(parts, " ")
+}
+
+func (e *Expectation) exhausted() bool {
+	return e.times >= 0 && e.calls >= e.times
+}
+
+func (e *Expectation) matches(binary string, args []string) bool {
+	if binary != e.binary {
+		return false
+	}
+	for i, m := range e.matchers {
+		if _, rest := m.(restMatcher); rest {
+			return true
+		}
+		if i >= len(args) || !m.Match(args[i]) {
+			return false
+		}
+	}
+	return len(args) == len(e.matchers)
+}
+
+// next returns the response for the current call. Caller must hold e.m.mu.
+func (e *Expectation) next() (*Result, error) {
+	e.calls++
+	if len(e.steps) == 0 {
+		return nil, fmt.Errorf("mock: no response configured for expectation %q", e)
+	}
+	step := e.steps[min(e.calls, len(e.steps))-1]
+	return step.result, step.err
+}
+
+// ArgMatcher matches a single CLI argument.
+type ArgMatcher interface {
+	Match(arg string) bool
+	String() string
+}
+
+type funcMatcher struct {
+	desc string
+	fn   func(string) bool
+}
+
+func (f funcMatcher) Match(arg string) bool { return f.fn(arg) }
+func (f funcMatcher) String() string        { return f.desc }
+
+// Eq matches exactly s.
+func Eq(s string) ArgMatcher {
+	return funcMatcher{desc: s, fn: func(arg string) bool { return arg == s }}
+}
+
+// Any matches any single argument.
+func Any() ArgMatcher {
+	return funcMatcher{desc: "<any>", fn: func(string) bool { return true }}
+}
+
+// HasPrefix matches arguments starting with prefix.
+func HasPrefix(prefix string) ArgMatcher {
+	return funcMatcher{desc: prefix + "*", fn: func(arg string) bool { return strings.HasPrefix(arg, prefix) }}
+}
+
+// Contains matches arguments containing sub.
+func Contains(sub string) ArgMatcher {
+	return funcMatcher{desc: "*" + sub + "*", fn: func(arg string) bool { return strings.Contains(arg, sub) }}
+}
+
+// MatchFunc matches arguments for which fn returns true.
+func MatchFunc(desc string, fn func(arg string) bool) ArgMatcher {
+	return funcMatcher{desc: desc, fn: fn}
+}
+
+type restMatcher struct{}
+
+func (restMatcher) Match(string) bool { return true }
+func (restMatcher) String() string    { return "<rest...>" }
+
+// Rest matches any remaining arguments (including none). It must be last.
+func Rest() ArgMatcher {
+	return restMatcher{}
+}
+
 // SyncResult creates a Result with sync JSON response.
 func SyncResult(data string) *Result {
 	return &Result{
@@ -2,6 +2,10 @@ package executor
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -104,3 +108,138 @@ func TestAsyncResult(t *testing.T) {
 		t.Errorf("got %q, want %q", result.Stdout, expected)
 	}
 }
+
+func TestMockExecutor_ConcurrentCalls(t *testing.T) {
+	m := NewMockExecutor().WithDefault(SyncResult(`{}`))
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = m.RunZaia(t.Context(), "discover", "--service", fmt.Sprint(i%2))
+			_ = m.CallLog()
+		}()
+	}
+	wg.Wait()
+	if got := m.CallCount("zaia", "discover --service 0"); got != 10 {
+		t.Errorf("CallCount: got %d, want 10", got)
+	}
+	if len(m.Calls) != 20 {
+		t.Errorf("Calls: got %d, want 20", len(m.Calls))
+	}
+}
+
+func TestMockExecutor_LongestPrefixWins(t *testing.T) {
+	m := NewMockExecutor().
+		WithZaiaResponse("discover", SyncResult(`"all"`)).
+		WithZaiaResponse("discover --service", SyncResult(`"service"`))
+
+	for range 10 {
+		r, err := m.RunZaia(t.Context(), "discover", "--service", "api")
+		if err != nil || !strings.Contains(string(r.Stdout), `"service"`) {
+			t.Fatalf("got %s, %v; want the longer prefix", r.Stdout, err)
+		}
+	}
+	if _, err := m.RunZaia(t.Context(), "discoverx"); err == nil {
+		t.Error("prefix must match on an argument boundary")
+	}
+}
+
+func TestMockExecutor_Sequence(t *testing.T) {
+	m := NewMockExecutor()
+	m.ExpectZaia("process", "p1").Return(
+		SyncResult(`{"status":"PENDING"}`),
+		SyncResult(`{"status":"FINISHED"}`),
+	)
+
+	var statuses []string
+	for range 3 {
+		r, err := m.RunZaia(t.Context(), "process", "p1")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		statuses = append(statuses, string(r.Stdout))
+	}
+	if !strings.Contains(statuses[0], "PENDING") || !strings.Contains(statuses[1], "FINISHED") || !strings.Contains(statuses[2], "FINISHED") {
+		t.Errorf("got %v, want PENDING then FINISHED repeating", statuses)
+	}
+}
+
+func TestMockExecutor_TimesHandsOver(t *testing.T) {
+	m := NewMockExecutor()
+	first := m.ExpectZaia("discover").Return(ErrorResult("API_ERROR", "boom", "", 1)).Times(2)
+	m.ExpectZaia("discover").Return(SyncResult(`{}`))
+
+	codes := []int{}
+	for range 3 {
+		r, _ := m.RunZaia(t.Context(), "discover")
+		codes = append(codes, r.ExitCode)
+	}
+	if fmt.Sprint(codes) != "[1 1 0]" {
+		t.Errorf("exit codes: got %v", codes)
+	}
+	if first.Calls() != 2 {
+		t.Errorf("first.Calls: got %d, want 2", first.Calls())
+	}
+}
+
+func TestMockExecutor_Matchers(t *testing.T) {
+	m := NewMockExecutor()
+	m.ExpectZaia("import", "--content", Any()).Return(AsyncResult(`[]`))
+	m.ExpectZaia("logs", "--service", HasPrefix("api"), Rest()).Return(SyncResult(`[]`))
+	m.ExpectZaia("validate", MatchFunc("yaml", func(a string) bool { return strings.HasSuffix(a, ".yml") })).
+		ReturnError(errors.New("boom"))
+
+	if _, err := m.RunZaia(t.Context(), "import", "--content", "services: []"); err != nil {
+		t.Errorf("import: %v", err)
+	}
+	if _, err := m.RunZaia(t.Context(), "import", "--content", "x", "--dry-run"); err == nil {
+		t.Error("extra argument must not match without Rest")
+	}
+	if _, err := m.RunZaia(t.Context(), "logs", "--service", "api-2", "--limit", "5"); err != nil {
+		t.Errorf("logs: %v", err)
+	}
+	if _, err := m.RunZaia(t.Context(), "validate", "zerops.yml"); err == nil || err.Error() != "boom" {
+		t.Errorf("validate: got %v, want boom", err)
+	}
+}
+
+type fakeTB struct {
+	mu       sync.Mutex
+	errors   []string
+	cleanups []func()
+}
+
+func (f *fakeTB) Helper() {}
+func (f *fakeTB) Errorf(format string, args ...any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+func (f *fakeTB) Cleanup(fn func()) { f.cleanups = append(f.cleanups, fn) }
+
+func TestMockExecutor_FailOnUnexpected(t *testing.T) {
+	tb := &fakeTB{}
+	m := NewMockExecutor().FailOnUnexpected(tb)
+	m.ExpectZaia("discover").Return(SyncResult(`{}`)).Once()
+	m.ExpectZcli("push").Return(SyncResult(`{}`)).Once()
+
+	_, _ = m.RunZaia(t.Context(), "discover")
+	if _, err := m.RunZaia(t.Context(), "discover"); err == nil {
+		t.Error("call beyond Once should be unexpected")
+	}
+	for _, fn := range tb.cleanups {
+		fn()
+	}
+
+	joined := strings.Join(tb.errors, "\n")
+	if !strings.Contains(joined, "unexpected call: zaia discover") {
+		t.Errorf("missing unexpected-call failure: %s", joined)
+	}
+	if !strings.Contains(joined, "expected zcli push to be called 1 times, got 0") {
+		t.Errorf("missing unmet expectation failure: %s", joined)
+	}
+	if len(m.Unexpected()) != 1 {
+		t.Errorf("Unexpected: got %d, want 1", len(m.Unexpected()))
+	}
+}
@@ -0,0 +1,28 @@
+//go:build !windows
+
+package executor
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCLIExecutor_TimeoutKillsProcessGroup(t *testing.T) {
+	// The grandchild inherits stdout; if only the shell were killed,
+	// Wait would block on the open pipe until waitDelay.
+	exec := NewCLIExecutor("sh", "sh")
+	exec.DefaultTimeout = 100 * time.Millisecond
+
+	start := time.Now()
+	_, err := exec.RunZaia(t.Context(), "-c", "sleep 30 & wait")
+	elapsed := time.Since(start)
+
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("got error %v, want *TimeoutError", err)
+	}
+	if elapsed > waitDelay/2 {
+		t.Errorf("run took %v; grandchild was not killed with the group", elapsed)
+	}
+}
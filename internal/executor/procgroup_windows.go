@@ -0,0 +1,15 @@
+//go:build windows
+
+package executor
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup starts the command in a new process group. Windows has no
+// group kill via signals, so cancellation falls back to killing the direct
+// child (exec.CommandContext default).
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
@@ -0,0 +1,274 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+type profileKey struct{}
+
+// WithProfile returns a context selecting the named profile for CLI calls
+// made with it. An empty name selects the default profile.
+func WithProfile(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, profileKey{}, name)
+}
+
+// ProfileFrom returns the profile selected by WithProfile ("" if none).
+func ProfileFrom(ctx context.Context) string {
+	name, _ := ctx.Value(profileKey{}).(string)
+	return name
+}
+
+// Profile is one named Zerops project target.
+type Profile struct {
+	Name        string
+	Description string
+	ProjectID   string   // passed to zcli push as --projectId; checked against zaia's project
+	Exec        Executor // runs CLIs with the profile's credentials and binaries
+}
+
+// ProfileInfo describes a profile without its executor or credentials.
+type ProfileInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	ProjectID   string `json:"projectId,omitempty"`
+	Default     bool   `json:"default,omitempty"`
+}
+
+// UnknownProfileError is returned for calls selecting a profile that is not configured.
+type UnknownProfileError struct {
+	Name      string
+	Available []string
+}
+
+func (e *UnknownProfileError) Error() string {
+	if len(e.Available) == 0 {
+		return fmt.Sprintf("unknown profile %q: no profiles are configured", e.Name)
+	}
+	return fmt.Sprintf("unknown profile %q (available: %s)", e.Name, strings.Join(e.Available, ", "))
+}
+
+// ProjectMismatchError is returned for zaia calls of a profile whose token
+// gives zaia a different project than the profile's ProjectID.
+type ProjectMismatchError struct {
+	Profile string
+	Want    string // the profile's ProjectID
+	Got     string // the project zaia discover reported
+}
+
+func (e *ProjectMismatchError) Error() string {
+	return fmt.Sprintf("profile %q is for project %s, but its token gives zaia project %s; use a token for project %s", e.Profile, e.Want, e.Got, e.Want)
+}
+
+// Router is implemented by executors that dispatch each call to one of
+// several executors depending on the context.
+type Router interface {
+	Route(ctx context.Context) (Executor, error)
+}
+
+// ProfileExecutor dispatches each call to the executor of the profile
+// selected by WithProfile. Calls without a profile go to the default
+// profile, or to fallback if there is none.
+//
+// zaia has no project flag: it acts on the project its token is scoped
+// to. Before the first zaia call of a profile with a ProjectID, the
+// project reported by `zaia discover` is checked against it.
+type ProfileExecutor struct {
+	profiles    map[string]Profile
+	names       []string
+	defaultName string
+	fallback    Executor
+
+	mu       sync.Mutex
+	verified map[string]bool // profiles whose zaia project matched
+}
+
+// NewProfileExecutor creates a ProfileExecutor. defaultName, if non-empty,
+// must name one of profiles.
+func NewProfileExecutor(fallback Executor, defaultName string, profiles ...Profile) (*ProfileExecutor, error) {
+	p := &ProfileExecutor{
+		profiles:    make(map[string]Profile, len(profiles)),
+		defaultName: defaultName,
+		fallback:    fallback,
+		verified:    make(map[string]bool),
+	}
+	for _, prof := range profiles {
+		if prof.Name == "" || prof.Exec == nil {
+			return nil, fmt.Errorf("profile %q: name and executor are required", prof.Name)
+		}
+		if _, dup := p.profiles[prof.Name]; dup {
+			return nil, fmt.Errorf("duplicate profile %q", prof.Name)
+		}
+		p.profiles[prof.Name] = prof
+		p.names = append(p.names, prof.Name)
+	}
+	slices.Sort(p.names)
+	if defaultName != "" {
+		if _, ok := p.profiles[defaultName]; !ok {
+			return nil, &UnknownProfileError{Name: defaultName, Available: p.names}
+		}
+	}
+	return p, nil
+}
+
+// Profiles lists the configured profiles sorted by name.
+func (p *ProfileExecutor) Profiles() []ProfileInfo {
+	out := make([]ProfileInfo, 0, len(p.names))
+	for _, name := range p.names {
+		prof := p.profiles[name]
+		out = append(out, ProfileInfo{
+			Name:        prof.Name,
+			Description: prof.Description,
+			ProjectID:   prof.ProjectID,
+			Default:     prof.Name == p.defaultName,
+		})
+	}
+	return out
+}
+
+// Unwrap returns the executor used for calls without a profile.
+func (p *ProfileExecutor) Unwrap() Executor {
+	if p.defaultName != "" {
+		return p.profiles[p.defaultName].Exec
+	}
+	return p.fallback
+}
+
+// Route implements Router.
+func (p *ProfileExecutor) Route(ctx context.Context) (Executor, error) {
+	prof, err := p.profile(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if prof.Exec == nil {
+		return p.fallback, nil
+	}
+	return prof.Exec, nil
+}
+
+func (p *ProfileExecutor) profile(ctx context.Context) (Profile, error) {
+	name := ProfileFrom(ctx)
+	if name == "" {
+		name = p.defaultName
+	}
+	if name == "" {
+		return Profile{}, nil
+	}
+	prof, ok := p.profiles[name]
+	if !ok {
+		return Profile{}, &UnknownProfileError{Name: name, Available: p.names}
+	}
+	return prof, nil
+}
+
+// RunZaia implements Executor. Calls of a profile whose zaia project
+// differs from its ProjectID fail with ProjectMismatchError.
+func (p *ProfileExecutor) RunZaia(ctx context.Context, args ...string) (*Result, error) {
+	prof, err := p.profile(ctx)
+	if err != nil {
+		return nil, err
+	}
+	exec := prof.Exec
+	if exec == nil {
+		exec = p.fallback
+	}
+	ctx = WithProfile(ctx, "")
+	if prof.ProjectID == "" || p.isVerified(prof.Name) {
+		return exec.RunZaia(ctx, args...)
+	}
+	// A plain discover is the check itself.
+	if slices.Equal(args, []string{"discover"}) {
+		result, err := exec.RunZaia(ctx, args...)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.verify(prof, result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+	result, err := exec.RunZaia(ctx, "discover")
+	if err != nil {
+		return nil, err
+	}
+	if err := p.verify(prof, result); err != nil {
+		return nil, err
+	}
+	return exec.RunZaia(ctx, args...)
+}
+
+func (p *ProfileExecutor) isVerified(name string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.verified[name]
+}
+
+// verify checks the project of a `zaia discover` result against the
+// profile's ProjectID. Results without a project ID (such as auth errors)
+// are let through unverified, so the call reports zaia's own error.
+func (p *ProfileExecutor) verify(prof Profile, discover *Result) error {
+	var resp struct {
+		Type string `json:"type"`
+		Data struct {
+			Project struct {
+				ID string `json:"id"`
+			} `json:"project"`
+		} `json:"data"`
+	}
+	if json.Unmarshal(discover.Stdout, &resp) != nil || resp.Type != "sync" || resp.Data.Project.ID == "" {
+		return nil
+	}
+	if got := resp.Data.Project.ID; got != prof.ProjectID {
+		return &ProjectMismatchError{Profile: prof.Name, Want: prof.ProjectID, Got: got}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.verified[prof.Name] = true
+	return nil
+}
+
+// RunZcli implements Executor. zcli push gets the profile's --projectId
+// unless the caller passed one.
+func (p *ProfileExecutor) RunZcli(ctx context.Context, args ...string) (*Result, error) {
+	prof, err := p.profile(ctx)
+	if err != nil {
+		return nil, err
+	}
+	exec := prof.Exec
+	if exec == nil {
+		exec = p.fallback
+	}
+	if prof.ProjectID != "" && len(args) > 0 && args[0] == "push" && !hasFlag(args, "--projectId") {
+		args = append(slices.Clone(args), "--projectId", prof.ProjectID)
+	}
+	return exec.RunZcli(WithProfile(ctx, ""), args...)
+}
+
+// FindFor is Find, but at executors implementing Router it follows the
+// executor selected for ctx instead of Unwrap.
+func FindFor[T any](ctx context.Context, e Executor) (T, bool) {
+	for e != nil {
+		if t, ok := e.(T); ok {
+			return t, true
+		}
+		if r, ok := e.(Router); ok {
+			next, err := r.Route(ctx)
+			if err != nil {
+				break
+			}
+			e = next
+			continue
+		}
+		u, ok := e.(Unwrapper)
+		if !ok {
+			break
+		}
+		e = u.Unwrap()
+	}
+	var zero T
+	return zero, false
+}
@@ -0,0 +1,109 @@
+package executor
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestProfileExecutor_Routing(t *testing.T) {
+	base := NewMockExecutor().WithDefault(SyncResult(`"base"`))
+	prod := NewMockExecutor().WithDefault(SyncResult(`"prod"`))
+	p, err := NewProfileExecutor(base, "", Profile{Name: "prod", ProjectID: "p1", Exec: prod})
+	if err != nil {
+		t.Fatalf("NewProfileExecutor: %v", err)
+	}
+
+	_, _ = p.RunZaia(t.Context(), "discover")
+	_, _ = p.RunZaia(WithProfile(t.Context(), "prod"), "discover")
+	_, _ = p.RunZcli(WithProfile(t.Context(), "prod"), "push", "--serviceId", "s1")
+	_, _ = p.RunZcli(WithProfile(t.Context(), "prod"), "push", "--projectId", "other")
+
+	if len(base.Calls) != 1 {
+		t.Errorf("base calls: got %d, want 1", len(base.Calls))
+	}
+	wantProd := [][]string{
+		{"discover"},
+		{"push", "--serviceId", "s1", "--projectId", "p1"},
+		{"push", "--projectId", "other"},
+	}
+	if len(prod.Calls) != len(wantProd) {
+		t.Fatalf("prod calls: got %d, want %d", len(prod.Calls), len(wantProd))
+	}
+	for i, want := range wantProd {
+		if !slices.Equal(prod.Calls[i].Args, want) {
+			t.Errorf("prod call %d: got %v, want %v", i, prod.Calls[i].Args, want)
+		}
+	}
+}
+
+func TestProfileExecutor_Unknown(t *testing.T) {
+	p, err := NewProfileExecutor(NewMockExecutor(), "", Profile{Name: "prod", Exec: NewMockExecutor()})
+	if err != nil {
+		t.Fatalf("NewProfileExecutor: %v", err)
+	}
+	_, err = p.RunZaia(WithProfile(t.Context(), "dev"), "discover")
+	var unknown *UnknownProfileError
+	if !errors.As(err, &unknown) || !slices.Equal(unknown.Available, []string{"prod"}) {
+		t.Errorf("got %v, want UnknownProfileError listing prod", err)
+	}
+
+	if _, err := NewProfileExecutor(NewMockExecutor(), "missing"); err == nil {
+		t.Error("expected error for unknown default profile")
+	}
+}
+
+func TestCLIExecutor_RejectsProfileWithoutRouter(t *testing.T) {
+	exec := NewCLIExecutor("echo", "echo")
+	_, err := exec.RunZaia(WithProfile(t.Context(), "prod"), "hello")
+	var unknown *UnknownProfileError
+	if !errors.As(err, &unknown) {
+		t.Errorf("got %v, want UnknownProfileError", err)
+	}
+}
+
+func TestFindFor_FollowsRoute(t *testing.T) {
+	prodCLI := NewCLIExecutor("zaia-prod", "zcli-prod")
+	p, _ := NewProfileExecutor(NewMockExecutor(), "", Profile{Name: "prod", Exec: NewLimitedExecutor(prodCLI, 1)})
+
+	if _, ok := FindFor[Locator](t.Context(), p); ok {
+		t.Error("default route (mock) should not yield a Locator")
+	}
+	loc, ok := FindFor[Locator](WithProfile(t.Context(), "prod"), p)
+	if !ok || loc != prodCLI {
+		t.Errorf("got %v, %v; want prod CLI executor", loc, ok)
+	}
+}
+
+func TestProfileExecutor_ChecksZaiaProject(t *testing.T) {
+	prod := NewMockExecutor().
+		WithZaiaResponse("discover", SyncResult(`{"project":{"id":"p1"}}`)).
+		WithDefault(SyncResult(`{}`))
+	stale := NewMockExecutor().WithZaiaResponse("discover", SyncResult(`{"project":{"id":"other"}}`))
+	p, err := NewProfileExecutor(NewMockExecutor(), "",
+		Profile{Name: "prod", ProjectID: "p1", Exec: prod},
+		Profile{Name: "stale", ProjectID: "p1", Exec: stale},
+	)
+	if err != nil {
+		t.Fatalf("NewProfileExecutor: %v", err)
+	}
+
+	ctx := WithProfile(t.Context(), "prod")
+	for range 2 {
+		if _, err := p.RunZaia(ctx, "env", "get", "--project"); err != nil {
+			t.Fatalf("prod: %v", err)
+		}
+	}
+	if got := prod.CallCount("zaia", "discover"); got != 1 {
+		t.Errorf("prod discover checks: got %d, want 1", got)
+	}
+
+	_, err = p.RunZaia(WithProfile(t.Context(), "stale"), "env", "get", "--project")
+	var mismatch *ProjectMismatchError
+	if !errors.As(err, &mismatch) || mismatch.Got != "other" {
+		t.Errorf("got %v, want ProjectMismatchError for project other", err)
+	}
+	if got := stale.CallCount("zaia", "env get --project"); got != 0 {
+		t.Errorf("stale profile ran %d env calls, want 0", got)
+	}
+}
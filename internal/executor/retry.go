@@ -0,0 +1,141 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand/v2"
+	"time"
+)
+
+// retryableCommands lists read-only zaia subcommands backing tools annotated
+// ReadOnlyHint/IdempotentHint: discover, logs, events, knowledge (search),
+// process status and validate. `process <id>` is a status check; `cancel`
+// is deliberately absent.
+var retryableCommands = map[string]bool{
+	"discover": true,
+	"logs":     true,
+	"events":   true,
+	"search":   true,
+	"process":  true,
+	"validate": true,
+}
+
+// IsRetryable reports whether zaia args describe a command safe to retry.
+func IsRetryable(args []string) bool {
+	return len(args) > 0 && retryableCommands[args[0]]
+}
+
+// RetryPolicy controls retries of transient CLI failures.
+type RetryPolicy struct {
+	MaxAttempts int             // total attempts including the first (<= 1 disables retries)
+	BaseDelay   time.Duration   // delay before the second attempt
+	MaxDelay    time.Duration   // cap on a single delay
+	Codes       map[string]bool // CLI error codes treated as transient
+}
+
+// DefaultRetryPolicy returns the default policy: 3 attempts, 250ms base
+// delay doubling up to 2s, retrying network/API availability errors.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   250 * time.Millisecond,
+		MaxDelay:    2 * time.Second,
+		Codes: map[string]bool{
+			"NETWORK_ERROR":       true,
+			"API_ERROR":           true,
+			"API_TIMEOUT":         true,
+			"RATE_LIMITED":        true,
+			"SERVICE_UNAVAILABLE": true,
+		},
+	}
+}
+
+// delay returns the backoff before attempt n+1 (n >= 1): exponential with
+// jitter in [d/2, d].
+func (p RetryPolicy) delay(n int) time.Duration {
+	d := p.BaseDelay << (n - 1)
+	if d <= 0 || (p.MaxDelay > 0 && d > p.MaxDelay) {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	half := d / 2
+	return half + rand.N(half+1) //nolint:gosec // jitter does not need crypto randomness
+}
+
+// RetryExecutor retries retryable zaia commands whose output is a CLI error
+// envelope with a transient code. Mutating commands and zcli are never retried.
+// Result.Attempts records how many attempts were made.
+type RetryExecutor struct {
+	next   Executor
+	policy RetryPolicy
+}
+
+// NewRetryExecutor wraps next with policy.
+func NewRetryExecutor(next Executor, policy RetryPolicy) *RetryExecutor {
+	return &RetryExecutor{next: next, policy: policy}
+}
+
+// Unwrap returns the wrapped executor.
+func (r *RetryExecutor) Unwrap() Executor {
+	return r.next
+}
+
+// RunZaia implements Executor.
+func (r *RetryExecutor) RunZaia(ctx context.Context, args ...string) (*Result, error) {
+	if !IsRetryable(args) || r.policy.MaxAttempts <= 1 {
+		return r.next.RunZaia(ctx, args...)
+	}
+	for attempt := 1; ; attempt++ {
+		result, err := r.next.RunZaia(ctx, args...)
+		if err != nil || attempt >= r.policy.MaxAttempts || !r.transient(result) {
+			return withAttempts(result, attempt), err
+		}
+		select {
+		case <-time.After(r.policy.delay(attempt)):
+		case <-ctx.Done():
+			return withAttempts(result, attempt), nil
+		}
+	}
+}
+
+// withAttempts returns a copy of result with Attempts set; results from the
+// wrapped executor may be shared (e.g. mock responses) and are not mutated.
+func withAttempts(result *Result, attempts int) *Result {
+	if result == nil {
+		return nil
+	}
+	out := *result
+	out.Attempts = attempts
+	return &out
+}
+
+// RunZcli implements Executor.
+func (r *RetryExecutor) RunZcli(ctx context.Context, args ...string) (*Result, error) {
+	return r.next.RunZcli(ctx, args...)
+}
+
+// transient reports whether result is a CLI error envelope with a retryable code.
+func (r *RetryExecutor) transient(result *Result) bool {
+	if result == nil || result.ExitCode == 0 {
+		return false
+	}
+	return r.policy.Codes[ErrorCode(result)]
+}
+
+// ErrorCode returns the code of a CLI error envelope ({"type":"error","code":...})
+// on stdout, or "" if result is not one.
+func ErrorCode(result *Result) string {
+	if result == nil {
+		return ""
+	}
+	var resp struct {
+		Type string `json:"type"`
+		Code string `json:"code"`
+	}
+	if json.Unmarshal(result.Stdout, &resp) != nil || resp.Type != "error" {
+		return ""
+	}
+	return resp.Code
+}
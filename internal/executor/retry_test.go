@@ -0,0 +1,106 @@
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// seqExecutor returns results in order, repeating the last one.
+type seqExecutor struct {
+	results []*Result
+	calls   int
+}
+
+func (s *seqExecutor) RunZaia(_ context.Context, _ ...string) (*Result, error) {
+	r := s.results[min(s.calls, len(s.results)-1)]
+	s.calls++
+	return r, nil
+}
+
+func (s *seqExecutor) RunZcli(ctx context.Context, args ...string) (*Result, error) {
+	return s.RunZaia(ctx, args...)
+}
+
+func fastPolicy() RetryPolicy {
+	p := DefaultRetryPolicy()
+	p.BaseDelay = time.Millisecond
+	p.MaxDelay = 2 * time.Millisecond
+	return p
+}
+
+func TestRetryExecutor_RetriesTransientErrors(t *testing.T) {
+	seq := &seqExecutor{results: []*Result{
+		ErrorResult("NETWORK_ERROR", "connection reset", "", 1),
+		ErrorResult("API_TIMEOUT", "timeout", "", 1),
+		SyncResult(`{"services":[]}`),
+	}}
+	r := NewRetryExecutor(seq, fastPolicy())
+
+	result, err := r.RunZaia(t.Context(), "discover")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ExitCode != 0 {
+		t.Errorf("got exit code %d, want 0", result.ExitCode)
+	}
+	if result.Attempts != 3 || seq.calls != 3 {
+		t.Errorf("got attempts %d (calls %d), want 3", result.Attempts, seq.calls)
+	}
+}
+
+func TestRetryExecutor_GivesUpAfterMaxAttempts(t *testing.T) {
+	seq := &seqExecutor{results: []*Result{ErrorResult("NETWORK_ERROR", "down", "", 1)}}
+	r := NewRetryExecutor(seq, fastPolicy())
+
+	result, _ := r.RunZaia(t.Context(), "logs", "--service", "api")
+	if result.ExitCode != 1 || result.Attempts != 3 {
+		t.Errorf("got exit %d attempts %d, want exit 1 after 3 attempts", result.ExitCode, result.Attempts)
+	}
+}
+
+func TestRetryExecutor_NoRetry(t *testing.T) {
+	tests := map[string][]string{
+		"non-transient code": {"discover"},
+		"mutating command":   {"restart", "--service", "api"},
+		"process cancel":     {"cancel", "p1"},
+	}
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			result := ErrorResult("NETWORK_ERROR", "down", "", 1)
+			if name == "non-transient code" {
+				result = ErrorResult("SERVICE_NOT_FOUND", "no such service", "", 1)
+			}
+			seq := &seqExecutor{results: []*Result{result}}
+			r := NewRetryExecutor(seq, fastPolicy())
+			_, _ = r.RunZaia(t.Context(), args...)
+			if seq.calls != 1 {
+				t.Errorf("got %d calls, want 1", seq.calls)
+			}
+		})
+	}
+}
+
+func TestRetryExecutor_DoesNotMutateSharedResult(t *testing.T) {
+	shared := SyncResult(`{}`)
+	r := NewRetryExecutor(&seqExecutor{results: []*Result{shared}}, fastPolicy())
+	result, _ := r.RunZaia(t.Context(), "discover")
+	if result.Attempts != 1 {
+		t.Errorf("got attempts %d, want 1", result.Attempts)
+	}
+	if shared.Attempts != 0 {
+		t.Error("wrapped executor's result was mutated")
+	}
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+	for n, want := range map[int]time.Duration{1: 100, 2: 200, 3: 300, 10: 300} {
+		want *= time.Millisecond
+		for i := 0; i < 20; i++ {
+			if d := p.delay(n); d < want/2 || d > want {
+				t.Fatalf("delay(%d) = %v, want in [%v, %v]", n, d, want/2, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"sync"
+)
+
+// Stream names the subprocess output stream a line came from.
+type Stream string
+
+// Output streams.
+const (
+	Stdout Stream = "stdout"
+	Stderr Stream = "stderr"
+)
+
+// maxLineLen bounds a buffered line; longer output is delivered in chunks.
+const maxLineLen = 16 * 1024
+
+// LineFunc receives subprocess output line by line while the process runs.
+// Calls are serialized across both streams. Lines have their terminator
+// ("\n", "\r\n" or a bare "\r" used by progress bars) removed and secrets
+// redacted; empty lines are skipped.
+type LineFunc func(stream Stream, line string)
+
+type lineKey struct{}
+
+// WithLineHandler returns a context that makes CLIExecutor stream output of
+// calls made with it to fn. The complete output is still returned in Result.
+func WithLineHandler(ctx context.Context, fn LineFunc) context.Context {
+	return context.WithValue(ctx, lineKey{}, fn)
+}
+
+func lineHandlerFrom(ctx context.Context) LineFunc {
+	fn, _ := ctx.Value(lineKey{}).(LineFunc)
+	return fn
+}
+
+// lineSink serializes line delivery from the stdout and stderr writers.
+type lineSink struct {
+	mu     sync.Mutex
+	fn     LineFunc
+	redact *redactor
+}
+
+func (s *lineSink) emit(stream Stream, line []byte) {
+	if len(line) == 0 {
+		return
+	}
+	line = s.redact.bytes(line)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fn(stream, string(line))
+}
+
+// writer returns an io.Writer splitting output of stream into lines.
+func (s *lineSink) writer(stream Stream) *lineWriter {
+	return &lineWriter{sink: s, stream: stream}
+}
+
+// lineWriter buffers a partial line between writes.
+type lineWriter struct {
+	sink   *lineSink
+	stream Stream
+	buf    []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexAny(p, "\r\n")
+		seg := p
+		if i >= 0 {
+			seg = p[:i]
+		}
+		if room := maxLineLen - len(w.buf); len(seg) >= room {
+			w.buf = append(w.buf, seg[:room]...)
+			w.flush()
+			p = p[room:]
+			continue
+		}
+		w.buf = append(w.buf, seg...)
+		if i < 0 {
+			break
+		}
+		w.flush()
+		p = p[i+1:]
+	}
+	return n, nil
+}
+
+// flush delivers the buffered partial line, if any.
+func (w *lineWriter) flush() {
+	w.sink.emit(w.stream, w.buf)
+	w.buf = w.buf[:0]
+}
+
+// replayLines delivers the captured output of result to the line handler
+// in ctx, if any. Used by executors that do not run real processes.
+func replayLines(ctx context.Context, result *Result) {
+	fn := lineHandlerFrom(ctx)
+	if fn == nil || result == nil {
+		return
+	}
+	sink := &lineSink{fn: fn}
+	for _, out := range []struct {
+		stream Stream
+		data   []byte
+	}{{Stderr, result.Stderr}, {Stdout, result.Stdout}} {
+		w := sink.writer(out.stream)
+		_, _ = w.Write(out.data)
+		w.flush()
+	}
+}
@@ -0,0 +1,100 @@
+package executor
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type lineRecorder struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (r *lineRecorder) record(stream Stream, line string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lines = append(r.lines, string(stream)+": "+line)
+}
+
+func (r *lineRecorder) get() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.lines...)
+}
+
+func TestLineWriter_Splitting(t *testing.T) {
+	var rec lineRecorder
+	w := (&lineSink{fn: rec.record}).writer(Stderr)
+	for _, chunk := range []string{"Upload", "ing\nBuild 10%\rBuild ", "50%\r\nDone\n\n", "tail"} {
+		_, _ = w.Write([]byte(chunk))
+	}
+	w.flush()
+
+	want := []string{"stderr: Uploading", "stderr: Build 10%", "stderr: Build 50%", "stderr: Done", "stderr: tail"}
+	if got := rec.get(); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestLineWriter_LongLineChunked(t *testing.T) {
+	var rec lineRecorder
+	w := (&lineSink{fn: rec.record}).writer(Stdout)
+	_, _ = w.Write([]byte(strings.Repeat("x", maxLineLen+10) + "\n"))
+	got := rec.get()
+	if len(got) != 2 || len(got[0]) != len("stdout: ")+maxLineLen {
+		t.Errorf("got %d lines (first %d bytes), want a %d-byte chunk and the rest", len(got), len(got[0]), maxLineLen)
+	}
+}
+
+func TestCLIExecutor_StreamsLinesWhileRunning(t *testing.T) {
+	exec := NewCLIExecutorWithEnv("sh", "sh", EnvPolicy{
+		Inject: map[string]string{TokenEnvVar: "zerops-test-token"},
+	})
+	var rec lineRecorder
+	first := make(chan struct{})
+	var once sync.Once
+	ctx := WithLineHandler(t.Context(), func(stream Stream, line string) {
+		rec.record(stream, line)
+		once.Do(func() { close(first) })
+	})
+
+	done := make(chan *Result)
+	go func() {
+		result, _ := exec.RunZcli(ctx, "-c", `echo "step 1 $ZEROPS_TOKEN" >&2; sleep 1; echo '{"type":"sync"}'`)
+		done <- result
+	}()
+
+	select {
+	case <-first:
+	case <-done:
+		t.Fatal("no line delivered before the process exited")
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the first line")
+	}
+	result := <-done
+
+	want := []string{"stderr: step 1 " + Redacted, `stdout: {"type":"sync"}`}
+	if got := rec.get(); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("lines: got %q, want %q", got, want)
+	}
+	if string(result.Stdout) != "{\"type\":\"sync\"}\n" {
+		t.Errorf("result stdout: got %q", result.Stdout)
+	}
+}
+
+func TestMockExecutor_ReplaysLines(t *testing.T) {
+	mock := NewMockExecutor().WithZcliResponse("push", &Result{
+		Stdout: []byte(`{"type":"sync"}`),
+		Stderr: []byte("uploading\nbuilding\n"),
+	})
+	var rec lineRecorder
+	_, _ = mock.RunZcli(WithLineHandler(context.Background(), rec.record), "push")
+
+	want := []string{"stderr: uploading", "stderr: building", `stdout: {"type":"sync"}`}
+	if got := rec.get(); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
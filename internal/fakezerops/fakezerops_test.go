@@ -0,0 +1,208 @@
+package fakezerops
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type fake struct {
+	t     *testing.T
+	state string
+}
+
+func newFake(t *testing.T) *fake {
+	t.Helper()
+	return &fake{t: t, state: filepath.Join(t.TempDir(), "state.json")}
+}
+
+// zaia runs a command and returns the decoded envelope and exit code.
+func (f *fake) zaia(args ...string) (map[string]any, int) {
+	f.t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := RunZaia(f.state, args, &stdout, &stderr)
+	var resp map[string]any
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		f.t.Fatalf("zaia %v: invalid envelope %q (stderr %q)", args, stdout.String(), stderr.String())
+	}
+	return resp, code
+}
+
+func (f *fake) mustZaia(args ...string) map[string]any {
+	f.t.Helper()
+	resp, code := f.zaia(args...)
+	if code != 0 || resp["type"] == "error" {
+		f.t.Fatalf("zaia %v: exit %d, %v", args, code, resp)
+	}
+	return resp
+}
+
+// wait polls `process` until every process of an async envelope finished.
+func (f *fake) wait(resp map[string]any) {
+	f.t.Helper()
+	procs, _ := resp["processes"].([]any)
+	if len(procs) == 0 {
+		f.t.Fatalf("expected async envelope, got %v", resp)
+	}
+	for _, p := range procs {
+		id := p.(map[string]any)["processId"].(string)
+		status := ""
+		for range 5 {
+			status = f.mustZaia("process", id)["data"].(map[string]any)["status"].(string)
+			if status != StatusPending && status != StatusRunning {
+				break
+			}
+		}
+		if status != StatusFinished {
+			f.t.Fatalf("process %s: got %s, want FINISHED", id, status)
+		}
+	}
+}
+
+func (f *fake) services() map[string]map[string]any {
+	f.t.Helper()
+	out := map[string]map[string]any{}
+	data := f.mustZaia("discover", "--include-envs")["data"].(map[string]any)
+	for _, s := range data["services"].([]any) {
+		m := s.(map[string]any)
+		out[m["hostname"].(string)] = m
+	}
+	return out
+}
+
+const importYAML = `services:
+  - hostname: app
+    type: nodejs@22
+    mode: NON_HA
+    ports:
+      - port: 3000
+  - hostname: cache
+    type: keydb@6
+    mode: NON_HA`
+
+func TestFake_Lifecycle(t *testing.T) {
+	f := newFake(t)
+
+	if resp := f.mustZaia("import", "--content", importYAML, "--dry-run"); resp["type"] != "sync" {
+		t.Fatalf("dry run: got %v", resp)
+	}
+	if len(f.services()) != 0 {
+		t.Fatal("dry run created services")
+	}
+
+	f.wait(f.mustZaia("import", "--content", importYAML))
+	svcs := f.services()
+	if got := svcs["app"]["status"]; got != ServiceReadyToDeploy {
+		t.Errorf("app status = %v, want READY_TO_DEPLOY", got)
+	}
+	if got := svcs["cache"]["status"]; got != ServiceActive {
+		t.Errorf("cache status = %v, want ACTIVE", got)
+	}
+
+	if resp, code := f.zaia("restart", "--service", "app"); code != 1 || resp["code"] != "INVALID_SERVICE_STATE" {
+		t.Errorf("restart undeployed: got exit %d, %v", code, resp)
+	}
+
+	f.wait(f.mustZaia("stop", "--service", "cache"))
+	if got := f.services()["cache"]["status"]; got != ServiceStopped {
+		t.Errorf("cache status after stop = %v", got)
+	}
+	f.wait(f.mustZaia("start", "--service", "cache"))
+
+	f.wait(f.mustZaia("env", "set", "--service", "app", "TEST_KEY=test_value"))
+	get := f.mustZaia("env", "get", "--service", "app")
+	if b, _ := json.Marshal(get["data"]); !strings.Contains(string(b), `"key":"TEST_KEY","value":"test_value"`) {
+		t.Errorf("env get = %s", b)
+	}
+
+	f.wait(f.mustZaia("delete", "--service", "app", "--confirm"))
+	f.wait(f.mustZaia("delete", "--service", "cache", "--confirm"))
+	if n := len(f.services()); n != 0 {
+		t.Errorf("%d services left after delete", n)
+	}
+}
+
+func TestFake_Errors(t *testing.T) {
+	f := newFake(t)
+	tests := []struct {
+		args []string
+		code string
+		exit int
+	}{
+		{[]string{"discover", "--service", "nope"}, "SERVICE_NOT_FOUND", 1},
+		{[]string{"process", "proc-9999"}, "PROCESS_NOT_FOUND", 1},
+		{[]string{"validate", "--content", "project:\n  name: x\nservices:\n  - hostname: a\n    type: go@1", "--type", "import.yml"}, "VALIDATION_FAILED", 1},
+		{[]string{"bogus"}, "UNKNOWN_COMMAND", 2},
+	}
+	for _, tt := range tests {
+		resp, exit := f.zaia(tt.args...)
+		if resp["type"] != "error" || resp["code"] != tt.code || exit != tt.exit {
+			t.Errorf("zaia %v: got exit %d, %v; want %s exit %d", tt.args, exit, resp, tt.code, tt.exit)
+		}
+	}
+
+	f.wait(f.mustZaia("import", "--content", importYAML))
+	if resp, _ := f.zaia("import", "--content", importYAML); resp["code"] != "SERVICE_ALREADY_EXISTS" {
+		t.Errorf("re-import: got %v", resp)
+	}
+	if resp, _ := f.zaia("delete", "--service", "app"); resp["code"] != "CONFIRM_REQUIRED" {
+		t.Errorf("delete without --confirm: got %v", resp)
+	}
+}
+
+func TestFake_Unauthenticated(t *testing.T) {
+	f := newFake(t)
+	t.Setenv(UnauthenticatedEnv, "1")
+	if resp, _ := f.zaia("discover"); resp["code"] != "AUTH_REQUIRED" {
+		t.Errorf("discover: got %v", resp)
+	}
+	if resp := f.mustZaia("version"); resp["data"].(map[string]any)["version"] != Version {
+		t.Errorf("version: got %v", resp)
+	}
+	var stdout, stderr bytes.Buffer
+	if code := RunZcli(f.state, []string{"project", "list"}, &stdout, &stderr); code == 0 || !strings.Contains(stderr.String(), "zcli login") {
+		t.Errorf("zcli project list: exit %d, stderr %q", code, stderr.String())
+	}
+}
+
+func TestFake_Search(t *testing.T) {
+	f := newFake(t)
+	data := f.mustZaia("search", "postgresql")["data"].(map[string]any)
+	top, _ := data["topResult"].(map[string]any)
+	if top["uri"] != "zerops://docs/services/postgresql" {
+		t.Fatalf("topResult = %v", data["topResult"])
+	}
+	doc := f.mustZaia("search", "--get", top["uri"].(string))["data"].(map[string]any)
+	if doc["content"] == "" {
+		t.Error("search --get returned no content")
+	}
+}
+
+func TestFake_PushDeploysService(t *testing.T) {
+	f := newFake(t)
+	f.wait(f.mustZaia("import", "--content", importYAML))
+	id := f.services()["app"]["serviceId"].(string)
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "zerops.yml"), []byte("zerops:\n  - setup: app\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr bytes.Buffer
+	if code := RunZcli(f.state, []string{"push", "--serviceId", id, "--workingDir", dir}, &stdout, &stderr); code != 0 {
+		t.Fatalf("push: exit %d, %s", code, stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "Deploy finished") {
+		t.Errorf("push progress = %q", stderr.String())
+	}
+	if got := f.services()["app"]["status"]; got != ServiceActive {
+		t.Errorf("app status after push = %v, want ACTIVE", got)
+	}
+	f.wait(f.mustZaia("restart", "--service", "app"))
+	f.wait(f.mustZaia("subdomain", "enable", "--service", "app"))
+	if url, _ := f.services()["app"]["subdomainUrl"].(string); url == "" {
+		t.Error("subdomain enable did not set subdomainUrl")
+	}
+}
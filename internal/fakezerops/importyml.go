@@ -0,0 +1,90 @@
+package fakezerops
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// importedService is a service entry read from import.yml.
+type importedService struct {
+	Hostname string `json:"hostname"`
+	Type     string `json:"type"`
+	Mode     string `json:"mode,omitempty"`
+}
+
+var hostnamePattern = regexp.MustCompile(`^[a-z][a-z0-9]{0,39}$`)
+
+// parseImport reads the services list of an import.yml. It understands
+// only the shape the tools and tests produce: a top-level services: list
+// whose entries carry hostname, type and mode as plain scalars. Nested
+// keys (ports, buildBase, ...) are ignored.
+func parseImport(content string) ([]importedService, error) {
+	var services []importedService
+	inServices := false
+	itemIndent, keyIndent := -1, -1
+	for i, raw := range strings.Split(content, "\n") {
+		line := strings.TrimRight(raw, " \t\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if indent == 0 {
+			switch {
+			case trimmed == "services:":
+				inServices = true
+			case strings.HasPrefix(trimmed, "project:"):
+				return nil, fmt.Errorf("line %d: project: section is not allowed, services are imported into the current project", i+1)
+			default:
+				inServices = false
+			}
+			continue
+		}
+		if !inServices {
+			continue
+		}
+		if item, ok := strings.CutPrefix(trimmed, "- "); ok && (itemIndent < 0 || indent == itemIndent) {
+			services = append(services, importedService{})
+			itemIndent, keyIndent = indent, indent+2
+			trimmed, indent = item, keyIndent
+		}
+		if len(services) == 0 {
+			return nil, fmt.Errorf("line %d: expected a list item under services:", i+1)
+		}
+		if indent != keyIndent {
+			continue
+		}
+		key, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		svc := &services[len(services)-1]
+		switch key {
+		case "hostname":
+			svc.Hostname = value
+		case "type":
+			svc.Type = value
+		case "mode":
+			svc.Mode = value
+		}
+	}
+
+	if len(services) == 0 {
+		return nil, fmt.Errorf("no services defined (expected a top-level services: list)")
+	}
+	seen := map[string]bool{}
+	for i, s := range services {
+		switch {
+		case !hostnamePattern.MatchString(s.Hostname):
+			return nil, fmt.Errorf("services[%d]: invalid hostname %q (lowercase letters and digits, starting with a letter)", i, s.Hostname)
+		case s.Type == "":
+			return nil, fmt.Errorf("services[%d]: type is required", i)
+		case seen[s.Hostname]:
+			return nil, fmt.Errorf("services[%d]: duplicate hostname %q", i, s.Hostname)
+		}
+		seen[s.Hostname] = true
+	}
+	return services, nil
+}
@@ -0,0 +1,73 @@
+package fakezerops
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// doc is one entry of the built-in knowledge corpus.
+type doc struct {
+	URI     string
+	Title   string
+	Content string
+}
+
+// docs is a small stand-in for the Zerops knowledge base.
+var docs = []doc{
+	{"zerops://docs/services/postgresql", "PostgreSQL", "Managed PostgreSQL service (type postgresql@16). Connect with ${hostname}_connectionString. HA mode runs three nodes."},
+	{"zerops://docs/services/keydb", "KeyDB", "Managed Redis-compatible KeyDB service (type keydb@6). Port 6379, no authentication inside the project network."},
+	{"zerops://docs/services/valkey", "Valkey", "Managed Valkey service (type valkey@7.2), a Redis-compatible key-value store."},
+	{"zerops://docs/runtimes/nodejs", "Node.js", "Runtime service (type nodejs@22). Deploy with zcli push; build and run commands live in zerops.yml."},
+	{"zerops://docs/runtimes/go", "Go", "Runtime service (type go@1). Build with go build in the build section of zerops.yml."},
+	{"zerops://docs/config/zerops-yml", "zerops.yml", "Per-service build and run configuration: zerops: list of setup entries with build and run sections."},
+	{"zerops://docs/config/import-yml", "import.yml", "Declarative service creation: services: list with hostname, type and mode (HA or NON_HA)."},
+	{"zerops://docs/networking/subdomain", "Zerops subdomain", "Public *.zerops.app subdomain for HTTP services; enable it once the service is ACTIVE."},
+	{"zerops://docs/config/env-variables", "Environment variables", "Service and project env variables. Changes apply after a restart; reference others with ${hostname_key}."},
+}
+
+type searchResult struct {
+	URI     string  `json:"uri"`
+	Title   string  `json:"title"`
+	Score   float64 `json:"score"`
+	Snippet string  `json:"snippet"`
+}
+
+func search(p parsedArgs) (response, int) {
+	if uri := p.flags["--get"]; uri != "" {
+		for _, d := range docs {
+			if d.URI == uri {
+				return syncResp(map[string]string{"uri": d.URI, "title": d.Title, "content": d.Content})
+			}
+		}
+		return errResp("DOCUMENT_NOT_FOUND", fmt.Sprintf("Document %s not found", uri), "Search first and use a returned uri")
+	}
+	if len(p.positional) == 0 {
+		return errResp("INVALID_ARGS", "search query is required", "")
+	}
+	terms := strings.Fields(strings.ToLower(strings.Join(p.positional, " ")))
+
+	results := []searchResult{}
+	for _, d := range docs {
+		text := strings.ToLower(d.Title + " " + d.Content)
+		matched := 0
+		for _, t := range terms {
+			if strings.Contains(text, t) {
+				matched++
+			}
+		}
+		if matched > 0 {
+			results = append(results, searchResult{URI: d.URI, Title: d.Title, Score: float64(matched) / float64(len(terms)), Snippet: d.Content})
+		}
+	}
+	slices.SortStableFunc(results, func(a, b searchResult) int { return cmp.Compare(b.Score, a.Score) })
+	if limit := p.limit(5); len(results) > limit {
+		results = results[:limit]
+	}
+	data := map[string]any{"query": strings.Join(p.positional, " "), "results": results}
+	if len(results) > 0 {
+		data["topResult"] = results[0]
+	}
+	return syncResp(data)
+}
@@ -0,0 +1,346 @@
+// Package fakezerops implements stateful fake zaia and zcli CLIs for
+// hermetic end-to-end tests. A simulated project (services, env vars,
+// subdomains, processes) lives in a JSON state file; every invocation
+// loads it under a lock file, runs one command and saves it back.
+//
+// Processes advance one step per CLI invocation: PENDING → RUNNING →
+// FINISHED, and their effect (service started, env var set, ...) applies
+// when they finish.
+package fakezerops
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// StateEnv names the variable holding the state file path.
+const StateEnv = "ZAIA_FAKE_STATE"
+
+// UnauthenticatedEnv, when set to "1", makes every zaia command except
+// version fail with AUTH_REQUIRED, and `zcli project list` fail like an
+// unauthenticated zcli.
+const UnauthenticatedEnv = "ZAIA_FAKE_UNAUTHENTICATED"
+
+// Version is reported by `zaia version` and `zcli version`.
+const Version = "0.0.0-fake"
+
+const (
+	lockWait  = 10 * time.Second
+	lockStale = 30 * time.Second
+)
+
+// Process statuses.
+const (
+	StatusPending  = "PENDING"
+	StatusRunning  = "RUNNING"
+	StatusFinished = "FINISHED"
+	StatusFailed   = "FAILED"
+	StatusCanceled = "CANCELED"
+)
+
+// Service statuses.
+const (
+	ServiceCreating      = "CREATING"
+	ServiceActive        = "ACTIVE"
+	ServiceReadyToDeploy = "READY_TO_DEPLOY"
+	ServiceStopped       = "STOPPED"
+	ServiceDeleting      = "DELETING"
+)
+
+// State is the simulated project.
+type State struct {
+	Project     Project           `json:"project"`
+	Services    []*Service        `json:"services"`
+	ProjectEnvs map[string]string `json:"projectEnvs,omitempty"`
+	Processes   []*Process        `json:"processes"`
+	NextID      int               `json:"nextId"`
+}
+
+// Project is the simulated Zerops project.
+type Project struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+// Service is one simulated service.
+type Service struct {
+	ID           string            `json:"serviceId"`
+	Hostname     string            `json:"hostname"`
+	Type         string            `json:"type"`
+	Mode         string            `json:"mode,omitempty"`
+	Status       string            `json:"status"`
+	Deployed     bool              `json:"deployed,omitempty"`
+	SubdomainURL string            `json:"subdomainUrl,omitempty"`
+	Envs         map[string]string `json:"envs,omitempty"`
+	Logs         []LogEntry        `json:"logs,omitempty"`
+}
+
+// LogEntry is one runtime log line of a service.
+type LogEntry struct {
+	Time     string `json:"timestamp"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// Process is one simulated async operation.
+type Process struct {
+	ID              string `json:"processId"`
+	Action          string `json:"actionName"`
+	ServiceHostname string `json:"serviceHostname,omitempty"`
+	Status          string `json:"status"`
+	Created         string `json:"created"`
+	Finished        string `json:"finished,omitempty"`
+	FailReason      string `json:"failReason,omitempty"`
+	Effect          Effect `json:"effect"`
+}
+
+// processView is the process as reported by the CLI; the effect is internal.
+type processView struct {
+	ID              string `json:"processId"`
+	Action          string `json:"actionName"`
+	ServiceHostname string `json:"serviceHostname,omitempty"`
+	Status          string `json:"status"`
+	Created         string `json:"created"`
+	Finished        string `json:"finished,omitempty"`
+	FailReason      string `json:"failReason,omitempty"`
+}
+
+func (p *Process) view() processView {
+	return processView{
+		ID:              p.ID,
+		Action:          p.Action,
+		ServiceHostname: p.ServiceHostname,
+		Status:          p.Status,
+		Created:         p.Created,
+		Finished:        p.Finished,
+		FailReason:      p.FailReason,
+	}
+}
+
+// Effect is applied to the state when a process finishes.
+type Effect struct {
+	Kind     string            `json:"kind"`
+	Hostname string            `json:"hostname,omitempty"`
+	Project  bool              `json:"project,omitempty"`
+	Set      map[string]string `json:"set,omitempty"`
+	Unset    []string          `json:"unset,omitempty"`
+}
+
+func newState() *State {
+	return &State{
+		Project: Project{ID: "proj-fake", Name: "fake-project", Status: ServiceActive},
+	}
+}
+
+// StatePath returns the state file path from StateEnv, defaulting to
+// zaia-fake-state.json in the temp directory.
+func StatePath() string {
+	if p := os.Getenv(StateEnv); p != "" {
+		return p
+	}
+	return filepath.Join(os.TempDir(), "zaia-fake-state.json")
+}
+
+// update loads the state at path under a lock, calls fn and saves the
+// state back if fn returns save=true.
+func update(path string, fn func(*State) (save bool)) error {
+	unlock, err := lock(path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	st := newState()
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return err
+	default:
+		if err := json.Unmarshal(data, st); err != nil {
+			return fmt.Errorf("corrupt state file %s: %w", path, err)
+		}
+	}
+
+	if !fn(st) {
+		return nil
+	}
+	out, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, out, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// lock acquires an exclusive lock file, breaking locks older than lockStale.
+func lock(path string) (unlock func(), err error) {
+	deadline := time.Now().Add(lockWait)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			_ = f.Close()
+			return func() { _ = os.Remove(path) }, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, err
+		}
+		if info, statErr := os.Stat(path); statErr == nil && time.Since(info.ModTime()) > lockStale {
+			_ = os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for state lock %s", path)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func (st *State) newID(prefix string) string {
+	st.NextID++
+	return fmt.Sprintf("%s-%04d", prefix, st.NextID)
+}
+
+func (st *State) service(hostname string) *Service {
+	for _, s := range st.Services {
+		if s.Hostname == hostname {
+			return s
+		}
+	}
+	return nil
+}
+
+func (st *State) serviceByID(id string) *Service {
+	for _, s := range st.Services {
+		if s.ID == id {
+			return s
+		}
+	}
+	return nil
+}
+
+func (st *State) process(id string) *Process {
+	for _, p := range st.Processes {
+		if p.ID == id {
+			return p
+		}
+	}
+	return nil
+}
+
+func (st *State) startProcess(action, hostname string, effect Effect) *Process {
+	p := &Process{
+		ID:              st.newID("proc"),
+		Action:          action,
+		ServiceHostname: hostname,
+		Status:          StatusPending,
+		Created:         now(),
+		Effect:          effect,
+	}
+	st.Processes = append(st.Processes, p)
+	return p
+}
+
+// advance moves every running process one step and applies the effects of
+// those that finish.
+func (st *State) advance() {
+	for _, p := range st.Processes {
+		switch p.Status {
+		case StatusPending:
+			p.Status = StatusRunning
+		case StatusRunning:
+			p.Status = StatusFinished
+			p.Finished = now()
+			st.apply(p)
+		}
+	}
+}
+
+func (st *State) apply(p *Process) {
+	e := p.Effect
+	svc := st.service(e.Hostname)
+	if svc == nil && !e.Project {
+		p.Status = StatusFailed
+		p.FailReason = fmt.Sprintf("service %s no longer exists", e.Hostname)
+		return
+	}
+	switch e.Kind {
+	case "create":
+		if isManaged(svc.Type) {
+			svc.Status = ServiceActive
+		} else {
+			svc.Status = ServiceReadyToDeploy
+		}
+	case "start", "restart":
+		svc.Status = ServiceActive
+	case "stop":
+		svc.Status = ServiceStopped
+	case "scale":
+	case "delete":
+		st.Services = deleteService(st.Services, svc)
+		return
+	case "env":
+		envs := &st.ProjectEnvs
+		if !e.Project {
+			envs = &svc.Envs
+		}
+		if *envs == nil {
+			*envs = map[string]string{}
+		}
+		for k, v := range e.Set {
+			(*envs)[k] = v
+		}
+		for _, k := range e.Unset {
+			delete(*envs, k)
+		}
+	case "subdomain-enable":
+		svc.SubdomainURL = fmt.Sprintf("https://%s-%s.prg1.zerops.app", svc.Hostname, st.Project.ID)
+	case "subdomain-disable":
+		svc.SubdomainURL = ""
+	}
+	if svc != nil {
+		svc.log("INFO", fmt.Sprintf("%s finished", p.Action))
+	}
+}
+
+func deleteService(services []*Service, svc *Service) []*Service {
+	out := services[:0]
+	for _, s := range services {
+		if s != svc {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func (s *Service) log(severity, msg string) {
+	s.Logs = append(s.Logs, LogEntry{Time: now(), Severity: severity, Message: msg})
+}
+
+func now() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
+
+// managedTypes are service types that start ACTIVE without a deploy.
+var managedTypes = []string{
+	"postgresql", "mariadb", "clickhouse", "valkey", "keydb", "meilisearch",
+	"elasticsearch", "typesense", "qdrant", "nats", "kafka", "object-storage", "shared-storage",
+}
+
+func isManaged(serviceType string) bool {
+	for _, t := range managedTypes {
+		if serviceType == t || len(serviceType) > len(t) && serviceType[:len(t)+1] == t+"@" {
+			return true
+		}
+	}
+	return false
+}
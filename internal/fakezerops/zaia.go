@@ -0,0 +1,463 @@
+package fakezerops
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Exit codes of the fake CLIs.
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+// boolFlags take no value; every other --flag takes the next argument.
+var boolFlags = map[string]bool{
+	"--include-envs": true,
+	"--project":      true,
+	"--confirm":      true,
+	"--dry-run":      true,
+}
+
+type parsedArgs struct {
+	positional []string
+	flags      map[string]string
+}
+
+func parseArgs(args []string) parsedArgs {
+	p := parsedArgs{flags: map[string]string{}}
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		switch {
+		case boolFlags[a]:
+			p.flags[a] = "true"
+		case strings.HasPrefix(a, "--") && i+1 < len(args):
+			p.flags[a] = args[i+1]
+			i++
+		default:
+			p.positional = append(p.positional, a)
+		}
+	}
+	return p
+}
+
+func (p parsedArgs) has(flag string) bool {
+	_, ok := p.flags[flag]
+	return ok
+}
+
+func (p parsedArgs) limit(def int) int {
+	if n, err := strconv.Atoi(p.flags["--limit"]); err == nil && n > 0 {
+		return n
+	}
+	return def
+}
+
+// response is the ZAIA JSON envelope.
+type response struct {
+	Type       string `json:"type"`
+	Status     string `json:"status,omitempty"`
+	Data       any    `json:"data,omitempty"`
+	Processes  any    `json:"processes,omitempty"`
+	Code       string `json:"code,omitempty"`
+	Error      string `json:"error,omitempty"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+func syncResp(data any) (response, int) {
+	return response{Type: "sync", Status: "ok", Data: data}, exitOK
+}
+
+func asyncResp(procs ...*Process) (response, int) {
+	views := make([]processView, 0, len(procs))
+	for _, p := range procs {
+		views = append(views, p.view())
+	}
+	return response{Type: "async", Status: "initiated", Processes: views}, exitOK
+}
+
+func errResp(code, msg, suggestion string) (response, int) {
+	exit := exitError
+	if code == "INVALID_ARGS" || code == "UNKNOWN_COMMAND" {
+		exit = exitUsage
+	}
+	return response{Type: "error", Code: code, Error: msg, Suggestion: suggestion}, exit
+}
+
+func writeResp(w io.Writer, resp response) {
+	b, _ := json.Marshal(resp)
+	_, _ = fmt.Fprintf(w, "%s\n", b)
+}
+
+// RunZaia runs one fake zaia command against the state at statePath,
+// writes the JSON envelope to stdout and returns the exit code.
+func RunZaia(statePath string, args []string, stdout, stderr io.Writer) int {
+	if len(args) > 0 && args[0] == "version" {
+		resp, code := syncResp(map[string]string{"version": Version})
+		writeResp(stdout, resp)
+		return code
+	}
+	if os.Getenv(UnauthenticatedEnv) == "1" {
+		resp, code := errResp("AUTH_REQUIRED", "not logged in", "Run: zaia login <token>")
+		writeResp(stdout, resp)
+		return code
+	}
+
+	var resp response
+	var code int
+	err := update(statePath, func(st *State) bool {
+		st.advance()
+		resp, code = zaiaCommand(st, args)
+		return true
+	})
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "fake zaia: %v\n", err)
+		resp, code = errResp("INTERNAL_ERROR", err.Error(), "")
+	}
+	writeResp(stdout, resp)
+	return code
+}
+
+func zaiaCommand(st *State, args []string) (response, int) {
+	if len(args) == 0 {
+		return errResp("UNKNOWN_COMMAND", "no command given", "Run: zaia --help")
+	}
+	p := parseArgs(args[1:])
+	switch args[0] {
+	case "discover":
+		return discover(st, p)
+	case "search":
+		return search(p)
+	case "validate":
+		return validate(p)
+	case "logs":
+		return logs(st, p)
+	case "events":
+		return events(st, p)
+	case "process":
+		return processStatus(st, p)
+	case "cancel":
+		return cancel(st, p)
+	case "start", "stop", "restart", "scale":
+		return manage(st, args[0], p)
+	case "env":
+		return env(st, p)
+	case "import":
+		return importServices(st, p)
+	case "delete":
+		return deleteSvc(st, p)
+	case "subdomain":
+		return subdomain(st, p)
+	default:
+		return errResp("UNKNOWN_COMMAND", fmt.Sprintf("unknown command %q", args[0]), "Run: zaia --help")
+	}
+}
+
+func serviceNotFound(hostname string) (response, int) {
+	return errResp("SERVICE_NOT_FOUND", fmt.Sprintf("Service %s not found", hostname), "Use zerops_discover to list services")
+}
+
+func requireService(st *State, p parsedArgs) (*Service, response, int) {
+	hostname := p.flags["--service"]
+	if hostname == "" {
+		resp, code := errResp("INVALID_ARGS", "--service is required", "")
+		return nil, resp, code
+	}
+	svc := st.service(hostname)
+	if svc == nil {
+		resp, code := serviceNotFound(hostname)
+		return nil, resp, code
+	}
+	return svc, response{}, 0
+}
+
+type serviceView struct {
+	ID           string            `json:"serviceId"`
+	Hostname     string            `json:"hostname"`
+	Type         string            `json:"type"`
+	Status       string            `json:"status"`
+	Mode         string            `json:"mode,omitempty"`
+	SubdomainURL string            `json:"subdomainUrl,omitempty"`
+	Envs         map[string]string `json:"envs,omitempty"`
+}
+
+func discover(st *State, p parsedArgs) (response, int) {
+	services := make([]serviceView, 0, len(st.Services))
+	for _, s := range st.Services {
+		if host := p.flags["--service"]; host != "" && s.Hostname != host {
+			continue
+		}
+		v := serviceView{ID: s.ID, Hostname: s.Hostname, Type: s.Type, Status: s.Status, Mode: s.Mode, SubdomainURL: s.SubdomainURL}
+		if p.has("--include-envs") {
+			v.Envs = s.Envs
+		}
+		services = append(services, v)
+	}
+	if host := p.flags["--service"]; host != "" && len(services) == 0 {
+		return serviceNotFound(host)
+	}
+	return syncResp(map[string]any{"project": st.Project, "services": services})
+}
+
+func logs(st *State, p parsedArgs) (response, int) {
+	svc, resp, code := requireService(st, p)
+	if svc == nil {
+		return resp, code
+	}
+	entries := []LogEntry{}
+	for _, e := range svc.Logs {
+		if sev := p.flags["--severity"]; sev != "" && !strings.EqualFold(e.Severity, sev) {
+			continue
+		}
+		if q := p.flags["--search"]; q != "" && !strings.Contains(e.Message, q) {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	limit := p.limit(100)
+	hasMore := len(entries) > limit
+	if hasMore {
+		entries = entries[len(entries)-limit:]
+	}
+	return syncResp(map[string]any{"entries": entries, "hasMore": hasMore})
+}
+
+func events(st *State, p parsedArgs) (response, int) {
+	out := []map[string]string{}
+	for i := len(st.Processes) - 1; i >= 0; i-- {
+		proc := st.Processes[i]
+		if host := p.flags["--service"]; host != "" && proc.ServiceHostname != host {
+			continue
+		}
+		out = append(out, map[string]string{
+			"type":            "process",
+			"action":          proc.Action,
+			"status":          proc.Status,
+			"serviceHostname": proc.ServiceHostname,
+			"processId":       proc.ID,
+			"timestamp":       proc.Created,
+		})
+		if len(out) == p.limit(50) {
+			break
+		}
+	}
+	return syncResp(map[string]any{"events": out})
+}
+
+func processStatus(st *State, p parsedArgs) (response, int) {
+	if len(p.positional) == 0 {
+		return errResp("INVALID_ARGS", "process ID is required", "")
+	}
+	proc := st.process(p.positional[0])
+	if proc == nil {
+		return errResp("PROCESS_NOT_FOUND", fmt.Sprintf("Process %s not found", p.positional[0]), "")
+	}
+	return syncResp(proc.view())
+}
+
+func cancel(st *State, p parsedArgs) (response, int) {
+	if len(p.positional) == 0 {
+		return errResp("INVALID_ARGS", "process ID is required", "")
+	}
+	proc := st.process(p.positional[0])
+	if proc == nil {
+		return errResp("PROCESS_NOT_FOUND", fmt.Sprintf("Process %s not found", p.positional[0]), "")
+	}
+	if proc.Status != StatusPending && proc.Status != StatusRunning {
+		return errResp("PROCESS_ALREADY_TERMINAL", fmt.Sprintf("Process %s is already %s", proc.ID, proc.Status), "")
+	}
+	proc.Status = StatusCanceled
+	proc.Finished = now()
+	return syncResp(proc.view())
+}
+
+func manage(st *State, action string, p parsedArgs) (response, int) {
+	svc, resp, code := requireService(st, p)
+	if svc == nil {
+		return resp, code
+	}
+	switch {
+	case svc.Status == ServiceCreating || svc.Status == ServiceDeleting:
+		return invalidState(svc, action)
+	case action != "scale" && svc.Status == ServiceReadyToDeploy:
+		return invalidState(svc, action)
+	case action == "start" && svc.Status == ServiceActive:
+		return invalidState(svc, action)
+	case (action == "stop" || action == "restart") && svc.Status == ServiceStopped:
+		return invalidState(svc, action)
+	}
+	return asyncResp(st.startProcess("service."+action, svc.Hostname, Effect{Kind: action, Hostname: svc.Hostname}))
+}
+
+func invalidState(svc *Service, action string) (response, int) {
+	return errResp("INVALID_SERVICE_STATE",
+		fmt.Sprintf("Cannot %s service %s in state %s", action, svc.Hostname, svc.Status),
+		"Check the service status with zerops_discover")
+}
+
+func env(st *State, p parsedArgs) (response, int) {
+	if len(p.positional) == 0 {
+		return errResp("INVALID_ARGS", "env action is required (get, set, delete)", "")
+	}
+	action, vars := p.positional[0], p.positional[1:]
+	project := p.has("--project")
+
+	var svc *Service
+	if !project {
+		var resp response
+		var code int
+		if svc, resp, code = requireService(st, p); svc == nil {
+			return resp, code
+		}
+	}
+	target, hostname := st.ProjectEnvs, ""
+	if svc != nil {
+		target, hostname = svc.Envs, svc.Hostname
+	}
+
+	switch action {
+	case "get":
+		keys := make([]string, 0, len(target))
+		for k := range target {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		envs := make([]map[string]string, 0, len(keys))
+		for _, k := range keys {
+			envs = append(envs, map[string]string{"key": k, "value": target[k]})
+		}
+		data := map[string]any{"envs": envs}
+		if hostname != "" {
+			data["serviceHostname"] = hostname
+		}
+		return syncResp(data)
+	case "set":
+		set := map[string]string{}
+		for _, kv := range vars {
+			k, v, ok := strings.Cut(kv, "=")
+			if !ok || k == "" {
+				return errResp("INVALID_ARGS", fmt.Sprintf("invalid variable %q, expected KEY=value", kv), "")
+			}
+			set[k] = v
+		}
+		if len(set) == 0 {
+			return errResp("INVALID_ARGS", "no variables given", "")
+		}
+		return asyncResp(st.startProcess("env.set", hostname, Effect{Kind: "env", Hostname: hostname, Project: project, Set: set}))
+	case "delete":
+		if len(vars) == 0 {
+			return errResp("INVALID_ARGS", "no variables given", "")
+		}
+		return asyncResp(st.startProcess("env.delete", hostname, Effect{Kind: "env", Hostname: hostname, Project: project, Unset: vars}))
+	default:
+		return errResp("INVALID_ARGS", fmt.Sprintf("unknown env action %q", action), "")
+	}
+}
+
+func importServices(st *State, p parsedArgs) (response, int) {
+	content, resp, code := readContent(p)
+	if content == "" {
+		return resp, code
+	}
+	services, err := parseImport(content)
+	if err != nil {
+		return errResp("INVALID_IMPORT_YML", err.Error(), "Validate with zerops_validate type=import.yml")
+	}
+	for _, s := range services {
+		if st.service(s.Hostname) != nil {
+			return errResp("SERVICE_ALREADY_EXISTS", fmt.Sprintf("Service %s already exists", s.Hostname), "")
+		}
+	}
+	if p.has("--dry-run") {
+		return syncResp(map[string]any{"dryRun": true, "valid": true, "services": services})
+	}
+	procs := make([]*Process, 0, len(services))
+	for _, s := range services {
+		svc := &Service{ID: st.newID("svc"), Hostname: s.Hostname, Type: s.Type, Mode: s.Mode, Status: ServiceCreating}
+		st.Services = append(st.Services, svc)
+		procs = append(procs, st.startProcess("service.create", svc.Hostname, Effect{Kind: "create", Hostname: svc.Hostname}))
+	}
+	return asyncResp(procs...)
+}
+
+func deleteSvc(st *State, p parsedArgs) (response, int) {
+	svc, resp, code := requireService(st, p)
+	if svc == nil {
+		return resp, code
+	}
+	if !p.has("--confirm") {
+		return errResp("CONFIRM_REQUIRED", "delete requires --confirm", "")
+	}
+	if svc.Status == ServiceDeleting {
+		return invalidState(svc, "delete")
+	}
+	svc.Status = ServiceDeleting
+	return asyncResp(st.startProcess("service.delete", svc.Hostname, Effect{Kind: "delete", Hostname: svc.Hostname}))
+}
+
+func subdomain(st *State, p parsedArgs) (response, int) {
+	if len(p.positional) == 0 || (p.positional[0] != "enable" && p.positional[0] != "disable") {
+		return errResp("INVALID_ARGS", "subdomain action must be enable or disable", "")
+	}
+	action := p.positional[0]
+	svc, resp, code := requireService(st, p)
+	if svc == nil {
+		return resp, code
+	}
+	if isManaged(svc.Type) || svc.Status != ServiceActive {
+		return invalidState(svc, action+" subdomain of")
+	}
+	return asyncResp(st.startProcess("service.subdomain."+action, svc.Hostname, Effect{Kind: "subdomain-" + action, Hostname: svc.Hostname}))
+}
+
+func validate(p parsedArgs) (response, int) {
+	content, resp, code := readContent(p)
+	if content == "" {
+		return resp, code
+	}
+	typ := p.flags["--type"]
+	if typ == "" {
+		typ = "zerops.yml"
+		if strings.Contains(content, "services:") {
+			typ = "import.yml"
+		}
+	}
+	switch typ {
+	case "import.yml":
+		services, err := parseImport(content)
+		if err != nil {
+			return errResp("VALIDATION_FAILED", err.Error(), "")
+		}
+		return syncResp(map[string]any{"valid": true, "type": typ, "services": len(services)})
+	case "zerops.yml":
+		if !strings.Contains(content, "zerops:") {
+			return errResp("VALIDATION_FAILED", "zerops.yml must have a top-level zerops: section", "")
+		}
+		return syncResp(map[string]any{"valid": true, "type": typ})
+	default:
+		return errResp("INVALID_ARGS", fmt.Sprintf("unknown type %q (zerops.yml or import.yml)", typ), "")
+	}
+}
+
+// readContent returns --content, or the content of --file.
+func readContent(p parsedArgs) (string, response, int) {
+	if c := p.flags["--content"]; c != "" {
+		return c, response{}, 0
+	}
+	if f := p.flags["--file"]; f != "" {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			resp, code := errResp("FILE_NOT_FOUND", err.Error(), "")
+			return "", resp, code
+		}
+		return string(b), response{}, 0
+	}
+	resp, code := errResp("INVALID_ARGS", "--content or --file is required", "")
+	return "", resp, code
+}
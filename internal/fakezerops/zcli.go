@@ -0,0 +1,113 @@
+package fakezerops
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// PushDelayEnv sets the pause between `zcli push` progress lines
+// (a Go duration, default none) so streaming can be observed.
+const PushDelayEnv = "ZAIA_FAKE_PUSH_DELAY"
+
+// RunZcli runs one fake zcli command. `version` and `project list` print
+// plain text like the real zcli; `push` prints progress to stderr and a
+// JSON envelope to stdout.
+func RunZcli(statePath string, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		_, _ = fmt.Fprintln(stderr, "usage: zcli <command>")
+		return exitUsage
+	}
+	switch args[0] {
+	case "version":
+		_, _ = fmt.Fprintf(stdout, "zcli version v%s\n", Version)
+		return exitOK
+	case "project":
+		if os.Getenv(UnauthenticatedEnv) == "1" {
+			_, _ = fmt.Fprintln(stderr, "Error: unauthenticated user, run zcli login <token>")
+			return exitError
+		}
+		_, _ = fmt.Fprintln(stdout, "fake-project  ACTIVE")
+		return exitOK
+	case "push":
+		resp, code := push(statePath, parseArgs(args[1:]), stderr)
+		writeResp(stdout, resp)
+		return code
+	default:
+		_, _ = fmt.Fprintf(stderr, "unknown command %q\n", args[0])
+		return exitUsage
+	}
+}
+
+func push(statePath string, p parsedArgs, progress io.Writer) (response, int) {
+	serviceID := p.flags["--serviceId"]
+	if serviceID == "" {
+		return errResp("INVALID_ARGS", "--serviceId is required", "Use zerops_discover to find the service ID")
+	}
+	workingDir := p.flags["--workingDir"]
+	if workingDir == "" {
+		workingDir = "."
+	}
+	if _, err := os.Stat(filepath.Join(workingDir, "zerops.yml")); err != nil {
+		return errResp("ZEROPS_YML_NOT_FOUND", fmt.Sprintf("zerops.yml not found in %s", workingDir), "Create zerops.yml or set workingDir")
+	}
+
+	var svc Service
+	var resp response
+	var code int
+	err := update(statePath, func(st *State) bool {
+		s := st.serviceByID(serviceID)
+		if s == nil {
+			s = st.service(serviceID)
+		}
+		switch {
+		case s == nil:
+			resp, code = errResp("SERVICE_NOT_FOUND", fmt.Sprintf("Service %s not found", serviceID), "Use zerops_discover to find the service ID")
+			return false
+		case isManaged(s.Type):
+			resp, code = invalidState(s, "deploy to")
+			return false
+		}
+		svc = *s
+		return false
+	})
+	if err != nil {
+		return errResp("INTERNAL_ERROR", err.Error(), "")
+	}
+	if resp.Type != "" {
+		return resp, code
+	}
+
+	delay, _ := time.ParseDuration(os.Getenv(PushDelayEnv))
+	for _, step := range []string{
+		"Uploading application files",
+		"Building application",
+		"Build finished",
+		"Deploying to " + svc.Hostname,
+	} {
+		_, _ = fmt.Fprintln(progress, step)
+		time.Sleep(delay)
+	}
+
+	err = update(statePath, func(st *State) bool {
+		s := st.serviceByID(svc.ID)
+		if s == nil {
+			resp, code = errResp("SERVICE_NOT_FOUND", fmt.Sprintf("Service %s was deleted during deploy", svc.Hostname), "")
+			return false
+		}
+		s.Deployed = true
+		s.Status = ServiceActive
+		s.log("INFO", "application deployed")
+		return true
+	})
+	if err != nil {
+		return errResp("INTERNAL_ERROR", err.Error(), "")
+	}
+	if resp.Type != "" {
+		return resp, code
+	}
+	_, _ = fmt.Fprintln(progress, "Deploy finished")
+	return syncResp(map[string]any{"deployed": true, "serviceId": svc.ID, "hostname": svc.Hostname})
+}
@@ -0,0 +1,130 @@
+// Package metrics collects counters and latency histograms for tool calls
+// and CLI subprocess executions, rendered in the Prometheus text format.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/zeropsio/zaia-mcp/internal/executor"
+)
+
+// ContentType is the MIME type of the Prometheus text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Tool call outcomes.
+const (
+	ToolSuccess = "success" // result without isError
+	ToolError   = "error"   // result with isError (validation or CLI error)
+	ToolFailure = "failure" // protocol-level error, no result
+)
+
+// CLI execution outcomes.
+const (
+	CLISuccess     = "success"      // exit code 0
+	CLIError       = "cli_error"    // non-zero exit; code label holds the CLI error code
+	CLIExecFailure = "exec_failure" // could not run (binary missing, I/O error)
+	CLITimeout     = "timeout"      // killed after its timeout
+	CLICanceled    = "canceled"     // caller canceled
+	CLICached      = "cached"       // served by the cache, no subprocess
+)
+
+// durationBuckets spans fast cached lookups up to long zcli pushes (seconds).
+var durationBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 900}
+
+// Metrics holds the server's metric families.
+type Metrics struct {
+	reg          *Registry
+	toolCalls    *CounterVec
+	toolDuration *HistogramVec
+	cliRuns      *CounterVec
+	cliDuration  *HistogramVec
+}
+
+// New creates a Metrics with all families registered.
+func New() *Metrics {
+	reg := NewRegistry()
+	return &Metrics{
+		reg: reg,
+		toolCalls: reg.Counter("zaia_mcp_tool_calls_total",
+			"MCP tool calls by tool and outcome (success, error, failure).", "tool", "outcome"),
+		toolDuration: reg.Histogram("zaia_mcp_tool_call_duration_seconds",
+			"MCP tool call latency.", durationBuckets, "tool"),
+		cliRuns: reg.Counter("zaia_mcp_cli_executions_total",
+			"CLI executions by binary, subcommand, outcome and CLI error code.", "binary", "command", "outcome", "code"),
+		cliDuration: reg.Histogram("zaia_mcp_cli_execution_duration_seconds",
+			"CLI execution latency by binary and subcommand.", durationBuckets, "binary", "command"),
+	}
+}
+
+// ObserveTool records one tool call.
+func (m *Metrics) ObserveTool(tool, outcome string, d time.Duration) {
+	m.toolCalls.Inc(tool, outcome)
+	m.toolDuration.Observe(d.Seconds(), tool)
+}
+
+// ObserveCLI records one CLI execution.
+func (m *Metrics) ObserveCLI(binary string, args []string, result *executor.Result, err error, d time.Duration) {
+	command := ""
+	if len(args) > 0 {
+		command = args[0]
+	}
+	outcome, code := cliOutcome(result, err)
+	m.cliRuns.Inc(binary, command, outcome, code)
+	if outcome != CLICached {
+		m.cliDuration.Observe(d.Seconds(), binary, command)
+	}
+}
+
+func cliOutcome(result *executor.Result, err error) (outcome, code string) {
+	var timeoutErr *executor.TimeoutError
+	switch {
+	case errors.As(err, &timeoutErr):
+		return CLITimeout, ""
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		return CLICanceled, ""
+	case err != nil:
+		return CLIExecFailure, ""
+	case result == nil:
+		return CLIExecFailure, ""
+	case !result.CachedAt.IsZero():
+		return CLICached, ""
+	case result.ExitCode != 0:
+		code := executor.ErrorCode(result)
+		if code == "" {
+			code = "UNKNOWN"
+		}
+		return CLIError, code
+	default:
+		return CLISuccess, ""
+	}
+}
+
+// WrapExecutor returns an executor recording every CLI execution. It is an
+// executor.Middleware.
+func (m *Metrics) WrapExecutor(next executor.Executor) executor.Executor {
+	return executor.Intercept(func(ctx context.Context, binary string, args []string, run executor.RunFunc) (*executor.Result, error) {
+		start := time.Now()
+		result, err := run(ctx, args...)
+		m.ObserveCLI(binary, args, result, err, time.Since(start))
+		return result, err
+	})(next)
+}
+
+// Text returns the current metrics in the Prometheus text format.
+func (m *Metrics) Text() string {
+	var b strings.Builder
+	_ = m.reg.WriteText(&b)
+	return b.String()
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		_ = m.reg.WriteText(w)
+	})
+}
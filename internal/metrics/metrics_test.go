@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zeropsio/zaia-mcp/internal/executor"
+)
+
+func TestCLIOutcome(t *testing.T) {
+	cached := executor.SyncResult(`{}`)
+	cached.CachedAt = time.Now()
+
+	tests := []struct {
+		name    string
+		result  *executor.Result
+		err     error
+		outcome string
+		code    string
+	}{
+		{"success", executor.SyncResult(`{}`), nil, CLISuccess, ""},
+		{"cli error", executor.ErrorResult("SERVICE_NOT_FOUND", "nope", "", 1), nil, CLIError, "SERVICE_NOT_FOUND"},
+		{"unparsable error", &executor.Result{Stdout: []byte("boom"), ExitCode: 2}, nil, CLIError, "UNKNOWN"},
+		{"timeout", &executor.Result{}, &executor.TimeoutError{Command: "zaia logs"}, CLITimeout, ""},
+		{"canceled", &executor.Result{}, context.Canceled, CLICanceled, ""},
+		{"exec failure", nil, errors.New("exec: not found"), CLIExecFailure, ""},
+		{"cached", cached, nil, CLICached, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outcome, code := cliOutcome(tt.result, tt.err)
+			if outcome != tt.outcome || code != tt.code {
+				t.Errorf("got (%q, %q), want (%q, %q)", outcome, code, tt.outcome, tt.code)
+			}
+		})
+	}
+}
+
+func TestWrapExecutor(t *testing.T) {
+	m := New()
+	mock := executor.NewMockExecutor().
+		WithZaiaResponse("discover", executor.SyncResult(`{}`)).
+		WithZaiaResponse("restart --service api", executor.ErrorResult("SERVICE_NOT_FOUND", "nope", "", 1)).
+		WithZcliResponse("push", executor.SyncResult(`{}`))
+	exec := executor.Chain(mock, m.WrapExecutor)
+
+	_, _ = exec.RunZaia(t.Context(), "discover")
+	_, _ = exec.RunZaia(t.Context(), "discover")
+	_, _ = exec.RunZaia(t.Context(), "restart", "--service", "api")
+	_, _ = exec.RunZcli(t.Context(), "push")
+
+	text := m.Text()
+	for _, want := range []string{
+		`zaia_mcp_cli_executions_total{binary="zaia",command="discover",outcome="success",code=""} 2`,
+		`zaia_mcp_cli_executions_total{binary="zaia",command="restart",outcome="cli_error",code="SERVICE_NOT_FOUND"} 1`,
+		`zaia_mcp_cli_executions_total{binary="zcli",command="push",outcome="success",code=""} 1`,
+		`zaia_mcp_cli_execution_duration_seconds_count{binary="zaia",command="discover"} 2`,
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %q in:\n%s", want, text)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	m := New()
+	m.ObserveTool("zerops_discover", ToolSuccess, 30*time.Millisecond)
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	if ct := rec.Header().Get("Content-Type"); ct != ContentType {
+		t.Errorf("Content-Type: got %q", ct)
+	}
+	body, _ := io.ReadAll(rec.Body)
+	if !strings.Contains(string(body), `zaia_mcp_tool_calls_total{tool="zerops_discover",outcome="success"} 1`) {
+		t.Errorf("body:\n%s", body)
+	}
+}
@@ -0,0 +1,177 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Registry holds metric families and renders them in the Prometheus text
+// exposition format (version 0.0.4). It covers the small subset this
+// server needs: labeled counters and histograms.
+type Registry struct {
+	mu       sync.Mutex
+	families []*family
+}
+
+type family struct {
+	name    string
+	help    string
+	typ     string // "counter" or "histogram"
+	labels  []string
+	buckets []float64 // histograms only
+	series  map[string]*series
+}
+
+type series struct {
+	values []string
+	count  float64  // counter value, or histogram observation count
+	sum    float64  // histograms only
+	counts []uint64 // histograms only; per bucket, non-cumulative
+}
+
+// CounterVec is a counter partitioned by label values.
+type CounterVec struct {
+	r *Registry
+	f *family
+}
+
+// HistogramVec is a histogram partitioned by label values.
+type HistogramVec struct {
+	r *Registry
+	f *family
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Counter registers a counter family.
+func (r *Registry) Counter(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{r: r, f: r.add(name, help, "counter", nil, labels)}
+}
+
+// Histogram registers a histogram family with the given upper bucket bounds
+// (ascending; +Inf is implicit).
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	return &HistogramVec{r: r, f: r.add(name, help, "histogram", buckets, labels)}
+}
+
+func (r *Registry) add(name, help, typ string, buckets []float64, labels []string) *family {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f := &family{name: name, help: help, typ: typ, labels: labels, buckets: buckets, series: map[string]*series{}}
+	r.families = append(r.families, f)
+	return f
+}
+
+// Inc adds 1 to the series with the given label values.
+func (c *CounterVec) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Add adds v to the series with the given label values.
+func (c *CounterVec) Add(v float64, values ...string) {
+	c.r.mu.Lock()
+	defer c.r.mu.Unlock()
+	c.f.get(values).count += v
+}
+
+// Observe records v in the series with the given label values.
+func (h *HistogramVec) Observe(v float64, values ...string) {
+	h.r.mu.Lock()
+	defer h.r.mu.Unlock()
+	s := h.f.get(values)
+	s.count++
+	s.sum += v
+	for i, bound := range h.f.buckets {
+		if v <= bound {
+			s.counts[i]++
+			return
+		}
+	}
+}
+
+// get returns the series for values, creating it. Caller must hold the lock.
+func (f *family) get(values []string) *series {
+	if len(values) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", f.name, len(f.labels), len(values)))
+	}
+	key := strings.Join(values, "\xff")
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{values: slices.Clone(values)}
+		if f.typ == "histogram" {
+			s.counts = make([]uint64, len(f.buckets))
+		}
+		f.series[key] = s
+	}
+	return s
+}
+
+// WriteText writes all families in the Prometheus text format. Series are
+// sorted by label values for stable output.
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var b strings.Builder
+	for _, f := range r.families {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", f.name, escapeHelp(f.help), f.name, f.typ)
+		keys := make([]string, 0, len(f.series))
+		for k := range f.series {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			s := f.series[k]
+			if f.typ == "counter" {
+				fmt.Fprintf(&b, "%s%s %s\n", f.name, labelString(f.labels, s.values, "", ""), formatFloat(s.count))
+				continue
+			}
+			var cumulative uint64
+			for i, bound := range f.buckets {
+				cumulative += s.counts[i]
+				fmt.Fprintf(&b, "%s_bucket%s %d\n", f.name, labelString(f.labels, s.values, "le", formatFloat(bound)), cumulative)
+			}
+			fmt.Fprintf(&b, "%s_bucket%s %s\n", f.name, labelString(f.labels, s.values, "le", "+Inf"), formatFloat(s.count))
+			fmt.Fprintf(&b, "%s_sum%s %s\n", f.name, labelString(f.labels, s.values, "", ""), formatFloat(s.sum))
+			fmt.Fprintf(&b, "%s_count%s %s\n", f.name, labelString(f.labels, s.values, "", ""), formatFloat(s.count))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func labelString(names, values []string, extraName, extraValue string) string {
+	if len(names) == 0 && extraName == "" {
+		return ""
+	}
+	parts := make([]string, 0, len(names)+1)
+	for i, name := range names {
+		parts = append(parts, name+`="`+escapeLabel(values[i])+`"`)
+	}
+	if extraName != "" {
+		parts = append(parts, extraName+`="`+extraValue+`"`)
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegistry_WriteText(t *testing.T) {
+	reg := NewRegistry()
+	c := reg.Counter("calls_total", "Calls.", "tool")
+	h := reg.Histogram("latency_seconds", "Latency.", []float64{0.1, 1}, "tool")
+
+	c.Inc("b")
+	c.Inc("a")
+	c.Add(2, "a")
+	h.Observe(0.05, "a")
+	h.Observe(0.5, "a")
+	h.Observe(5, "a")
+
+	var b strings.Builder
+	if err := reg.WriteText(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP calls_total Calls.
+# TYPE calls_total counter
+calls_total{tool="a"} 3
+calls_total{tool="b"} 1
+# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{tool="a",le="0.1"} 1
+latency_seconds_bucket{tool="a",le="1"} 2
+latency_seconds_bucket{tool="a",le="+Inf"} 3
+latency_seconds_sum{tool="a"} 5.55
+latency_seconds_count{tool="a"} 3
+`
+	if got := b.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRegistry_EscapesLabels(t *testing.T) {
+	reg := NewRegistry()
+	reg.Counter("x_total", "X.", "v").Inc("a\"b\\c\nd")
+	var b strings.Builder
+	_ = reg.WriteText(&b)
+	if !strings.Contains(b.String(), `x_total{v="a\"b\\c\nd"} 1`) {
+		t.Errorf("label not escaped: %s", b.String())
+	}
+}
@@ -0,0 +1,263 @@
+// Package prompts provides parameterized MCP prompts for common Zerops
+// workflows. Each prompt spells out the tool calls of the workflow and
+// embeds the most relevant zerops://docs document.
+package prompts
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/zeropsio/zaia-mcp/internal/executor"
+)
+
+// Prompt names.
+const (
+	AddDatabase      = "add-database"
+	DebugService     = "debug-service"
+	FirstDeploy      = "first-deploy"
+	ScaleForLoadTest = "scale-for-load-test"
+)
+
+// Default argument values.
+const (
+	defaultDatabaseType     = "postgresql@16"
+	defaultDatabaseHostname = "db"
+	defaultMinContainers    = "2"
+	defaultMaxContainers    = "6"
+)
+
+// workflow is one prompt: its definition, the tools its steps call and
+// how its text and docs query are built from the arguments.
+type workflow struct {
+	prompt *mcp.Prompt
+	// tools lists the tools the steps call; the prompt is registered only
+	// when all of them are.
+	tools []string
+	// docsQuery is the zerops_knowledge query whose top document is embedded.
+	docsQuery func(args map[string]string) string
+	text      func(args map[string]string) string
+}
+
+var workflows = []workflow{
+	{
+		prompt: &mcp.Prompt{
+			Name:        AddDatabase,
+			Title:       "Add a managed database to my app",
+			Description: "Create a managed database service and connect an existing app service to it.",
+			Arguments: []*mcp.PromptArgument{
+				{Name: "serviceHostname", Description: "App service that will use the database", Required: true},
+				{Name: "databaseType", Description: "Database service type (default " + defaultDatabaseType + ")"},
+				{Name: "databaseHostname", Description: "Hostname of the new database service (default " + defaultDatabaseHostname + ")"},
+			},
+		},
+		tools: []string{"zerops_discover", "zerops_knowledge", "zerops_validate", "zerops_import", "zerops_process", "zerops_env", "zerops_manage"},
+		docsQuery: func(args map[string]string) string {
+			return typeName(cmp.Or(args["databaseType"], defaultDatabaseType)) + " connection"
+		},
+		text: func(args map[string]string) string {
+			app := args["serviceHostname"]
+			dbType := cmp.Or(args["databaseType"], defaultDatabaseType)
+			db := cmp.Or(args["databaseHostname"], defaultDatabaseHostname)
+			return fmt.Sprintf(`Add a managed %[2]s database named %[3]q to the app service %[1]q.
+
+1. zerops_discover — confirm %[1]q exists and no service is named %[3]q yet.
+2. Write an import.yml with only a services: list (no project: section):
+     services:
+       - hostname: %[3]s
+         type: %[2]s
+         mode: NON_HA
+3. zerops_validate type=import.yml with that content, then zerops_import dryRun=true.
+4. zerops_import with the content; poll zerops_process with the returned processId until FINISHED.
+5. zerops_discover service=%[3]s includeEnvs=true — note the connection variables (e.g. %[3]s_connectionString).
+6. zerops_env action=set serviceHostname=%[1]s with references such as DATABASE_URL=${%[3]s_connectionString}; poll zerops_process.
+7. zerops_manage action=restart serviceHostname=%[1]s so the app picks up the variables; poll zerops_process.
+
+Ask before step 4; it creates a billable service.`, app, dbType, db)
+		},
+	},
+	{
+		prompt: &mcp.Prompt{
+			Name:        DebugService,
+			Title:       "Debug a failing service",
+			Description: "Find out why a service fails: status, recent events, error and build logs, then propose a fix.",
+			Arguments: []*mcp.PromptArgument{
+				{Name: "serviceHostname", Description: "Service to debug", Required: true},
+				{Name: "since", Description: "Log time range (default 1h)"},
+			},
+		},
+		tools:     []string{"zerops_discover", "zerops_events", "zerops_logs", "zerops_knowledge"},
+		docsQuery: func(map[string]string) string { return "troubleshooting logs" },
+		text: func(args map[string]string) string {
+			svc, since := args["serviceHostname"], cmp.Or(args["since"], "1h")
+			return fmt.Sprintf(`Debug the failing service %[1]q. Diagnose first; change nothing without asking.
+
+1. zerops_discover service=%[1]s includeEnvs=true fresh=true — check status, type and env variables.
+2. zerops_events serviceHostname=%[1]s — look for FAILED processes or deploys and note any buildId.
+3. zerops_logs serviceHostname=%[1]s severity=error since=%[2]s — runtime errors.
+4. If a deploy failed: zerops_logs serviceHostname=%[1]s buildId=<buildId> — build output.
+5. zerops_knowledge with the key error message to find the documented cause.
+6. Summarize the root cause and propose a fix (zerops.yml change, env variable, scaling or restart).`, svc, since)
+		},
+	},
+	{
+		prompt: &mcp.Prompt{
+			Name:        FirstDeploy,
+			Title:       "First deploy of this repository",
+			Description: "Create a runtime service for a repository, write its zerops.yml and deploy it.",
+			Arguments: []*mcp.PromptArgument{
+				{Name: "workingDir", Description: "Repository directory (default current directory)"},
+				{Name: "serviceHostname", Description: "Runtime service to deploy to (created if missing; default app)"},
+				{Name: "runtime", Description: "Runtime service type, e.g. nodejs@22 or go@1 (default: detect from the repository)"},
+			},
+		},
+		tools: []string{"zerops_discover", "zerops_knowledge", "zerops_validate", "zerops_import", "zerops_process", "zerops_deploy", "zerops_subdomain"},
+		docsQuery: func(args map[string]string) string {
+			return strings.TrimSpace(typeName(args["runtime"]) + " zerops.yml")
+		},
+		text: func(args map[string]string) string {
+			dir, svc := cmp.Or(args["workingDir"], "."), cmp.Or(args["serviceHostname"], "app")
+			runtime := args["runtime"]
+			if runtime == "" {
+				runtime = "the runtime detected from the repository (package.json → nodejs, go.mod → go, ...)"
+			}
+			return fmt.Sprintf(`Deploy the repository in %[1]q to Zerops for the first time as service %[2]q using %[3]s.
+
+1. zerops_discover — check whether %[2]q exists.
+2. If it does not: write an import.yml (services: list only) with hostname %[2]s and the runtime type,
+   zerops_validate type=import.yml, zerops_import dryRun=true, then zerops_import and poll zerops_process.
+3. zerops_knowledge "<runtime> zerops.yml" for build/run examples, then write %[1]s/zerops.yml
+   with a setup: %[2]s entry (build commands, deployFiles, run start command and ports).
+4. zerops_validate filePath=%[1]s/zerops.yml — fix every reported error.
+5. zerops_discover service=%[2]s — take its service id; zerops_deploy workingDir=%[1]s serviceId=<id>.
+6. If the deploy fails: zerops_events and zerops_logs buildId=<buildId> to see the build output.
+7. zerops_subdomain action=enable serviceHostname=%[2]s for HTTP apps, then zerops_discover for the URL.
+
+Ask before creating the service.`, dir, svc, runtime)
+		},
+	},
+	{
+		prompt: &mcp.Prompt{
+			Name:        ScaleForLoadTest,
+			Title:       "Scale for a load test",
+			Description: "Raise a service's horizontal scaling for a load test, watch it, and scale back afterwards.",
+			Arguments: []*mcp.PromptArgument{
+				{Name: "serviceHostname", Description: "Service under load", Required: true},
+				{Name: "minContainers", Description: "Minimum containers during the test (default " + defaultMinContainers + ")"},
+				{Name: "maxContainers", Description: "Maximum containers during the test (default " + defaultMaxContainers + ")"},
+			},
+		},
+		tools:     []string{"zerops_discover", "zerops_manage", "zerops_process", "zerops_events", "zerops_logs"},
+		docsQuery: func(map[string]string) string { return "scaling autoscaling containers" },
+		text: func(args map[string]string) string {
+			svc := args["serviceHostname"]
+			minC, maxC := cmp.Or(args["minContainers"], defaultMinContainers), cmp.Or(args["maxContainers"], defaultMaxContainers)
+			return fmt.Sprintf(`Prepare the service %[1]q for a load test.
+
+1. zerops_discover service=%[1]s fresh=true — record the current scaling (containers, CPU, RAM) to restore later.
+2. zerops_manage action=scale serviceHostname=%[1]s minContainers=%[2]s maxContainers=%[3]s
+   (raise minCpu/minRam too if the current minimum is low); poll zerops_process until FINISHED.
+3. zerops_discover service=%[1]s fresh=true — confirm the new scaling is active.
+4. During the test: zerops_events serviceHostname=%[1]s and zerops_logs serviceHostname=%[1]s severity=error.
+5. After the test: zerops_manage action=scale with the recorded values to scale back down.
+
+Ask before step 2 and remind the user that extra containers are billed.`, svc, minC, maxC)
+		},
+	},
+}
+
+// Register adds the prompts whose tools are all in enabledTools.
+// Embedded documents are fetched via `zaia search`.
+func Register(srv *mcp.Server, exec executor.Executor, enabledTools []string) {
+	for _, w := range workflows {
+		if !containsAll(enabledTools, w.tools) {
+			continue
+		}
+		srv.AddPrompt(w.prompt, w.handler(exec))
+	}
+}
+
+func (w workflow) handler(exec executor.Executor) mcp.PromptHandler {
+	return func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		args := map[string]string{}
+		for _, a := range w.prompt.Arguments {
+			v := strings.TrimSpace(req.Params.Arguments[a.Name])
+			if v == "" && a.Required {
+				return nil, fmt.Errorf("prompt %s: argument %s is required", w.prompt.Name, a.Name)
+			}
+			args[a.Name] = v
+		}
+
+		messages := []*mcp.PromptMessage{{Role: "user", Content: &mcp.TextContent{Text: w.text(args)}}}
+		if doc := fetchTopDoc(ctx, exec, w.docsQuery(args)); doc != nil {
+			messages = append(messages, &mcp.PromptMessage{Role: "user", Content: &mcp.EmbeddedResource{Resource: doc}})
+		}
+		return &mcp.GetPromptResult{Description: w.prompt.Description, Messages: messages}, nil
+	}
+}
+
+// fetchTopDoc returns the top search result for query as resource
+// contents, or nil if the search fails or finds nothing. Prompts stay
+// usable without docs.
+func fetchTopDoc(ctx context.Context, exec executor.Executor, query string) *mcp.ResourceContents {
+	result, err := exec.RunZaia(ctx, "search", query, "--limit", "1")
+	if err != nil || result.ExitCode != 0 {
+		return nil
+	}
+	var found struct {
+		Data struct {
+			TopResult *struct {
+				URI string `json:"uri"`
+			} `json:"topResult"`
+			Results []struct {
+				URI string `json:"uri"`
+			} `json:"results"`
+		} `json:"data"`
+	}
+	if json.Unmarshal(result.Stdout, &found) != nil {
+		return nil
+	}
+	var uri string
+	switch {
+	case found.Data.TopResult != nil:
+		uri = found.Data.TopResult.URI
+	case len(found.Data.Results) > 0:
+		uri = found.Data.Results[0].URI
+	}
+	if !strings.HasPrefix(uri, "zerops://docs/") {
+		return nil
+	}
+
+	result, err = exec.RunZaia(ctx, "search", "--get", uri)
+	if err != nil || result.ExitCode != 0 || result.StdoutOverflow != nil {
+		return nil
+	}
+	var doc struct {
+		Data struct {
+			Content string `json:"content"`
+		} `json:"data"`
+	}
+	if json.Unmarshal(result.Stdout, &doc) != nil || doc.Data.Content == "" {
+		return nil
+	}
+	return &mcp.ResourceContents{URI: uri, MIMEType: "text/markdown", Text: doc.Data.Content}
+}
+
+// typeName strips the version from a service type ("postgresql@16" → "postgresql").
+func typeName(serviceType string) string {
+	name, _, _ := strings.Cut(serviceType, "@")
+	return name
+}
+
+func containsAll(have, want []string) bool {
+	for _, w := range want {
+		if !slices.Contains(have, w) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,131 @@
+package prompts_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/zeropsio/zaia-mcp/internal/executor"
+	"github.com/zeropsio/zaia-mcp/internal/prompts"
+)
+
+var allTools = []string{
+	"zerops_discover", "zerops_logs", "zerops_validate", "zerops_knowledge", "zerops_process", "zerops_events",
+	"zerops_manage", "zerops_env", "zerops_import", "zerops_delete", "zerops_subdomain", "zerops_deploy",
+}
+
+func connect(t *testing.T, exec executor.Executor, tools []string) *mcp.ClientSession {
+	t.Helper()
+	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.0.1"}, nil)
+	prompts.Register(srv, exec, tools)
+
+	ctx := t.Context()
+	t1, t2 := mcp.NewInMemoryTransports()
+	if _, err := srv.Connect(ctx, t1, nil); err != nil {
+		t.Fatalf("server connect: %v", err)
+	}
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0.0.1"}, nil)
+	session, err := client.Connect(ctx, t2, nil)
+	if err != nil {
+		t.Fatalf("client connect: %v", err)
+	}
+	t.Cleanup(func() { session.Close() })
+	return session
+}
+
+func promptNames(t *testing.T, session *mcp.ClientSession) []string {
+	t.Helper()
+	result, err := session.ListPrompts(t.Context(), nil)
+	if err != nil {
+		t.Fatalf("ListPrompts: %v", err)
+	}
+	var names []string
+	for _, p := range result.Prompts {
+		names = append(names, p.Name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestPrompts_List(t *testing.T) {
+	session := connect(t, executor.NewMockExecutor(), allTools)
+
+	got := promptNames(t, session)
+	want := []string{prompts.AddDatabase, prompts.DebugService, prompts.FirstDeploy, prompts.ScaleForLoadTest}
+	if !slices.Equal(got, want) {
+		t.Errorf("prompts = %v, want %v", got, want)
+	}
+}
+
+func TestPrompts_OnlyWithEnabledTools(t *testing.T) {
+	readOnly := []string{"zerops_discover", "zerops_logs", "zerops_validate", "zerops_knowledge", "zerops_process", "zerops_events"}
+	session := connect(t, executor.NewMockExecutor(), readOnly)
+
+	if got := promptNames(t, session); !slices.Equal(got, []string{prompts.DebugService}) {
+		t.Errorf("prompts = %v, want only %s", got, prompts.DebugService)
+	}
+}
+
+func TestPrompts_AddDatabaseEmbedsDocs(t *testing.T) {
+	mock := executor.NewMockExecutor().
+		WithZaiaResponse("search postgresql connection --limit 1",
+			executor.SyncResult(`{"results":[{"uri":"zerops://docs/services/postgresql"}],"topResult":{"uri":"zerops://docs/services/postgresql"}}`)).
+		WithZaiaResponse("search --get zerops://docs/services/postgresql",
+			executor.SyncResult(`{"uri":"zerops://docs/services/postgresql","title":"PostgreSQL","content":"# PostgreSQL"}`))
+	session := connect(t, mock, allTools)
+
+	result, err := session.GetPrompt(t.Context(), &mcp.GetPromptParams{
+		Name:      prompts.AddDatabase,
+		Arguments: map[string]string{"serviceHostname": "api"},
+	})
+	if err != nil {
+		t.Fatalf("GetPrompt: %v", err)
+	}
+	if len(result.Messages) != 2 {
+		t.Fatalf("got %d messages, want 2", len(result.Messages))
+	}
+	text := result.Messages[0].Content.(*mcp.TextContent).Text
+	for _, want := range []string{`"api"`, "type: postgresql@16", "hostname: db", "zerops_import dryRun=true", "${db_connectionString}"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("prompt text missing %q:\n%s", want, text)
+		}
+	}
+	doc, ok := result.Messages[1].Content.(*mcp.EmbeddedResource)
+	if !ok {
+		t.Fatalf("second message is %T, want *mcp.EmbeddedResource", result.Messages[1].Content)
+	}
+	if doc.Resource.URI != "zerops://docs/services/postgresql" || doc.Resource.Text != "# PostgreSQL" {
+		t.Errorf("embedded doc = %+v", doc.Resource)
+	}
+}
+
+func TestPrompts_WithoutDocs(t *testing.T) {
+	mock := executor.NewMockExecutor().
+		WithZaiaResponse("search", executor.ErrorResult("API_ERROR", "search unavailable", "", 1))
+	session := connect(t, mock, allTools)
+
+	result, err := session.GetPrompt(t.Context(), &mcp.GetPromptParams{
+		Name:      prompts.ScaleForLoadTest,
+		Arguments: map[string]string{"serviceHostname": "api", "maxContainers": "10"},
+	})
+	if err != nil {
+		t.Fatalf("GetPrompt: %v", err)
+	}
+	if len(result.Messages) != 1 {
+		t.Fatalf("got %d messages, want 1 (no docs)", len(result.Messages))
+	}
+	text := result.Messages[0].Content.(*mcp.TextContent).Text
+	if !strings.Contains(text, "minContainers=2 maxContainers=10") {
+		t.Errorf("prompt text missing scaling arguments:\n%s", text)
+	}
+}
+
+func TestPrompts_RequiredArgument(t *testing.T) {
+	session := connect(t, executor.NewMockExecutor(), allTools)
+
+	_, err := session.GetPrompt(t.Context(), &mcp.GetPromptParams{Name: prompts.DebugService})
+	if err == nil || !strings.Contains(err.Error(), "serviceHostname is required") {
+		t.Errorf("GetPrompt without serviceHostname: err = %v", err)
+	}
+}
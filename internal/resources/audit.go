@@ -0,0 +1,45 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/zeropsio/zaia-mcp/internal/audit"
+)
+
+// AuditURI is the URI of the audit log resource.
+const AuditURI = "zerops://audit"
+
+// auditResourceLimit is the number of entries returned by the audit resource.
+const auditResourceLimit = 50
+
+// RegisterAuditResource registers the zerops://audit resource with the most
+// recent mutating operations, newest first.
+func RegisterAuditResource(srv *mcp.Server, log *audit.Log) {
+	srv.AddResource(
+		&mcp.Resource{
+			URI:         AuditURI,
+			Name:        "zerops-audit",
+			Description: "Recent mutating operations performed through this MCP server (secrets redacted). Use zerops_audit tool to filter.",
+			MIMEType:    "application/json",
+		},
+		func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+			entries := log.Recent(audit.Filter{Limit: auditResourceLimit})
+			b, err := json.Marshal(entries)
+			if err != nil {
+				return nil, fmt.Errorf("encoding audit entries: %w", err)
+			}
+			return &mcp.ReadResourceResult{
+				Contents: []*mcp.ResourceContents{
+					{
+						URI:      AuditURI,
+						MIMEType: "application/json",
+						Text:     string(b),
+					},
+				},
+			}, nil
+		},
+	)
+}
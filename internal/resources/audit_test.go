@@ -0,0 +1,49 @@
+package resources_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/zeropsio/zaia-mcp/internal/audit"
+	"github.com/zeropsio/zaia-mcp/internal/resources"
+)
+
+func TestAuditResource_Read(t *testing.T) {
+	log := audit.NewMemoryLog(10)
+	_ = log.Append(audit.Entry{Tool: "zerops_manage"})
+	_ = log.Append(audit.Entry{Tool: "zerops_delete"})
+
+	srv := mcp.NewServer(
+		&mcp.Implementation{Name: "test", Version: "0.0.1"},
+		nil,
+	)
+	resources.RegisterAuditResource(srv, log)
+
+	ctx := t.Context()
+	t1, t2 := mcp.NewInMemoryTransports()
+	if _, err := srv.Connect(ctx, t1, nil); err != nil {
+		t.Fatalf("server connect: %v", err)
+	}
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0.0.1"}, nil)
+	session, err := client.Connect(ctx, t2, nil)
+	if err != nil {
+		t.Fatalf("client connect: %v", err)
+	}
+	defer session.Close()
+
+	result, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: resources.AuditURI})
+	if err != nil {
+		t.Fatalf("ReadResource: %v", err)
+	}
+	if len(result.Contents) != 1 {
+		t.Fatalf("got %d contents, want 1", len(result.Contents))
+	}
+	var entries []audit.Entry
+	if err := json.Unmarshal([]byte(result.Contents[0].Text), &entries); err != nil {
+		t.Fatalf("parse entries: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Tool != "zerops_delete" {
+		t.Errorf("got %+v, want newest (zerops_delete) first", entries)
+	}
+}
@@ -10,12 +10,15 @@ import (
 	"github.com/zeropsio/zaia-mcp/internal/executor"
 )
 
+// DocsTemplate is the URI template of the knowledge base documents.
+const DocsTemplate = "zerops://docs/{+path}"
+
 // RegisterKnowledgeResources registers the zerops://docs/{path} resource template.
 // Documents are fetched via `zaia search --get <uri>`.
 func RegisterKnowledgeResources(srv *mcp.Server, exec executor.Executor) {
 	srv.AddResourceTemplate(
 		&mcp.ResourceTemplate{
-			URITemplate: "zerops://docs/{+path}",
+			URITemplate: DocsTemplate,
 			Name:        "zerops-docs",
 			Description: "Zerops knowledge base documents. Use zerops_knowledge tool to search, then read specific docs via this resource.",
 			MIMEType:    "text/markdown",
@@ -31,6 +34,11 @@ func RegisterKnowledgeResources(srv *mcp.Server, exec executor.Executor) {
 				return nil, fmt.Errorf("failed to fetch resource: %w", err)
 			}
 
+			if result.StdoutOverflow != nil {
+				return nil, fmt.Errorf("document exceeds output limit (%d bytes, full output: %s)",
+					result.StdoutOverflow.Size, result.StdoutOverflow.Path)
+			}
+
 			// Parse the CLI response
 			if len(result.Stdout) == 0 {
 				return nil, mcp.ResourceNotFoundError(uri)
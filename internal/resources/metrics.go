@@ -0,0 +1,35 @@
+package resources
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/zeropsio/zaia-mcp/internal/metrics"
+)
+
+// MetricsURI is the URI of the metrics resource.
+const MetricsURI = "zerops://metrics"
+
+// RegisterMetricsResource registers the zerops://metrics resource with
+// tool call and CLI execution metrics in the Prometheus text format.
+func RegisterMetricsResource(srv *mcp.Server, m *metrics.Metrics) {
+	srv.AddResource(
+		&mcp.Resource{
+			URI:         MetricsURI,
+			Name:        "zerops-metrics",
+			Description: "Tool call and CLI execution counters and latency histograms of this MCP server (Prometheus text format).",
+			MIMEType:    metrics.ContentType,
+		},
+		func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+			return &mcp.ReadResourceResult{
+				Contents: []*mcp.ResourceContents{
+					{
+						URI:      MetricsURI,
+						MIMEType: metrics.ContentType,
+						Text:     m.Text(),
+					},
+				},
+			}, nil
+		},
+	)
+}
@@ -0,0 +1,131 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/zeropsio/zaia-mcp/internal/audit"
+)
+
+// maxAuditError bounds the error text stored per audit entry.
+const maxAuditError = 500
+
+// isMutatingCall reports whether a tool call changes project state and must be audited.
+func isMutatingCall(tool string, raw json.RawMessage) bool {
+	switch tool {
+	case "zerops_manage", "zerops_delete", "zerops_subdomain", "zerops_deploy":
+		return true
+	case "zerops_env":
+		var in struct {
+			Action string `json:"action"`
+		}
+		_ = json.Unmarshal(raw, &in)
+		return in.Action == "set" || in.Action == "delete"
+	case "zerops_import":
+		var in struct {
+			DryRun bool `json:"dryRun"`
+		}
+		_ = json.Unmarshal(raw, &in)
+		return !in.DryRun
+	default:
+		return false
+	}
+}
+
+// auditMiddleware appends every mutating tools/call to log.
+func auditMiddleware(log *audit.Log, logger *slog.Logger) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			callReq, ok := req.(*mcp.CallToolRequest)
+			if method != "tools/call" || !ok || !isMutatingCall(callReq.Params.Name, callReq.Params.Arguments) {
+				return next(ctx, method, req)
+			}
+
+			ctx, call := audit.WithCall(ctx)
+			start := time.Now()
+			res, err := next(ctx, method, req)
+
+			entry := audit.Entry{
+				Time:       start.UTC(),
+				Client:     clientName(callReq),
+				Tool:       callReq.Params.Name,
+				Args:       audit.NormalizeArgs(callReq.Params.Arguments),
+				ExitCode:   call.ExitCode(),
+				DurationMs: time.Since(start).Milliseconds(),
+			}
+			if err != nil {
+				entry.IsError = true
+				entry.Error = truncate(err.Error(), maxAuditError)
+			} else if result, ok := res.(*mcp.CallToolResult); ok {
+				text := firstText(result)
+				if result.IsError {
+					entry.IsError = true
+					entry.Error = truncate(text, maxAuditError)
+				} else {
+					entry.ProcessIDs = processIDs(text)
+				}
+			}
+
+			if appendErr := log.Append(entry); appendErr != nil {
+				logger.Error("audit append failed", "tool", entry.Tool, "error", appendErr)
+			}
+			return res, err
+		}
+	}
+}
+
+func clientName(req *mcp.CallToolRequest) string {
+	if req.Session == nil {
+		return ""
+	}
+	params := req.Session.InitializeParams()
+	if params == nil || params.ClientInfo == nil {
+		return ""
+	}
+	return params.ClientInfo.Name
+}
+
+func firstText(result *mcp.CallToolResult) string {
+	if len(result.Content) == 0 {
+		return ""
+	}
+	if tc, ok := result.Content[0].(*mcp.TextContent); ok {
+		return tc.Text
+	}
+	return ""
+}
+
+// processIDs extracts process IDs from an async tool result: a JSON array
+// of processes, or the final states returned with waitForCompletion.
+func processIDs(text string) []string {
+	type process struct {
+		ProcessID string `json:"processId"`
+	}
+	var processes []process
+	if json.Unmarshal([]byte(text), &processes) != nil {
+		var waited struct {
+			Processes []process `json:"processes"`
+		}
+		if json.Unmarshal([]byte(text), &waited) != nil {
+			return nil
+		}
+		processes = waited.Processes
+	}
+	var ids []string
+	for _, p := range processes {
+		if p.ProcessID != "" {
+			ids = append(ids, p.ProcessID)
+		}
+	}
+	return ids
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "…"
+}
@@ -0,0 +1,34 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/zeropsio/zaia-mcp/internal/executor"
+)
+
+// callInfoMiddleware attaches executor.CallInfo for every tools/call so
+// executor middlewares know which tool (and service) a CLI command serves.
+func callInfoMiddleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if callReq, ok := req.(*mcp.CallToolRequest); ok && method == "tools/call" {
+				ctx = executor.WithCallInfo(ctx, callInfo(callReq.Params.Name, callReq.Params.Arguments))
+			}
+			return next(ctx, method, req)
+		}
+	}
+}
+
+func callInfo(tool string, raw json.RawMessage) executor.CallInfo {
+	var in struct {
+		ServiceHostname string `json:"serviceHostname"`
+	}
+	_ = json.Unmarshal(raw, &in)
+	return executor.CallInfo{
+		Tool:     tool,
+		Hostname: in.ServiceHostname,
+		Mutating: isMutatingCall(tool, raw),
+	}
+}
@@ -0,0 +1,178 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// HTTP endpoint paths.
+const (
+	MCPPath     = "/mcp"
+	HealthPath  = "/healthz"
+	MetricsPath = "/metrics"
+)
+
+// DefaultSessionTimeout closes HTTP sessions idle for this long.
+const DefaultSessionTimeout = 30 * time.Minute
+
+// shutdownTimeout bounds graceful shutdown of the HTTP listener.
+const shutdownTimeout = 5 * time.Second
+
+// HTTPOptions configures the streamable HTTP transport.
+type HTTPOptions struct {
+	// Tokens are the accepted bearer tokens. Empty disables authentication,
+	// which RunHTTP only allows on a loopback address.
+	Tokens []string
+	// AllowedOrigins lists browser origins (e.g. "https://app.example.com")
+	// allowed to call the MCP endpoint. Requests without an Origin header
+	// (non-browser clients) are always allowed.
+	AllowedOrigins []string
+	// SessionTimeout closes idle sessions (default DefaultSessionTimeout).
+	SessionTimeout time.Duration
+}
+
+// ReadTokenFile reads bearer tokens from path, one per line. Blank lines
+// and lines starting with # are ignored.
+func ReadTokenFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("token file: %w", err)
+	}
+	var tokens []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			tokens = append(tokens, line)
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("token file %s: no tokens", path)
+	}
+	return tokens, nil
+}
+
+// HTTPHandler returns the handler for the streamable HTTP transport:
+// the MCP endpoint at MCPPath (bearer auth, origin check), an
+// unauthenticated health check at HealthPath and metrics at MetricsPath
+// (bearer auth). Each client gets its own MCP session; a session can only
+// be used with the token that created it.
+func (s *MCPServer) HTTPHandler(opts HTTPOptions) http.Handler {
+	timeout := opts.SessionTimeout
+	if timeout <= 0 {
+		timeout = DefaultSessionTimeout
+	}
+	mcpHandler := mcp.NewStreamableHTTPHandler(
+		func(*http.Request) *mcp.Server { return s.server },
+		&mcp.StreamableHTTPOptions{Logger: s.sdkLogger, SessionTimeout: timeout},
+	)
+
+	protect := func(h http.Handler) http.Handler { return h }
+	if len(opts.Tokens) > 0 {
+		protect = auth.RequireBearerToken(tokenVerifier(opts.Tokens), nil)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(MCPPath, checkOrigin(opts.AllowedOrigins, protect(mcpHandler)))
+	mux.HandleFunc("GET "+HealthPath, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{"status": "ok", "version": Version})
+	})
+	mux.Handle("GET "+MetricsPath, protect(s.metrics.Handler()))
+	return mux
+}
+
+// RunHTTP serves the streamable HTTP transport on addr until ctx is done.
+// Dependencies are probed in the background and the outcome is logged.
+func (s *MCPServer) RunHTTP(ctx context.Context, addr string, opts HTTPOptions) error {
+	if len(opts.Tokens) == 0 && !isLoopback(addr) {
+		return fmt.Errorf("listening on %s without authentication: configure a bearer token or listen on a loopback address", addr)
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("http listener: %w", err)
+	}
+	return s.Serve(ctx, ln, opts)
+}
+
+// Serve is RunHTTP on an existing listener. It does not check that
+// unauthenticated listeners are loopback-only.
+func (s *MCPServer) Serve(ctx context.Context, ln net.Listener, opts HTTPOptions) error {
+	srv := &http.Server{Handler: s.HTTPHandler(opts), ReadHeaderTimeout: 10 * time.Second}
+	go s.logDependencies(ctx)
+	s.logger.Info("serving MCP over streamable HTTP", "addr", ln.Addr().String(), "path", MCPPath, "auth", len(opts.Tokens) > 0)
+
+	errc := make(chan error, 1)
+	go func() { errc <- srv.Serve(ln) }()
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	// Open event streams keep connections busy; close whatever remains.
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		_ = srv.Close()
+	}
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// tokenVerifier accepts any of tokens. The session owner is derived from
+// the token hash, so sessions cannot be taken over with another token.
+func tokenVerifier(tokens []string) auth.TokenVerifier {
+	return func(_ context.Context, token string, _ *http.Request) (*auth.TokenInfo, error) {
+		for _, t := range tokens {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(t)) == 1 {
+				sum := sha256.Sum256([]byte(t))
+				return &auth.TokenInfo{
+					UserID: hex.EncodeToString(sum[:8]),
+					// Static tokens do not expire; the SDK requires a value.
+					Expiration: time.Now().Add(24 * time.Hour),
+				}, nil
+			}
+		}
+		return nil, auth.ErrInvalidToken
+	}
+}
+
+// checkOrigin rejects browser requests from origins not in allowed, which
+// protects local servers from DNS rebinding.
+func checkOrigin(allowed []string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if origin := r.Header.Get("Origin"); origin != "" && !slices.Contains(allowed, origin) {
+			http.Error(w, "origin not allowed", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isLoopback reports whether addr (host:port) binds to loopback only.
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
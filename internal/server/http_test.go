@@ -0,0 +1,181 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/zeropsio/zaia-mcp/internal/executor"
+)
+
+const testToken = "test-token-1234"
+
+// bearer adds an Authorization header to every request.
+type bearer struct {
+	token string
+	next  http.RoundTripper
+}
+
+func (b bearer) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Set("Authorization", "Bearer "+b.token)
+	return b.next.RoundTrip(r)
+}
+
+func newHTTPTestServer(t *testing.T, opts HTTPOptions) *httptest.Server {
+	t.Helper()
+	mock := executor.NewMockExecutor().WithDefault(executor.SyncResult(`{"services":[]}`))
+	ts := httptest.NewServer(NewWithExecutor(mock).HTTPHandler(opts))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func connectHTTP(t *testing.T, endpoint, token string) (*mcp.ClientSession, error) {
+	t.Helper()
+	client := mcp.NewClient(&mcp.Implementation{Name: "http-client", Version: "0.0.1"}, nil)
+	return client.Connect(t.Context(), &mcp.StreamableClientTransport{
+		Endpoint:   endpoint,
+		HTTPClient: &http.Client{Transport: bearer{token: token, next: http.DefaultTransport}},
+		MaxRetries: -1,
+	}, nil)
+}
+
+func TestHTTP_AuthenticatedSession(t *testing.T) {
+	ts := newHTTPTestServer(t, HTTPOptions{Tokens: []string{testToken}})
+
+	cs, err := connectHTTP(t, ts.URL+MCPPath, testToken)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer cs.Close()
+	result, err := cs.CallTool(t.Context(), &mcp.CallToolParams{Name: "zerops_discover", Arguments: map[string]any{}})
+	if err != nil {
+		t.Fatalf("CallTool: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected tool error: %+v", result.Content)
+	}
+
+	if _, err := connectHTTP(t, ts.URL+MCPPath, "wrong-token"); err == nil {
+		t.Error("connect with a wrong token succeeded")
+	}
+}
+
+func TestHTTP_StatusCodes(t *testing.T) {
+	ts := newHTTPTestServer(t, HTTPOptions{Tokens: []string{testToken}, AllowedOrigins: []string{"https://ok.example"}})
+	init := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"c","version":"1"}}}`
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		headers map[string]string
+		want    int
+	}{
+		{"health without token", http.MethodGet, HealthPath, nil, http.StatusOK},
+		{"mcp without token", http.MethodPost, MCPPath, nil, http.StatusUnauthorized},
+		{"metrics without token", http.MethodGet, MetricsPath, nil, http.StatusUnauthorized},
+		{"metrics with token", http.MethodGet, MetricsPath, map[string]string{"Authorization": "Bearer " + testToken}, http.StatusOK},
+		{"foreign origin", http.MethodPost, MCPPath, map[string]string{"Authorization": "Bearer " + testToken, "Origin": "https://evil.example"}, http.StatusForbidden},
+		{"allowed origin", http.MethodPost, MCPPath, map[string]string{"Authorization": "Bearer " + testToken, "Origin": "https://ok.example"}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequestWithContext(t.Context(), tt.method, ts.URL+tt.path, strings.NewReader(init))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Accept", "application/json, text/event-stream")
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("got %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTP_SessionBoundToToken(t *testing.T) {
+	ts := newHTTPTestServer(t, HTTPOptions{Tokens: []string{testToken, "other-token-5678"}})
+
+	post := func(token, sessionID, body string) *http.Response {
+		req, _ := http.NewRequestWithContext(t.Context(), http.MethodPost, ts.URL+MCPPath, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json, text/event-stream")
+		req.Header.Set("Authorization", "Bearer "+token)
+		if sessionID != "" {
+			req.Header.Set("Mcp-Session-Id", sessionID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	resp := post(testToken, "", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"c","version":"1"}}}`)
+	sessionID := resp.Header.Get("Mcp-Session-Id")
+	if sessionID == "" {
+		t.Fatalf("no session ID (status %d)", resp.StatusCode)
+	}
+	if got := post("other-token-5678", sessionID, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`).StatusCode; got != http.StatusForbidden {
+		t.Errorf("session used with another token: got %d, want 403", got)
+	}
+}
+
+func TestRunHTTP_RequiresTokenOffLoopback(t *testing.T) {
+	srv := NewWithExecutor(executor.NewMockExecutor())
+	err := srv.RunHTTP(t.Context(), "0.0.0.0:0", HTTPOptions{})
+	if err == nil || !strings.Contains(err.Error(), "without authentication") {
+		t.Errorf("got %v, want refusal", err)
+	}
+}
+
+func TestServe_StopsOnCancel(t *testing.T) {
+	srv := NewWithExecutor(executor.NewMockExecutor())
+	ctx, cancel := context.WithCancel(t.Context())
+	ln := httptest.NewUnstartedServer(nil).Listener
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ctx, ln, HTTPOptions{}) }()
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Serve: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Serve did not return after cancel")
+	}
+}
+
+func TestReadTokenFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens")
+	if err := os.WriteFile(path, []byte("# team tokens\nalpha\n\n  beta  \n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tokens, err := ReadTokenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(tokens, []string{"alpha", "beta"}) {
+		t.Errorf("got %q", tokens)
+	}
+	if err := os.WriteFile(path, []byte("# none\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadTokenFile(path); err == nil {
+		t.Error("empty token file accepted")
+	}
+}
@@ -0,0 +1,172 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/zeropsio/zaia-mcp/internal/executor"
+)
+
+// DefaultInstructionsBudget is the default token budget of the server
+// instructions. TestInstructions_WithinBudget keeps the largest static
+// variant (all tools, every mode line) under it with room for a project
+// summary.
+const DefaultInstructionsBudget = 900
+
+// projectSummaryTimeout bounds the startup `zaia discover` for the summary,
+// which delays the MCP handshake; tests shorten it.
+var projectSummaryTimeout = 3 * time.Second
+
+// instructionsHeader is the platform overview that precedes the tool list.
+// Lines naming a zerops_ tool are dropped when that tool is not registered.
+const instructionsHeader = `Zerops
+
+PaaS. Full Linux containers (Incus), bare-metal, SSH access. Not serverless.
+
+Services
+Runtime: nodejs php python go rust java dotnet elixir gleam bun deno
+Container: alpine ubuntu docker(VM-based)
+DB: postgresql(default) mariadb clickhouse
+Cache: valkey(default, redis-compat) | keydb(deprecated)
+Search: meilisearch(default) elasticsearch typesense qdrant(internal-only)
+Queue: nats(default) kafka
+Storage: object-storage(S3/MinIO) shared-storage(POSIX)
+Web: nginx static(SPA-ready)
+
+Files
+zerops.yml = build + deploy + run config (per service)
+import.yml = infrastructure-as-code (services array, NO project: section)
+
+Critical Rules
+- Internal networking: ALWAYS http://, NEVER https:// (SSL terminates at L7 balancer)
+- Ports: 10-65435 only (0-9 and 65436+ reserved)
+- HA mode: immutable after creation (cannot change single↔HA)
+- mode: NON_HA or HA REQUIRED for databases/caches in import.yml (omitting passes dryRun but fails real import)
+- prepareCommands: cached. initCommands: run every start
+- Env var cross-ref: ${service_hostname} (underscore, not dash)
+- Cloudflare: MUST use "Full (strict)" SSL mode
+- No localhost — services communicate via hostname
+- zerops_subdomain enable: only works on deployed (ACTIVE) services. For new services use enableSubdomainAccess in import.yml`
+
+const instructionsDefaults = `Defaults (use unless user specifies otherwise)
+postgresql@16, valkey@7.2, meilisearch@1.10, nats@2.10, alpine base, NON_HA, SHARED CPU`
+
+// DefaultInstructions returns the instructions with every tool registered
+// and no mode or project lines.
+func DefaultInstructions() string {
+	return buildInstructions(toolRegistry, instructionModes{}, nil, 0)
+}
+
+// instructionModes are the non-default server settings the model should know.
+type instructionModes struct {
+	readOnly bool
+	filtered bool     // tools were enabled/disabled explicitly
+	profiles []string // configured profile names, default marked
+}
+
+// EstimateTokens approximates the token count of s (about 4 characters
+// per token for English text and YAML-ish keywords).
+func EstimateTokens(s string) int {
+	return (utf8.RuneCountInString(s) + 3) / 4
+}
+
+// buildInstructions assembles the instructions: the header, one line per
+// registered tool, mode lines, the project summary and defaults. When the
+// result exceeds budget (> 0), project lines are dropped from the end.
+func buildInstructions(specs []toolSpec, modes instructionModes, project []string, budget int) string {
+	on := map[string]bool{}
+	for _, t := range specs {
+		on[t.name] = true
+	}
+
+	var head []string
+	for _, line := range strings.Split(instructionsHeader, "\n") {
+		if !slices.ContainsFunc(toolMention.FindAllString(line, -1), func(n string) bool { return !on[n] }) {
+			head = append(head, line)
+		}
+	}
+	head = append(head, "", "Tools")
+	for _, t := range specs {
+		head = append(head, strings.TrimPrefix(t.name, toolPrefix)+" → "+t.summary)
+	}
+
+	var mode []string
+	if modes.readOnly {
+		mode = append(mode, "read-only: project changes (deploy, manage, env, import, delete, subdomain, process cancel) are unavailable")
+	}
+	if modes.filtered {
+		mode = append(mode, "some tools are disabled by the server configuration; do not ask for them")
+	}
+	if len(modes.profiles) > 0 {
+		mode = append(mode, "profiles: "+strings.Join(modes.profiles, ", "))
+	}
+	if len(mode) > 0 {
+		head = append(head, "", "Mode")
+		head = append(head, mode...)
+	}
+
+	assemble := func(project []string) string {
+		parts := []string{strings.Join(head, "\n")}
+		if len(project) > 0 {
+			parts = append(parts, "Project (at server start; call discover for current state)\n"+strings.Join(project, "\n"))
+		}
+		return strings.Join(append(parts, instructionsDefaults), "\n\n")
+	}
+
+	out := assemble(project)
+	for budget > 0 && EstimateTokens(out) > budget && len(project) > 0 {
+		project = project[:len(project)-1]
+		out = assemble(project)
+	}
+	return out
+}
+
+// projectSummary runs `zaia discover` and returns one line for the project
+// and one per service, or nil if discover fails or does not return within
+// projectSummaryTimeout. It stops waiting at the timeout even if an
+// executor in the chain ignores the context.
+func projectSummary(exec executor.Executor) []string {
+	ctx, cancel := context.WithTimeout(context.Background(), projectSummaryTimeout)
+	defer cancel()
+	lines := make(chan []string, 1)
+	go func() { lines <- summarizeProject(ctx, exec) }()
+	select {
+	case l := <-lines:
+		return l
+	case <-ctx.Done():
+		return nil
+	}
+}
+
+func summarizeProject(ctx context.Context, exec executor.Executor) []string {
+	result, err := exec.RunZaia(ctx, "discover")
+	if err != nil || result.ExitCode != 0 {
+		return nil
+	}
+	var resp struct {
+		Type string `json:"type"`
+		Data struct {
+			Project struct {
+				Name string `json:"name"`
+			} `json:"project"`
+			Services []struct {
+				Hostname string `json:"hostname"`
+				Type     string `json:"type"`
+				Status   string `json:"status"`
+			} `json:"services"`
+		} `json:"data"`
+	}
+	if json.Unmarshal(result.Stdout, &resp) != nil || resp.Type != "sync" {
+		return nil
+	}
+	lines := []string{fmt.Sprintf("%s: %d service(s)", resp.Data.Project.Name, len(resp.Data.Services))}
+	for _, svc := range resp.Data.Services {
+		lines = append(lines, fmt.Sprintf("- %s %s %s", svc.Hostname, svc.Type, svc.Status))
+	}
+	return lines
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zeropsio/zaia-mcp/internal/executor"
+)
+
+func TestInstructions_ListsRegisteredTools(t *testing.T) {
+	got := DefaultInstructions()
+	for _, spec := range toolRegistry {
+		short := strings.TrimPrefix(spec.name, toolPrefix)
+		if !strings.Contains(got, "\n"+short+" → ") {
+			t.Errorf("instructions do not list %s", spec.name)
+		}
+	}
+	if strings.Contains(got, "configure →") {
+		t.Error("instructions list the nonexistent configure tool")
+	}
+}
+
+func TestInstructions_OmitDisabledTools(t *testing.T) {
+	got := buildInstructions(enabledTools(Options{DisableTools: []string{"subdomain", "deploy"}}), instructionModes{filtered: true}, nil, 0)
+	for _, hidden := range []string{"zerops_subdomain", "subdomain →", "deploy →"} {
+		if strings.Contains(got, hidden) {
+			t.Errorf("instructions mention disabled tool (%q)", hidden)
+		}
+	}
+	if !strings.Contains(got, "some tools are disabled") {
+		t.Error("instructions do not mention the tool filter")
+	}
+}
+
+// TestInstructions_WithinBudget enforces DefaultInstructionsBudget for the
+// largest static variant, leaving room for a project summary.
+func TestInstructions_WithinBudget(t *testing.T) {
+	modes := instructionModes{
+		readOnly: true,
+		filtered: true,
+		profiles: []string{"production (default)", "staging", "preview"},
+	}
+	static := buildInstructions(toolRegistry, modes, nil, 0)
+	if tokens := EstimateTokens(static); tokens > DefaultInstructionsBudget*85/100 {
+		t.Errorf("static instructions use %d of %d tokens; keep 15%% for the project summary", tokens, DefaultInstructionsBudget)
+	}
+	t.Logf("static instructions: ~%d tokens (budget %d)", EstimateTokens(static), DefaultInstructionsBudget)
+
+	var project []string
+	for i := range 100 {
+		project = append(project, fmt.Sprintf("- service%02d nodejs@22 ACTIVE", i))
+	}
+	got := buildInstructions(toolRegistry, modes, project, DefaultInstructionsBudget)
+	if tokens := EstimateTokens(got); tokens > DefaultInstructionsBudget {
+		t.Errorf("instructions with a large project use %d tokens, budget %d", tokens, DefaultInstructionsBudget)
+	}
+	if !strings.Contains(got, "- service00 ") || strings.Contains(got, "- service99 ") {
+		t.Error("project summary should be shortened from the end")
+	}
+}
+
+func TestNewWithOptions_ProjectSummary(t *testing.T) {
+	mock := executor.NewMockExecutor().WithZaiaResponse("discover", executor.SyncResult(
+		`{"project":{"name":"shop"},"services":[{"hostname":"api","type":"go@1","status":"ACTIVE"}]}`))
+	s := NewWithOptions(mock, Options{ProjectSummary: true})
+	got := connect(t, s).InitializeResult().Instructions
+	if !strings.Contains(got, "shop: 1 service(s)\n- api go@1 ACTIVE") {
+		t.Errorf("instructions lack the project summary:\n%s", got)
+	}
+
+	failing := executor.NewMockExecutor().WithZaiaResponse("discover", executor.ErrorResult("AUTH_REQUIRED", "not logged in", "", 1))
+	got = connect(t, NewWithOptions(failing, Options{ProjectSummary: true})).InitializeResult().Instructions
+	if strings.Contains(got, "Project (") {
+		t.Error("project section present although discover failed")
+	}
+}
+
+func TestNewWithOptions_ProjectSummaryTimeout(t *testing.T) {
+	defer func(d time.Duration) { projectSummaryTimeout = d }(projectSummaryTimeout)
+	projectSummaryTimeout = 50 * time.Millisecond
+
+	release := make(chan struct{})
+	defer close(release)
+	// An executor that ignores the context, like a stuck middleware.
+	stuck := executor.Intercept(func(ctx context.Context, binary string, args []string, next executor.RunFunc) (*executor.Result, error) {
+		<-release
+		return next(ctx, args...)
+	})(executor.NewMockExecutor())
+
+	start := time.Now()
+	s := NewWithOptions(stuck, Options{ProjectSummary: true})
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("NewWithOptions took %s with a stuck discover", elapsed)
+	}
+	if got := connect(t, s).InitializeResult().Instructions; strings.Contains(got, "Project (") {
+		t.Error("project section present although discover timed out")
+	}
+}
@@ -0,0 +1,291 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/zeropsio/zaia-mcp/internal/audit"
+)
+
+// loggerName is the "logger" field of log notifications sent to clients.
+const loggerName = "zaia-mcp"
+
+// mcpLevels maps MCP logging levels to slog levels (RFC 5424 order).
+var mcpLevels = map[mcp.LoggingLevel]slog.Level{
+	"debug":     slog.LevelDebug,
+	"info":      slog.LevelInfo,
+	"notice":    slog.LevelInfo + 2,
+	"warning":   slog.LevelWarn,
+	"error":     slog.LevelError,
+	"critical":  slog.LevelError + 4,
+	"alert":     slog.LevelError + 8,
+	"emergency": slog.LevelError + 12,
+}
+
+func mcpLevel(l slog.Level) mcp.LoggingLevel {
+	switch {
+	case l >= slog.LevelError:
+		return "error"
+	case l >= slog.LevelWarn:
+		return "warning"
+	case l >= slog.LevelInfo:
+		return "info"
+	}
+	return "debug"
+}
+
+type sessionKey struct{}
+
+// clientLogs tracks the level each client session asked for with
+// logging/setLevel. Sessions that never set one receive no logs.
+type clientLogs struct {
+	mu     sync.Mutex
+	srv    *mcp.Server // nil until the server is created
+	levels map[*mcp.ServerSession]slog.Level
+	// broadcast sends logs outside a request to every session. Only set
+	// for STDIO, whose one client runs the server; over HTTP, sessions may
+	// belong to different tenants and these logs stay on stderr.
+	broadcast bool
+}
+
+func newClientLogs() *clientLogs {
+	return &clientLogs{levels: make(map[*mcp.ServerSession]slog.Level)}
+}
+
+func (c *clientLogs) attach(srv *mcp.Server) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.srv = srv
+}
+
+// broadcastOutOfRequest enables sending logs outside a request to every
+// session that set a level.
+func (c *clientLogs) broadcastOutOfRequest() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.broadcast = true
+}
+
+// middleware attaches the request's session to the context, so logs
+// written while handling the request go to that client only, and records
+// the level of logging/setLevel requests.
+func (c *clientLogs) middleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			ss, _ := req.GetSession().(*mcp.ServerSession)
+			if ss != nil {
+				ctx = context.WithValue(ctx, sessionKey{}, ss)
+			}
+			res, err := next(ctx, method, req)
+			if params, ok := req.GetParams().(*mcp.SetLoggingLevelParams); ok && err == nil && ss != nil {
+				if level, known := mcpLevels[params.Level]; known {
+					c.mu.Lock()
+					c.levels[ss] = level
+					c.mu.Unlock()
+				}
+			}
+			return res, err
+		}
+	}
+}
+
+// targets returns the sessions that want a record at level: the session
+// in ctx, or with broadcast every connected session for logs outside a
+// request.
+func (c *clientLogs) targets(ctx context.Context, level slog.Level) []*mcp.ServerSession {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if ss, ok := ctx.Value(sessionKey{}).(*mcp.ServerSession); ok {
+		if min, set := c.levels[ss]; set && level >= min {
+			return []*mcp.ServerSession{ss}
+		}
+		return nil
+	}
+	if c.srv == nil || !c.broadcast {
+		return nil
+	}
+	connected := make(map[*mcp.ServerSession]bool, len(c.levels))
+	var out []*mcp.ServerSession
+	for ss := range c.srv.Sessions() {
+		connected[ss] = true
+		if min, set := c.levels[ss]; set && level >= min {
+			out = append(out, ss)
+		}
+	}
+	for ss := range c.levels {
+		if !connected[ss] {
+			delete(c.levels, ss)
+		}
+	}
+	return out
+}
+
+// enabled reports whether any client wants a record at level.
+func (c *clientLogs) enabled(ctx context.Context, level slog.Level) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if ss, ok := ctx.Value(sessionKey{}).(*mcp.ServerSession); ok {
+		min, set := c.levels[ss]
+		return set && level >= min
+	}
+	if !c.broadcast {
+		return false
+	}
+	for _, min := range c.levels {
+		if level >= min {
+			return true
+		}
+	}
+	return false
+}
+
+// clientLogHandler is a slog.Handler that writes records to next and also
+// sends them to clients as notifications/message, honoring each client's
+// logging/setLevel. Attributes with credential-like keys are redacted in
+// notifications.
+type clientLogHandler struct {
+	next  slog.Handler
+	logs  *clientLogs
+	attrs []prefixedAttr
+	group string // key prefix from WithGroup, e.g. "request."
+}
+
+type prefixedAttr struct {
+	prefix string
+	attr   slog.Attr
+}
+
+func newClientLogHandler(next slog.Handler, logs *clientLogs) *clientLogHandler {
+	return &clientLogHandler{next: next, logs: logs}
+}
+
+func (h *clientLogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level) || h.logs.enabled(ctx, level)
+}
+
+func (h *clientLogHandler) Handle(ctx context.Context, r slog.Record) error {
+	var err error
+	if h.next.Enabled(ctx, r.Level) {
+		err = h.next.Handle(ctx, r)
+	}
+	sessions := h.logs.targets(ctx, r.Level)
+	if len(sessions) == 0 {
+		return err
+	}
+	data := map[string]any{"msg": r.Message}
+	for _, a := range h.attrs {
+		addAttr(data, a.prefix, a.attr)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		addAttr(data, h.group, a)
+		return true
+	})
+	params := &mcp.LoggingMessageParams{Level: mcpLevel(r.Level), Logger: loggerName, Data: data}
+	for _, ss := range sessions {
+		_ = ss.Log(ctx, params)
+	}
+	return err
+}
+
+func (h *clientLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.next = h.next.WithAttrs(attrs)
+	h2.attrs = append([]prefixedAttr(nil), h.attrs...)
+	for _, a := range attrs {
+		h2.attrs = append(h2.attrs, prefixedAttr{h.group, a})
+	}
+	return &h2
+}
+
+func (h *clientLogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.next = h.next.WithGroup(name)
+	h2.group = h.group + name + "."
+	return &h2
+}
+
+// addAttr stores a under its dotted key, flattening groups and redacting
+// secrets.
+func addAttr(data map[string]any, prefix string, a slog.Attr) {
+	v := a.Value.Resolve()
+	if v.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, sub := range v.Group() {
+			addAttr(data, prefix, sub)
+		}
+		return
+	}
+	if a.Key == "" {
+		return
+	}
+	switch {
+	case audit.IsSecretKey(a.Key):
+		data[prefix+a.Key] = audit.Redacted
+	case v.Kind() == slog.KindAny:
+		if e, ok := v.Any().(error); ok {
+			data[prefix+a.Key] = e.Error()
+		} else {
+			data[prefix+a.Key] = v.Any()
+		}
+	case v.Kind() == slog.KindDuration:
+		data[prefix+a.Key] = v.Duration().String()
+	default:
+		data[prefix+a.Key] = v.Any()
+	}
+}
+
+// toolLogMiddleware logs every tools/call with its duration: at debug
+// level, or warn level for error results and protocol errors.
+func toolLogMiddleware(logger *slog.Logger) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			callReq, ok := req.(*mcp.CallToolRequest)
+			if method != "tools/call" || !ok {
+				return next(ctx, method, req)
+			}
+			start := time.Now()
+			res, err := next(ctx, method, req)
+
+			level := slog.LevelDebug
+			attrs := []any{"tool", callReq.Params.Name, "durationMs", time.Since(start).Milliseconds()}
+			if err != nil {
+				level = slog.LevelWarn
+				attrs = append(attrs, "error", err)
+			} else if result, ok := res.(*mcp.CallToolResult); ok && result.IsError {
+				level = slog.LevelWarn
+				attrs = append(attrs, "isError", true)
+				if code := resultCode(result); code != "" {
+					attrs = append(attrs, "code", code)
+				}
+			}
+			logger.Log(ctx, level, "tool call", attrs...)
+			return res, err
+		}
+	}
+}
+
+// resultCode returns the CLI error code of an error result, if any.
+func resultCode(result *mcp.CallToolResult) string {
+	for _, c := range result.Content {
+		text, ok := c.(*mcp.TextContent)
+		if !ok {
+			continue
+		}
+		var resp struct {
+			Code string `json:"code"`
+		}
+		if json.Unmarshal([]byte(text.Text), &resp) == nil && resp.Code != "" {
+			return resp.Code
+		}
+	}
+	return ""
+}
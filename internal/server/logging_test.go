@@ -0,0 +1,178 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/zeropsio/zaia-mcp/internal/executor"
+)
+
+// logClient is a client session that collects log notifications.
+type logClient struct {
+	cs *mcp.ClientSession
+
+	mu   sync.Mutex
+	msgs []*mcp.LoggingMessageParams
+}
+
+func connectLogClient(t *testing.T, s *MCPServer) *logClient {
+	t.Helper()
+	lc := &logClient{}
+	t1, t2 := mcp.NewInMemoryTransports()
+	if _, err := s.Server().Connect(t.Context(), t1, nil); err != nil {
+		t.Fatal(err)
+	}
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0.0.1"}, &mcp.ClientOptions{
+		LoggingMessageHandler: func(_ context.Context, req *mcp.LoggingMessageRequest) {
+			lc.mu.Lock()
+			defer lc.mu.Unlock()
+			lc.msgs = append(lc.msgs, req.Params)
+		},
+	})
+	cs, err := client.Connect(t.Context(), t2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cs.Close() })
+	lc.cs = cs
+	return lc
+}
+
+// find waits up to 2s for a log message with msg (notifications arrive
+// asynchronously) and returns its data.
+func (lc *logClient) find(msg string) (*mcp.LoggingMessageParams, map[string]any) {
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		lc.mu.Lock()
+		for _, m := range lc.msgs {
+			if data, ok := m.Data.(map[string]any); ok && data["msg"] == msg {
+				lc.mu.Unlock()
+				return m, data
+			}
+		}
+		lc.mu.Unlock()
+		if time.Now().After(deadline) {
+			return nil, nil
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func (lc *logClient) count() int {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	return len(lc.msgs)
+}
+
+func TestClientLogs_ToolAndCLICalls(t *testing.T) {
+	mock := executor.NewMockExecutor().WithZaiaResponse("discover", executor.SyncResult(`{"services":[]}`))
+	lc := connectLogClient(t, NewWithExecutor(mock))
+	if err := lc.cs.SetLoggingLevel(t.Context(), &mcp.SetLoggingLevelParams{Level: "debug"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := lc.cs.CallTool(t.Context(), &mcp.CallToolParams{Name: "zerops_discover", Arguments: map[string]any{}}); err != nil {
+		t.Fatal(err)
+	}
+
+	params, data := lc.find("cli call")
+	if data == nil {
+		t.Fatal("no cli call log notification")
+	}
+	if params.Logger != loggerName || params.Level != "debug" {
+		t.Errorf("logger/level = %q/%q, want %q/debug", params.Logger, params.Level, loggerName)
+	}
+	for key, want := range map[string]any{"tool": "zerops_discover", "command": "discover", "exitCode": float64(0)} {
+		if data[key] != want {
+			t.Errorf("cli call %s = %v, want %v", key, data[key], want)
+		}
+	}
+	if _, ok := data["durationMs"]; !ok {
+		t.Error("cli call has no durationMs")
+	}
+	if _, data := lc.find("tool call"); data == nil || data["tool"] != "zerops_discover" {
+		t.Errorf("tool call log = %v", data)
+	}
+}
+
+func TestClientLogs_HonorsLevel(t *testing.T) {
+	mock := executor.NewMockExecutor().
+		WithZaiaResponse("discover", executor.SyncResult(`{"services":[]}`)).
+		WithZaiaResponse("logs", executor.ErrorResult("SERVICE_NOT_FOUND", "no such service", "", 1))
+	s := NewWithExecutor(mock)
+	quiet := connectLogClient(t, s)
+	lc := connectLogClient(t, s)
+	if err := lc.cs.SetLoggingLevel(t.Context(), &mcp.SetLoggingLevelParams{Level: "warning"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := lc.cs.CallTool(t.Context(), &mcp.CallToolParams{Name: "zerops_discover", Arguments: map[string]any{}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lc.cs.CallTool(t.Context(), &mcp.CallToolParams{Name: "zerops_logs", Arguments: map[string]any{"serviceHostname": "nope"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	params, data := lc.find("tool call")
+	if data == nil {
+		t.Fatal("no log notification for the failed call")
+	}
+	if params.Level != "warning" || data["tool"] != "zerops_logs" || data["code"] != "SERVICE_NOT_FOUND" {
+		t.Errorf("failed tool call log = %s %v", params.Level, data)
+	}
+	lc.mu.Lock()
+	for _, m := range lc.msgs {
+		if m.Level == "debug" {
+			t.Errorf("debug message sent at warning level: %v", m.Data)
+		}
+	}
+	lc.mu.Unlock()
+	if n := quiet.count(); n != 0 {
+		t.Errorf("client without a log level got %d messages", n)
+	}
+}
+
+func TestClientLogHandler_RedactsAndForwardsToAllSessions(t *testing.T) {
+	var stderr bytes.Buffer
+	s := NewWithOptions(executor.NewMockExecutor(), Options{
+		Logger: slog.New(slog.NewTextHandler(&stderr, nil)),
+	})
+	s.logs.broadcastOutOfRequest() // as Run does for STDIO
+	lc := connectLogClient(t, s)
+	if err := lc.cs.SetLoggingLevel(t.Context(), &mcp.SetLoggingLevelParams{Level: "info"}); err != nil {
+		t.Fatal(err)
+	}
+
+	s.logger.With("authToken", "s3cret").WithGroup("req").Info("hello", "password", "hunter2", "path", "/mcp")
+
+	_, data := lc.find("hello")
+	if data == nil {
+		t.Fatal("log outside a request was not forwarded")
+	}
+	if data["authToken"] != "<redacted>" || data["req.password"] != "<redacted>" || data["req.path"] != "/mcp" {
+		t.Errorf("forwarded data = %v", data)
+	}
+	if !strings.Contains(stderr.String(), "msg=hello") {
+		t.Errorf("log not written to the configured logger: %s", stderr.String())
+	}
+}
+
+func TestClientLogHandler_NoBroadcastOverHTTP(t *testing.T) {
+	s := NewWithOptions(executor.NewMockExecutor(), Options{})
+	lc := connectLogClient(t, s)
+	if err := lc.cs.SetLoggingLevel(t.Context(), &mcp.SetLoggingLevelParams{Level: "debug"}); err != nil {
+		t.Fatal(err)
+	}
+
+	s.logger.Info("tenant secret")
+
+	if m, _ := lc.find("tenant secret"); m != nil {
+		t.Errorf("log outside a request was sent without STDIO: %+v", m)
+	}
+}
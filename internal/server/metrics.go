@@ -0,0 +1,32 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/zeropsio/zaia-mcp/internal/metrics"
+)
+
+// metricsMiddleware records count, outcome and latency of every tools/call.
+func metricsMiddleware(m *metrics.Metrics) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			callReq, ok := req.(*mcp.CallToolRequest)
+			if method != "tools/call" || !ok {
+				return next(ctx, method, req)
+			}
+			start := time.Now()
+			res, err := next(ctx, method, req)
+
+			outcome := metrics.ToolSuccess
+			if err != nil {
+				outcome = metrics.ToolFailure
+			} else if result, ok := res.(*mcp.CallToolResult); ok && result.IsError {
+				outcome = metrics.ToolError
+			}
+			m.ObserveTool(callReq.Params.Name, outcome, time.Since(start))
+			return res, err
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/zeropsio/zaia-mcp/internal/executor"
+	"github.com/zeropsio/zaia-mcp/internal/tools"
+)
+
+// toolPrefix is shared by all tool names; tool filters accept names with
+// or without it.
+const toolPrefix = "zerops_"
+
+// toolSpec is one entry of the tool registry.
+type toolSpec struct {
+	name string
+	// readOnly mirrors the tool's ReadOnlyHint annotation (checked by a test).
+	readOnly bool
+	// summary is the tool's line in the server instructions.
+	summary  string
+	register func(s *MCPServer)
+}
+
+// toolRegistry lists every tool in registration order.
+var toolRegistry = []toolSpec{
+	// Sync tools (7)
+	{
+		name: "zerops_discover", readOnly: true,
+		summary:  "project info + service list (call first)",
+		register: func(s *MCPServer) { tools.RegisterDiscover(s.server, s.exec) },
+	},
+	{
+		name: "zerops_logs", readOnly: true,
+		summary:  "service runtime/build logs",
+		register: func(s *MCPServer) { tools.RegisterLogs(s.server, s.exec) },
+	},
+	{
+		name: "zerops_validate", readOnly: true,
+		summary:  "check zerops.yml/import.yml before deploy",
+		register: func(s *MCPServer) { tools.RegisterValidate(s.server, s.exec) },
+	},
+	{
+		name: "zerops_knowledge", readOnly: true,
+		summary:  "BM25 search Zerops docs (use specific terms)",
+		register: func(s *MCPServer) { tools.RegisterKnowledge(s.server, s.exec) },
+	},
+	{
+		name: "zerops_process", readOnly: true,
+		summary:  "check or cancel an async operation",
+		register: func(s *MCPServer) { tools.RegisterProcess(s.server, s.exec) },
+	},
+	{
+		name: "zerops_wait", readOnly: true,
+		summary:  "wait for several processes at once (all or any)",
+		register: func(s *MCPServer) { tools.RegisterWait(s.server, s.exec) },
+	},
+	{
+		name: "zerops_events", readOnly: true,
+		summary:  "project activity timeline (processes + deploys)",
+		register: func(s *MCPServer) { tools.RegisterEvents(s.server, s.exec) },
+	},
+
+	// Async tools (5)
+	{
+		name: "zerops_manage", readOnly: false,
+		summary:  "start/stop/restart/scale (async)",
+		register: func(s *MCPServer) { tools.RegisterManage(s.server, s.exec) },
+	},
+	{
+		name: "zerops_env", readOnly: false,
+		summary:  "get/set/delete service or project env vars (set/delete async)",
+		register: func(s *MCPServer) { tools.RegisterEnv(s.server, s.exec) },
+	},
+	{
+		name: "zerops_import", readOnly: false,
+		summary:  "create services from import.yml (async; dryRun first)",
+		register: func(s *MCPServer) { tools.RegisterImport(s.server, s.exec) },
+	},
+	{
+		name: "zerops_delete", readOnly: false,
+		summary:  "remove service (user confirms, or confirm=true)",
+		register: func(s *MCPServer) { tools.RegisterDelete(s.server, s.exec) },
+	},
+	{
+		name: "zerops_subdomain", readOnly: false,
+		summary:  "enable/disable public zerops.app subdomain (async)",
+		register: func(s *MCPServer) { tools.RegisterSubdomain(s.server, s.exec) },
+	},
+
+	// Deploy (calls zcli, not zaia)
+	{
+		name: "zerops_deploy", readOnly: false,
+		summary:  "zcli push code from a dir with zerops.yml",
+		register: func(s *MCPServer) { tools.RegisterDeploy(s.server, s.exec) },
+	},
+
+	// Server-local (no CLI)
+	{
+		name: "zerops_audit", readOnly: true,
+		summary:  "mutating operations done via this server",
+		register: func(s *MCPServer) { tools.RegisterAudit(s.server, s.audit) },
+	},
+	{
+		name: "zerops_doctor", readOnly: true,
+		summary:  "diagnose zaia/zcli install + auth (use on CLI errors)",
+		register: func(s *MCPServer) { tools.RegisterDoctor(s.server, s.doctor) },
+	},
+	{
+		name: "zerops_profiles", readOnly: true,
+		summary: "configured projects; pass profile=<name> to any tool to target one",
+		register: func(s *MCPServer) {
+			profiles, _ := executor.Find[*executor.ProfileExecutor](s.executor)
+			tools.RegisterProfiles(s.server, profiles)
+		},
+	},
+}
+
+// ToolNames returns the names of all tools the server can register.
+func ToolNames() []string {
+	names := make([]string, 0, len(toolRegistry))
+	for _, t := range toolRegistry {
+		names = append(names, t.name)
+	}
+	return names
+}
+
+// ValidateToolNames reports names (with or without the zerops_ prefix)
+// that are not tools of the server.
+func ValidateToolNames(names ...string) error {
+	known := ToolNames()
+	var unknown []string
+	for _, n := range names {
+		if !slices.Contains(known, toolName(n)) {
+			unknown = append(unknown, n)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown tool(s) %s (available: %s)", strings.Join(unknown, ", "), strings.Join(known, ", "))
+	}
+	return nil
+}
+
+func toolName(name string) string {
+	name = strings.TrimSpace(name)
+	if strings.HasPrefix(name, toolPrefix) {
+		return name
+	}
+	return toolPrefix + name
+}
+
+// enabledTools returns the registry entries selected by opts: EnableTools
+// (all when empty) minus DisableTools, limited to read-only tools in
+// ReadOnly mode.
+func enabledTools(opts Options) []toolSpec {
+	enable := normalizeNames(opts.EnableTools)
+	disable := normalizeNames(opts.DisableTools)
+	var out []toolSpec
+	for _, t := range toolRegistry {
+		switch {
+		case len(enable) > 0 && !slices.Contains(enable, t.name):
+		case slices.Contains(disable, t.name):
+		case opts.ReadOnly && !t.readOnly:
+		default:
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func normalizeNames(names []string) []string {
+	out := make([]string, 0, len(names))
+	for _, n := range names {
+		out = append(out, toolName(n))
+	}
+	return out
+}
+
+// toolMention matches tool names in the instructions text.
+var toolMention = regexp.MustCompile(`\bzerops_[a-z]+\b`)
+
+// readOnlyMiddleware rejects tool calls that would change project state.
+// Mutating tools are not registered in read-only mode; this also covers
+// zerops_process action=cancel, which shares a read-only tool.
+func readOnlyMiddleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			callReq, ok := req.(*mcp.CallToolRequest)
+			if method != "tools/call" || !ok {
+				return next(ctx, method, req)
+			}
+			name, args := callReq.Params.Name, callReq.Params.Arguments
+			if isMutatingCall(name, args) || isProcessCancel(name, args) {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{&mcp.TextContent{Text: `{"code":"READ_ONLY","error":"server runs in read-only mode; this call would change the project"}`}},
+					IsError: true,
+				}, nil
+			}
+			return next(ctx, method, req)
+		}
+	}
+}
+
+func isProcessCancel(tool string, raw json.RawMessage) bool {
+	if tool != "zerops_process" {
+		return false
+	}
+	var in struct {
+		Action string `json:"action"`
+	}
+	_ = json.Unmarshal(raw, &in)
+	return in.Action == "cancel"
+}
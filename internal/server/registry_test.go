@@ -0,0 +1,160 @@
+package server
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/zeropsio/zaia-mcp/internal/executor"
+)
+
+func connect(t *testing.T, s *MCPServer) *mcp.ClientSession {
+	t.Helper()
+	t1, t2 := mcp.NewInMemoryTransports()
+	if _, err := s.Server().Connect(t.Context(), t1, nil); err != nil {
+		t.Fatal(err)
+	}
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0.0.1"}, nil).Connect(t.Context(), t2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cs.Close() })
+	return cs
+}
+
+func listTools(t *testing.T, cs *mcp.ClientSession) map[string]*mcp.Tool {
+	t.Helper()
+	out := map[string]*mcp.Tool{}
+	for tool, err := range cs.Tools(t.Context(), nil) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		out[tool.Name] = tool
+	}
+	return out
+}
+
+func TestToolRegistry_ReadOnlyMatchesAnnotations(t *testing.T) {
+	registered := listTools(t, connect(t, NewWithExecutor(executor.NewMockExecutor())))
+	if len(registered) != len(toolRegistry) {
+		t.Errorf("registered %d tools, registry has %d", len(registered), len(toolRegistry))
+	}
+	for _, spec := range toolRegistry {
+		tool, ok := registered[spec.name]
+		if !ok {
+			t.Errorf("%s: not registered under its registry name", spec.name)
+			continue
+		}
+		if tool.Annotations == nil || tool.Annotations.ReadOnlyHint != spec.readOnly {
+			t.Errorf("%s: registry readOnly=%v does not match the ReadOnlyHint annotation", spec.name, spec.readOnly)
+		}
+	}
+}
+
+func TestReadOnlyMode(t *testing.T) {
+	mock := executor.NewMockExecutor().WithDefault(executor.SyncResult(`{}`))
+	s := NewWithOptions(mock, Options{ReadOnly: true})
+	cs := connect(t, s)
+
+	var names []string
+	for name := range listTools(t, cs) {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	want := []string{"zerops_audit", "zerops_discover", "zerops_doctor", "zerops_events", "zerops_knowledge", "zerops_logs", "zerops_process", "zerops_profiles", "zerops_validate", "zerops_wait"}
+	if !slices.Equal(names, want) {
+		t.Errorf("tools: got %v, want %v", names, want)
+	}
+
+	instructions := cs.InitializeResult().Instructions
+	for _, hidden := range []string{"manage →", "configure →", "delete →", "zerops_subdomain"} {
+		if strings.Contains(instructions, hidden) {
+			t.Errorf("read-only instructions advertise %q", hidden)
+		}
+	}
+	if !strings.Contains(instructions, "read-only") {
+		t.Error("instructions do not mention read-only mode")
+	}
+
+	prompts, err := cs.ListPrompts(t.Context(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prompts.Prompts) != 1 || prompts.Prompts[0].Name != "debug-service" {
+		t.Errorf("read-only prompts: got %d, want only debug-service", len(prompts.Prompts))
+	}
+
+	result, err := cs.CallTool(t.Context(), &mcp.CallToolParams{
+		Name:      "zerops_process",
+		Arguments: map[string]any{"processId": "p1", "action": "cancel"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.IsError {
+		t.Error("process cancel succeeded in read-only mode")
+	}
+	if n := mock.CallCount("zaia", "cancel p1"); n != 0 {
+		t.Errorf("cancel reached the CLI %d times", n)
+	}
+}
+
+func TestToolFilters(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want []string
+	}{
+		{"enable with and without prefix", Options{EnableTools: []string{"discover", "zerops_logs", "deploy"}}, []string{"zerops_discover", "zerops_logs", "zerops_deploy"}},
+		{"enable and read-only", Options{EnableTools: []string{"discover", "deploy"}, ReadOnly: true}, []string{"zerops_discover"}},
+		{"disable", Options{DisableTools: []string{"delete", "zerops_deploy"}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, spec := range enabledTools(tt.opts) {
+				got = append(got, spec.name)
+			}
+			if tt.want == nil {
+				if len(got) != len(toolRegistry)-2 || slices.Contains(got, "zerops_delete") || slices.Contains(got, "zerops_deploy") {
+					t.Errorf("got %v", got)
+				}
+				return
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateToolNames(t *testing.T) {
+	if err := ValidateToolNames("discover", "zerops_deploy"); err != nil {
+		t.Errorf("valid names: %v", err)
+	}
+	err := ValidateToolNames("discover", "configure")
+	if err == nil || !strings.Contains(err.Error(), "configure") {
+		t.Errorf("got %v, want error naming configure", err)
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	mock := executor.NewMockExecutor().
+		WithZaiaResponse("discover", executor.SyncResult(`{"services":[{"hostname":"api"},{"hostname":"db"}]}`))
+	cs := connect(t, NewWithExecutor(mock))
+
+	if cs.InitializeResult().Capabilities.Completions == nil {
+		t.Fatal("completions capability not advertised")
+	}
+	result, err := cs.Complete(t.Context(), &mcp.CompleteParams{
+		Ref:      &mcp.CompleteReference{Type: "ref/prompt", Name: "debug-service"},
+		Argument: mcp.CompleteParamsArgument{Name: "serviceHostname", Value: "d"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := result.Completion.Values; !slices.Equal(got, []string{"db"}) {
+		t.Errorf("completion: got %v, want [db]", got)
+	}
+}
@@ -3,75 +3,128 @@ package server
 import (
 	"context"
 	"log/slog"
+	"maps"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/zeropsio/zaia-mcp/internal/audit"
+	"github.com/zeropsio/zaia-mcp/internal/completion"
+	"github.com/zeropsio/zaia-mcp/internal/config"
+	"github.com/zeropsio/zaia-mcp/internal/doctor"
 	"github.com/zeropsio/zaia-mcp/internal/executor"
+	"github.com/zeropsio/zaia-mcp/internal/metrics"
+	"github.com/zeropsio/zaia-mcp/internal/prompts"
 	"github.com/zeropsio/zaia-mcp/internal/resources"
-	"github.com/zeropsio/zaia-mcp/internal/tools"
 )
 
-// Instructions is the MCP server instructions field.
-// Token budget: ~250 tokens. Update carefully.
-const Instructions = `Zerops
-
-PaaS. Full Linux containers (Incus), bare-metal, SSH access. Not serverless.
-
-Services
-Runtime: nodejs php python go rust java dotnet elixir gleam bun deno
-Container: alpine ubuntu docker(VM-based)
-DB: postgresql(default) mariadb clickhouse
-Cache: valkey(default, redis-compat) | keydb(deprecated)
-Search: meilisearch(default) elasticsearch typesense qdrant(internal-only)
-Queue: nats(default) kafka
-Storage: object-storage(S3/MinIO) shared-storage(POSIX)
-Web: nginx static(SPA-ready)
-
-Files
-zerops.yml = build + deploy + run config (per service)
-import.yml = infrastructure-as-code (services array, NO project: section)
-
-Critical Rules
-- Internal networking: ALWAYS http://, NEVER https:// (SSL terminates at L7 balancer)
-- Ports: 10-65435 only (0-9 and 65436+ reserved)
-- HA mode: immutable after creation (cannot change single↔HA)
-- mode: NON_HA or HA REQUIRED for databases/caches in import.yml (omitting passes dryRun but fails real import)
-- prepareCommands: cached. initCommands: run every start
-- Env var cross-ref: ${service_hostname} (underscore, not dash)
-- Cloudflare: MUST use "Full (strict)" SSL mode
-- No localhost — services communicate via hostname
-- zerops_subdomain enable: only works on deployed (ACTIVE) services. For new services use enableSubdomainAccess in import.yml
-
-Tools
-discover → project info + service list (call first)
-logs → service runtime/build logs
-validate → check zerops.yml/import.yml before deploy
-knowledge → BM25 search Zerops docs (use specific terms)
-manage → start/stop/restart/scale (async)
-configure → env vars + import infrastructure (async)
-delete → remove service (requires confirm)
-process → check async operation status
-events → project activity timeline (processes + deploys)
-
-Defaults (use unless user specifies otherwise)
-postgresql@16, valkey@7.2, meilisearch@1.10, nats@2.10, alpine base, NON_HA, SHARED CPU`
-
 // Version is set at build time via -ldflags
 var Version = "dev"
 
 // MCPServer wraps the MCP server with ZAIA executor.
 type MCPServer struct {
 	server   *mcp.Server
-	executor executor.Executor
+	executor executor.Executor // executor as configured
+	exec     executor.Executor // executor used by tools (with audit hooks and middlewares)
+	audit    *audit.Log
+	doctor   *doctor.Checker
+	metrics  *metrics.Metrics
+	logger   *slog.Logger // opts.Logger plus log notifications to clients
+	logs     *clientLogs
+	// sdkLogger is opts.Logger (may be nil), for the SDK's own logs; those
+	// are never forwarded to clients.
+	sdkLogger *slog.Logger
+}
+
+// Options configures optional server features.
+type Options struct {
+	Logger *slog.Logger
+	// AuditLog receives mutating tool calls. Nil keeps an in-memory log
+	// of recent entries for this process only.
+	AuditLog *audit.Log
+	// Metrics collects tool call and CLI execution metrics. Nil creates a
+	// new collector, readable through the zerops://metrics resource.
+	Metrics *metrics.Metrics
+	// Middlewares wrap the executor, first outermost. They see
+	// executor.CallInfo for CLI commands run by tool calls.
+	Middlewares []executor.Middleware
+	// ReadOnly registers only tools annotated ReadOnlyHint and rejects
+	// calls that would change the project (e.g. process cancel).
+	ReadOnly bool
+	// EnableTools limits the registered tools to these names (all when
+	// empty); DisableTools removes tools. Names may omit the zerops_ prefix;
+	// unknown names are ignored (see ValidateToolNames).
+	EnableTools  []string
+	DisableTools []string
+	// ProjectSummary adds the project's services (from `zaia discover` at
+	// startup) to the instructions.
+	ProjectSummary bool
+	// InstructionsBudget caps the instructions size in estimated tokens
+	// (see EstimateTokens); the project summary is shortened to fit.
+	// Zero uses DefaultInstructionsBudget, negative disables the cap.
+	InstructionsBudget int
+	// MinVersions are the minimum zaia/zcli versions the dependency check
+	// accepts, keyed by binary name (see doctor.Checker.MinVersions).
+	MinVersions map[string]string
 }
 
 // New creates a new ZAIA-MCP server with the default CLI executor.
 func New() *MCPServer {
-	return NewWithExecutor(executor.NewCLIExecutor("", ""))
+	return NewWithExecutor(DefaultExecutor())
 }
 
 // NewWithLogger creates a new ZAIA-MCP server with a logger and the default CLI executor.
 func NewWithLogger(logger *slog.Logger) *MCPServer {
-	return NewWithExecutorAndLogger(executor.NewCLIExecutor("", ""), logger)
+	return NewWithExecutorAndLogger(DefaultExecutor(), logger)
+}
+
+// DefaultExecutor returns the CLI executor used by New: zaia/zcli from PATH,
+// capped at executor.DefaultMaxConcurrent concurrent subprocesses, with
+// mutating commands serialized per service, transient failures of
+// read-only commands retried, and discover/search results cached.
+func DefaultExecutor() executor.Executor {
+	return DefaultExecutorWithEnv(executor.DefaultEnvPolicy())
+}
+
+// DefaultExecutorWithEnv is DefaultExecutor with an explicit subprocess
+// environment policy.
+func DefaultExecutorWithEnv(policy executor.EnvPolicy) executor.Executor {
+	return cliChain("", "", policy)
+}
+
+// ExecutorFromConfig builds the executor for cfg: the DefaultExecutor chain
+// for the top-level settings and, when profiles are configured, one chain
+// per profile behind an executor.ProfileExecutor. Each profile has its own
+// cache and concurrency limit.
+func ExecutorFromConfig(cfg *config.Config) (executor.Executor, error) {
+	policy, err := cfg.EnvPolicy()
+	if err != nil {
+		return nil, err
+	}
+	base := DefaultExecutorWithEnv(policy)
+	if len(cfg.Profiles) == 0 {
+		return base, nil
+	}
+	profiles := make([]executor.Profile, 0, len(cfg.Profiles))
+	for _, name := range cfg.ProfileNames() {
+		p := cfg.Profiles[name]
+		policy, err := cfg.ProfileEnvPolicy(name)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, executor.Profile{
+			Name:        name,
+			Description: p.Description,
+			ProjectID:   p.ProjectID,
+			Exec:        cliChain(p.ZaiaBinary, p.ZcliBinary, policy),
+		})
+	}
+	return executor.NewProfileExecutor(base, cfg.DefaultProfile, profiles...)
+}
+
+func cliChain(zaiaBinary, zcliBinary string, policy executor.EnvPolicy) executor.Executor {
+	cli := executor.NewCLIExecutorWithEnv(zaiaBinary, zcliBinary, policy)
+	limited := executor.NewLimitedExecutor(cli, executor.DefaultMaxConcurrent)
+	retried := executor.NewRetryExecutor(limited, executor.DefaultRetryPolicy())
+	return executor.NewCachingExecutor(retried, executor.DefaultCacheTTLs())
 }
 
 // NewWithExecutor creates a new ZAIA-MCP server with a custom executor.
@@ -79,62 +132,162 @@ func NewWithExecutor(exec executor.Executor) *MCPServer {
 	return NewWithExecutorAndLogger(exec, nil)
 }
 
-// NewWithExecutorAndLogger creates a new ZAIA-MCP server with a custom executor and logger.
-func NewWithExecutorAndLogger(exec executor.Executor, logger *slog.Logger) *MCPServer {
+// NewWithExecutorAndLogger creates a new ZAIA-MCP server with a custom executor,
+// logger and executor middlewares (first outermost).
+func NewWithExecutorAndLogger(exec executor.Executor, logger *slog.Logger, mws ...executor.Middleware) *MCPServer {
+	return NewWithOptions(exec, Options{Logger: logger, Middlewares: mws})
+}
+
+// NewWithOptions creates a new ZAIA-MCP server with a custom executor and options.
+func NewWithOptions(exec executor.Executor, opts Options) *MCPServer {
+	auditLog := opts.AuditLog
+	if auditLog == nil {
+		auditLog = audit.NewMemoryLog(audit.DefaultKeep)
+	}
+
+	m := opts.Metrics
+	if m == nil {
+		m = metrics.New()
+	}
+
+	logs := newClientLogs()
+	var next slog.Handler = slog.DiscardHandler
+	if opts.Logger != nil {
+		next = opts.Logger.Handler()
+	}
+	logger := slog.New(newClientLogHandler(next, logs))
+
+	// audit.WrapExecutor is outermost so it records the final exit code.
+	chained := executor.Chain(exec, append([]executor.Middleware{audit.WrapExecutor, m.WrapExecutor, executor.Logging(logger)}, opts.Middlewares...)...)
+
+	specs := enabledTools(opts)
+	modes := instructionModes{
+		readOnly: opts.ReadOnly,
+		filtered: len(opts.EnableTools) > 0 || len(opts.DisableTools) > 0,
+	}
+	if profiles, ok := executor.Find[*executor.ProfileExecutor](exec); ok {
+		for _, p := range profiles.Profiles() {
+			name := p.Name
+			if p.Default {
+				name += " (default)"
+			}
+			modes.profiles = append(modes.profiles, name)
+		}
+	}
+	var project []string
+	if opts.ProjectSummary {
+		project = projectSummary(chained)
+	}
+	budget := opts.InstructionsBudget
+	if budget == 0 {
+		budget = DefaultInstructionsBudget
+	}
+	instructions := buildInstructions(specs, modes, project, budget)
+	if budget > 0 && EstimateTokens(instructions) > budget {
+		logger.Warn("server instructions exceed the token budget", "tokens", EstimateTokens(instructions), "budget", budget)
+	}
+
 	srv := mcp.NewServer(
 		&mcp.Implementation{
 			Name:    "zaia-mcp",
 			Version: Version,
 		},
 		&mcp.ServerOptions{
-			Instructions: Instructions,
-			Logger:       logger,
+			Instructions:      instructions,
+			Logger:            opts.Logger,
+			CompletionHandler: completion.New(chained).Complete,
 		},
 	)
 
+	logs.attach(srv)
+
+	checker := doctor.NewChecker(chained)
+	maps.Copy(checker.MinVersions, opts.MinVersions)
+
 	s := &MCPServer{
-		server:   srv,
-		executor: exec,
+		server:    srv,
+		executor:  exec,
+		exec:      chained,
+		audit:     auditLog,
+		doctor:    checker,
+		metrics:   m,
+		logger:    logger,
+		logs:      logs,
+		sdkLogger: opts.Logger,
+	}
+
+	// The client log middleware is outermost so every handler's context
+	// names the session its logs go to.
+	mws := []mcp.Middleware{
+		logs.middleware(), callInfoMiddleware(), metricsMiddleware(m), toolLogMiddleware(logger), auditMiddleware(auditLog, logger),
 	}
+	if opts.ReadOnly {
+		// Innermost, so rejected calls are still counted and audited.
+		mws = append(mws, readOnlyMiddleware())
+	}
+	srv.AddReceivingMiddleware(mws...)
 
-	s.registerTools()
+	s.registerTools(specs)
 	s.registerResources()
+	s.registerPrompts(specs)
 
 	return s
 }
 
 // Run starts the MCP server over STDIO transport.
+// Dependencies are probed in the background and the outcome is logged;
+// logs outside a request, like this one, are also sent to the client.
 func (s *MCPServer) Run(ctx context.Context) error {
+	s.logs.broadcastOutOfRequest()
+	go s.logDependencies(ctx)
 	return s.server.Run(ctx, &mcp.StdioTransport{})
 }
 
+// logDependencies runs the doctor check once and logs the result.
+func (s *MCPServer) logDependencies(ctx context.Context) {
+	report := s.doctor.Check(ctx)
+	attrs := []any{"path", report.PATH}
+	for _, b := range report.Binaries {
+		attrs = append(attrs, slog.Group(b.Name,
+			"path", b.Path,
+			"found", b.Found,
+			"version", b.Version,
+			"auth", b.Auth,
+			"error", b.Error,
+		))
+	}
+	if report.OK {
+		s.logger.Info("dependency check passed", attrs...)
+		return
+	}
+	attrs = append(attrs, "remediation", report.Remediation)
+	s.logger.Warn("dependency check failed; call zerops_doctor for details", attrs...)
+}
+
 // Server returns the underlying MCP server (for testing).
 func (s *MCPServer) Server() *mcp.Server {
 	return s.server
 }
 
-// registerTools registers all 11 MCP tools.
-func (s *MCPServer) registerTools() {
-	// Sync tools (6)
-	tools.RegisterDiscover(s.server, s.executor)
-	tools.RegisterLogs(s.server, s.executor)
-	tools.RegisterValidate(s.server, s.executor)
-	tools.RegisterKnowledge(s.server, s.executor)
-	tools.RegisterProcess(s.server, s.executor)
-	tools.RegisterEvents(s.server, s.executor)
-
-	// Async tools (5)
-	tools.RegisterManage(s.server, s.executor)
-	tools.RegisterEnv(s.server, s.executor)
-	tools.RegisterImport(s.server, s.executor)
-	tools.RegisterDelete(s.server, s.executor)
-	tools.RegisterSubdomain(s.server, s.executor)
-
-	// Deploy (calls zcli, not zaia)
-	tools.RegisterDeploy(s.server, s.executor)
+// registerTools registers the tools selected by the options (all 15 by default).
+func (s *MCPServer) registerTools(specs []toolSpec) {
+	for _, t := range specs {
+		t.register(s)
+	}
 }
 
 // registerResources registers MCP resources.
 func (s *MCPServer) registerResources() {
-	resources.RegisterKnowledgeResources(s.server, s.executor)
+	resources.RegisterKnowledgeResources(s.server, s.exec)
+	resources.RegisterAuditResource(s.server, s.audit)
+	resources.RegisterMetricsResource(s.server, s.metrics)
+}
+
+// registerPrompts registers the workflow prompts whose tools are all enabled.
+func (s *MCPServer) registerPrompts(specs []toolSpec) {
+	names := make([]string, 0, len(specs))
+	for _, t := range specs {
+		names = append(names, t.name)
+	}
+	prompts.Register(s.server, s.exec, names)
 }
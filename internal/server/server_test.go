@@ -32,7 +32,8 @@ func TestNewWithExecutor(t *testing.T) {
 }
 
 func TestInstructions(t *testing.T) {
-	if Instructions == "" {
+	instructions := DefaultInstructions()
+	if instructions == "" {
 		t.Fatal("Instructions is empty")
 	}
 	// Verify key content is present
@@ -45,7 +46,7 @@ func TestInstructions(t *testing.T) {
 		"Critical Rules",
 	}
 	for _, check := range checks {
-		if !strings.Contains(Instructions, check) {
+		if !strings.Contains(instructions, check) {
 			t.Errorf("Instructions missing %q", check)
 		}
 	}
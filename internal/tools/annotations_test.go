@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/zeropsio/zaia-mcp/internal/audit"
+	"github.com/zeropsio/zaia-mcp/internal/doctor"
 	"github.com/zeropsio/zaia-mcp/internal/executor"
 	"github.com/zeropsio/zaia-mcp/internal/tools"
 )
@@ -15,7 +17,7 @@ func TestAnnotations_AllToolsHaveTitleAndAnnotations(t *testing.T) {
 		nil,
 	)
 
-	// Register all 11 tools
+	// Register all tools
 	tools.RegisterDiscover(srv, mock)
 	tools.RegisterLogs(srv, mock)
 	tools.RegisterValidate(srv, mock)
@@ -27,6 +29,9 @@ func TestAnnotations_AllToolsHaveTitleAndAnnotations(t *testing.T) {
 	tools.RegisterDelete(srv, mock)
 	tools.RegisterSubdomain(srv, mock)
 	tools.RegisterDeploy(srv, mock)
+	tools.RegisterAudit(srv, audit.NewMemoryLog(10))
+	tools.RegisterDoctor(srv, doctor.NewChecker(mock))
+	tools.RegisterProfiles(srv, nil)
 
 	ctx := t.Context()
 	t1, t2 := mcp.NewInMemoryTransports()
@@ -70,6 +75,9 @@ func TestAnnotations_AllToolsHaveTitleAndAnnotations(t *testing.T) {
 		"zerops_delete":    {title: "Delete Service", destructive: boolPtr(true)},
 		"zerops_subdomain": {title: "Manage Subdomain", destructive: boolPtr(false), idempotent: true},
 		"zerops_deploy":    {title: "Deploy Code", destructive: boolPtr(false)},
+		"zerops_audit":     {title: "Audit Log", readOnly: true, idempotent: true, openWorld: boolPtr(false)},
+		"zerops_doctor":    {title: "Diagnose Setup", readOnly: true, idempotent: true},
+		"zerops_profiles":  {title: "List Profiles", readOnly: true, idempotent: true, openWorld: boolPtr(false)},
 	}
 
 	for name, exp := range tests {
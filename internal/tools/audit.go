@@ -0,0 +1,60 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/zeropsio/zaia-mcp/internal/audit"
+)
+
+// AuditInput is the input schema for zerops_audit.
+type AuditInput struct {
+	Limit           int    `json:"limit,omitempty"`
+	Tool            string `json:"tool,omitempty"`
+	ServiceHostname string `json:"serviceHostname,omitempty"`
+	Profile         string `json:"profile,omitempty"`
+}
+
+// RegisterAudit registers the zerops_audit tool on the server.
+// It reads back the audit log kept by the server; no CLI is called.
+func RegisterAudit(srv *mcp.Server, log *audit.Log) {
+	mcp.AddTool(srv, &mcp.Tool{
+		Name: "zerops_audit",
+		Annotations: &mcp.ToolAnnotations{
+			Title:          "Audit Log",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+			OpenWorldHint:  boolPtr(false),
+		},
+		Description: `Show recent mutating operations performed through this MCP server.
+
+Covers manage, env set/delete, import, delete, subdomain and deploy calls.
+Secrets (env values, import YAML) are redacted.
+
+Parameters:
+- limit: Max entries (default 20)
+- tool: Filter by tool name (e.g. zerops_manage)
+- serviceHostname: Filter by service
+- profile: Filter by profile (calls made with profile=<name>)
+
+Returns entries newest first: time, client, tool, args, exitCode, processIds, durationMs, error.`,
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input AuditInput) (*mcp.CallToolResult, any, error) {
+		entries := log.Recent(audit.Filter{
+			Tool:            input.Tool,
+			ServiceHostname: input.ServiceHostname,
+			Profile:         input.Profile,
+			Limit:           input.Limit,
+		})
+		b, err := json.Marshal(map[string]interface{}{
+			"entries": entries,
+			"count":   len(entries),
+		})
+		if err != nil {
+			return errorResult("encoding audit entries: " + err.Error()), nil, nil
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: string(b)}},
+		}, nil, nil
+	})
+}
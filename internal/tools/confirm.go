@@ -0,0 +1,130 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/zeropsio/zaia-mcp/internal/executor"
+)
+
+// confirmField is the elicitation form field the user types the target into.
+const confirmField = "confirmation"
+
+// destructiveOp describes an operation the user confirms via elicitation.
+type destructiveOp struct {
+	verb     string // e.g. "Delete"
+	hostname string // target service; empty for project scope
+	loss     string // what the operation destroys
+}
+
+// elicitationSupported reports whether the client of req can answer form
+// elicitation requests.
+func elicitationSupported(req *mcp.CallToolRequest) bool {
+	if req == nil || req.Session == nil {
+		return false
+	}
+	params := req.Session.InitializeParams()
+	if params == nil || params.Capabilities == nil || params.Capabilities.Elicitation == nil {
+		return false
+	}
+	caps := params.Capabilities.Elicitation
+	return caps.Form != nil || caps.URL == nil
+}
+
+// confirmDestructive asks the user to confirm op by typing the service
+// hostname (or the project name for project scope). It returns nil when
+// the user confirmed, otherwise the result to return instead of running
+// the operation. Callers check elicitationSupported first.
+func confirmDestructive(ctx context.Context, req *mcp.CallToolRequest, exec executor.Executor, op destructiveOp) *mcp.CallToolResult {
+	target, about, failure := describeTarget(ctx, exec, op.hostname)
+	if failure != nil {
+		return failure
+	}
+	// The field is not marked required: the SDK validates the content of
+	// declined responses too, and the typed value is checked below anyway.
+	res, err := req.Session.Elicit(ctx, &mcp.ElicitParams{
+		Message: fmt.Sprintf("%s %s?\n\n%s\n\nType %s to confirm.", op.verb, about, op.loss, target),
+		RequestedSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				confirmField: map[string]any{
+					"type":        "string",
+					"title":       "Confirmation",
+					"description": "Type " + target + " to confirm",
+				},
+			},
+		},
+	})
+	if err != nil {
+		return confirmError("CONFIRMATION_FAILED", "could not ask the user for confirmation: "+err.Error(), "")
+	}
+	if res.Action != "accept" {
+		return confirmError("CONFIRMATION_DECLINED", "the user did not confirm; nothing was changed",
+			"Do not retry unless the user asks for it again")
+	}
+	if typed, _ := res.Content[confirmField].(string); strings.TrimSpace(typed) != target {
+		return confirmError("CONFIRMATION_MISMATCH", fmt.Sprintf("the user typed %q instead of %q; nothing was changed", typed, target),
+			"Ask the user whether to try again")
+	}
+	return nil
+}
+
+// describeTarget looks up the service (or project) via `zaia discover` and
+// returns the text the user must type and a description such as
+// `service "db" (postgresql@16)`. A CLI failure is returned as the failure result.
+func describeTarget(ctx context.Context, exec executor.Executor, hostname string) (target, about string, failure *mcp.CallToolResult) {
+	args := []string{"discover"}
+	if hostname != "" {
+		args = append(args, "--service", hostname)
+	}
+	result, err := exec.RunZaia(ctx, args...)
+	if err != nil {
+		failure, _, _ = cliErrorResult(err)
+		return "", "", failure
+	}
+	if result.ExitCode != 0 {
+		failure, _ = ResultFromCLI(result)
+		return "", "", failure
+	}
+	var data struct {
+		Project struct {
+			Name string `json:"name"`
+		} `json:"project"`
+		Services []struct {
+			Hostname string `json:"hostname"`
+			Type     string `json:"type"`
+		} `json:"services"`
+	}
+	if resp, err := ParseCLIResponse(result); err == nil {
+		_ = json.Unmarshal(resp.Data, &data)
+	}
+	if hostname == "" {
+		target = data.Project.Name
+		if target == "" {
+			target = "project"
+		}
+		return target, fmt.Sprintf("project %q", target), nil
+	}
+	about = fmt.Sprintf("service %q", hostname)
+	for _, svc := range data.Services {
+		if svc.Hostname == hostname && svc.Type != "" {
+			about += " (" + svc.Type + ")"
+		}
+	}
+	return hostname, about, nil
+}
+
+func confirmError(code, msg, suggestion string) *mcp.CallToolResult {
+	b, _ := json.Marshal(struct {
+		Code       string `json:"code"`
+		Error      string `json:"error"`
+		Suggestion string `json:"suggestion,omitempty"`
+	}{code, msg, suggestion})
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(b)}},
+		IsError: true,
+	}
+}
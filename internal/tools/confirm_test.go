@@ -0,0 +1,133 @@
+package tools_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/zeropsio/zaia-mcp/internal/executor"
+	"github.com/zeropsio/zaia-mcp/internal/tools"
+)
+
+const discoverDB = `{"project":{"name":"demo"},"services":[{"hostname":"db","type":"postgresql@16"}]}`
+
+// typeAnswer returns an elicitation handler that records the request and
+// types answer.
+func typeAnswer(answer string, got **mcp.ElicitParams) func(*mcp.ElicitParams) *mcp.ElicitResult {
+	return func(p *mcp.ElicitParams) *mcp.ElicitResult {
+		*got = p
+		return &mcp.ElicitResult{Action: "accept", Content: map[string]any{"confirmation": answer}}
+	}
+}
+
+func TestDelete_ElicitationConfirmed(t *testing.T) {
+	mock := executor.NewMockExecutor()
+	mock.ExpectZaia("discover", "--service", "db").Return(executor.SyncResult(discoverDB))
+	mock.ExpectZaia("delete", "--service", "db", "--confirm").Return(executor.AsyncResult(`[{"processId":"p1"}]`))
+	srv := testServer(t, tools.RegisterDelete, mock)
+
+	var asked *mcp.ElicitParams
+	result := callToolElicit(t, srv, "zerops_delete", map[string]interface{}{"serviceHostname": "db"}, typeAnswer("db", &asked))
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", getTextContent(t, result))
+	}
+	if asked == nil {
+		t.Fatal("no elicitation request")
+	}
+	for _, want := range []string{`Delete service "db" (postgresql@16)?`, "removed permanently", "Type db to confirm"} {
+		if !strings.Contains(asked.Message, want) {
+			t.Errorf("message %q does not contain %q", asked.Message, want)
+		}
+	}
+	mock.AssertExpectations(t)
+}
+
+func TestDelete_ElicitationDeclined(t *testing.T) {
+	mock := executor.NewMockExecutor().WithZaiaResponse("discover", executor.SyncResult(discoverDB))
+	srv := testServer(t, tools.RegisterDelete, mock)
+
+	for _, action := range []string{"decline", "cancel"} {
+		result := callToolElicit(t, srv, "zerops_delete", map[string]interface{}{
+			"serviceHostname": "db",
+			"confirm":         true,
+		}, func(*mcp.ElicitParams) *mcp.ElicitResult { return &mcp.ElicitResult{Action: action} })
+		if !result.IsError || !strings.Contains(getTextContent(t, result), "CONFIRMATION_DECLINED") {
+			t.Errorf("%s: got %s, want CONFIRMATION_DECLINED", action, getTextContent(t, result))
+		}
+	}
+	if n := mock.CallCount("zaia", "delete --service db --confirm"); n != 0 {
+		t.Errorf("delete ran %d times without confirmation", n)
+	}
+}
+
+func TestDelete_ElicitationMismatch(t *testing.T) {
+	mock := executor.NewMockExecutor().WithZaiaResponse("discover", executor.SyncResult(discoverDB))
+	srv := testServer(t, tools.RegisterDelete, mock)
+
+	var asked *mcp.ElicitParams
+	result := callToolElicit(t, srv, "zerops_delete", map[string]interface{}{"serviceHostname": "db"}, typeAnswer("yes", &asked))
+	if !result.IsError || !strings.Contains(getTextContent(t, result), "CONFIRMATION_MISMATCH") {
+		t.Errorf("got %s, want CONFIRMATION_MISMATCH", getTextContent(t, result))
+	}
+	if n := mock.CallCount("zaia", "delete --service db --confirm"); n != 0 {
+		t.Errorf("delete ran %d times after a wrong hostname", n)
+	}
+}
+
+func TestDelete_ElicitationUnknownService(t *testing.T) {
+	mock := executor.NewMockExecutor().
+		WithZaiaResponse("discover", executor.ErrorResult("SERVICE_NOT_FOUND", "Service nope not found", "", 1))
+	srv := testServer(t, tools.RegisterDelete, mock)
+
+	var asked *mcp.ElicitParams
+	result := callToolElicit(t, srv, "zerops_delete", map[string]interface{}{"serviceHostname": "nope"}, typeAnswer("nope", &asked))
+	if !result.IsError || !strings.Contains(getTextContent(t, result), "SERVICE_NOT_FOUND") {
+		t.Errorf("got %s, want SERVICE_NOT_FOUND", getTextContent(t, result))
+	}
+	if asked != nil {
+		t.Error("asked the user to confirm deleting a missing service")
+	}
+}
+
+func TestManage_StopElicitation(t *testing.T) {
+	mock := executor.NewMockExecutor().
+		WithZaiaResponse("discover", executor.SyncResult(discoverDB)).
+		WithDefault(executor.AsyncResult(`[{"processId":"p1"}]`))
+	srv := testServer(t, tools.RegisterManage, mock)
+
+	var asked *mcp.ElicitParams
+	result := callToolElicit(t, srv, "zerops_manage", map[string]interface{}{"action": "stop", "serviceHostname": "db"}, typeAnswer("db", &asked))
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", getTextContent(t, result))
+	}
+	if asked == nil || !strings.Contains(asked.Message, `Stop service "db"`) {
+		t.Errorf("elicitation = %+v, want a stop confirmation", asked)
+	}
+
+	asked = nil
+	callToolElicit(t, srv, "zerops_manage", map[string]interface{}{"action": "start", "serviceHostname": "db"}, typeAnswer("db", &asked))
+	if asked != nil {
+		t.Error("start asked for confirmation")
+	}
+}
+
+func TestEnv_DeleteProjectElicitation(t *testing.T) {
+	mock := executor.NewMockExecutor()
+	mock.ExpectZaia("discover").Return(executor.SyncResult(discoverDB))
+	mock.ExpectZaia("env", "delete", "--project", "API_KEY").Return(executor.AsyncResult(`[{"processId":"p1"}]`))
+	srv := testServer(t, tools.RegisterEnv, mock)
+
+	var asked *mcp.ElicitParams
+	result := callToolElicit(t, srv, "zerops_env", map[string]interface{}{
+		"action":    "delete",
+		"project":   true,
+		"variables": []any{"API_KEY"},
+	}, typeAnswer("demo", &asked))
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", getTextContent(t, result))
+	}
+	if asked == nil || !strings.Contains(asked.Message, `Delete env vars API_KEY of project "demo"?`) {
+		t.Errorf("elicitation = %+v", asked)
+	}
+	mock.AssertExpectations(t)
+}
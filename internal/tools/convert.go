@@ -1,8 +1,11 @@
 package tools
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/zeropsio/zaia-mcp/internal/executor"
@@ -71,6 +74,9 @@ func ToMCPResult(resp *CLIResponse) *mcp.CallToolResult {
 // It never returns a non-nil error; parse failures are converted to MCP error results.
 // The error return exists only to satisfy the MCP handler signature.
 func ResultFromCLI(result *executor.Result) (*mcp.CallToolResult, error) {
+	if result.StdoutOverflow != nil {
+		return truncatedResult(result), nil
+	}
 	resp, err := ParseCLIResponse(result)
 	if err != nil {
 		return &mcp.CallToolResult{
@@ -80,20 +86,95 @@ func ResultFromCLI(result *executor.Result) (*mcp.CallToolResult, error) {
 			IsError: true,
 		}, nil
 	}
-	return ToMCPResult(resp), nil
+	mcpResult := ToMCPResult(resp)
+	mcpResult.Meta = resultMeta(result)
+	return mcpResult, nil
+}
+
+// resultMeta exposes executor bookkeeping (retries, cache hits) as result _meta.
+// Returns nil when there is nothing to report.
+func resultMeta(result *executor.Result) mcp.Meta {
+	var meta mcp.Meta
+	if result.Attempts > 1 {
+		meta = mcp.Meta{"attempts": result.Attempts}
+	}
+	if !result.CachedAt.IsZero() {
+		if meta == nil {
+			meta = mcp.Meta{}
+		}
+		meta["cached"] = true
+		meta["cachedAt"] = result.CachedAt.UTC().Format(time.RFC3339)
+	}
+	return meta
+}
+
+// freshContext marks ctx to bypass the read-through cache when fresh is set.
+func freshContext(ctx context.Context, fresh bool) context.Context {
+	if fresh {
+		return executor.WithFresh(ctx)
+	}
+	return ctx
+}
+
+// truncatedResult reports CLI output that exceeded the capture limit.
+// The leading JSON block marks the truncation (original size and the file
+// holding the complete output); the partial raw output follows.
+func truncatedResult(result *executor.Result) *mcp.CallToolResult {
+	overflow := result.StdoutOverflow
+	note := map[string]interface{}{
+		"truncated":    true,
+		"originalSize": overflow.Size,
+		"shownSize":    len(result.Stdout),
+		"suggestion":   "Output exceeded the size limit. Narrow the request (e.g. lower limit, shorter since, severity filter).",
+	}
+	if overflow.Path != "" {
+		note["outputFile"] = overflow.Path
+	}
+	b, _ := json.Marshal(note)
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: string(b)},
+			&mcp.TextContent{Text: string(result.Stdout)},
+		},
+		IsError: result.ExitCode != 0,
+	}
 }
 
 // cliErrorResult converts a Go error from CLI execution into an MCP error result.
 // Used by all zaia-backed tools to convert exec failures to user-visible errors.
 func cliErrorResult(err error) (*mcp.CallToolResult, any, error) {
+	if result := timeoutResult(err); result != nil {
+		return result, nil, nil
+	}
 	return errorResult("CLI execution failed: " + err.Error()), nil, nil
 }
 
 // zcliErrorResult converts a Go error from zcli execution into an MCP error result.
 func zcliErrorResult(err error) (*mcp.CallToolResult, any, error) {
+	if result := timeoutResult(err); result != nil {
+		return result, nil, nil
+	}
 	return errorResult("zcli execution failed: " + err.Error()), nil, nil
 }
 
+// timeoutResult converts an executor.TimeoutError into a structured MCP error result.
+// Returns nil if err is not a timeout.
+func timeoutResult(err error) *mcp.CallToolResult {
+	var timeoutErr *executor.TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		return nil
+	}
+	b, _ := json.Marshal(map[string]interface{}{
+		"code":       "TIMEOUT",
+		"error":      timeoutErr.Error(),
+		"command":    timeoutErr.Command,
+		"elapsedMs":  timeoutErr.Elapsed.Milliseconds(),
+		"timeoutMs":  timeoutErr.Timeout.Milliseconds(),
+		"suggestion": "The command was killed. Retry, or narrow the request (e.g. lower limit, shorter since).",
+	})
+	return errorResult(string(b))
+}
+
 func formatError(resp *CLIResponse) string {
 	result := map[string]interface{}{
 		"code":  resp.Code,
@@ -2,7 +2,11 @@ package tools
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/zeropsio/zaia-mcp/internal/executor"
@@ -161,6 +165,85 @@ func TestResultFromCLI_ParseError(t *testing.T) {
 	}
 }
 
+func TestResultFromCLI_AttemptsMeta(t *testing.T) {
+	cliResult := executor.SyncResult(`{"ok":true}`)
+	result, _ := ResultFromCLI(cliResult)
+	if result.Meta != nil {
+		t.Errorf("single attempt should not set meta, got %v", result.Meta)
+	}
+
+	cliResult.Attempts = 3
+	result, _ = ResultFromCLI(cliResult)
+	if result.Meta["attempts"] != 3 {
+		t.Errorf("got meta %v, want attempts=3", result.Meta)
+	}
+}
+
+func TestResultFromCLI_Truncated(t *testing.T) {
+	cliResult := &executor.Result{
+		Stdout:         []byte(`{"type":"sync","status":"ok","data":{"entries":[`),
+		StdoutOverflow: &executor.Overflow{Size: 5000, Path: "/tmp/zaia-mcp-stdout-1.json"},
+	}
+	result, err := ResultFromCLI(cliResult)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Error("truncated output of a successful command should not be an error")
+	}
+	if len(result.Content) != 2 {
+		t.Fatalf("got %d content items, want 2", len(result.Content))
+	}
+	var note map[string]interface{}
+	if err := json.Unmarshal([]byte(mustText(t, result)), &note); err != nil {
+		t.Fatalf("truncation note should be JSON: %v", err)
+	}
+	if note["truncated"] != true {
+		t.Errorf("got truncated %v, want true", note["truncated"])
+	}
+	if note["originalSize"] != float64(5000) {
+		t.Errorf("got originalSize %v, want 5000", note["originalSize"])
+	}
+	if note["outputFile"] != "/tmp/zaia-mcp-stdout-1.json" {
+		t.Errorf("got outputFile %v", note["outputFile"])
+	}
+}
+
+func TestCLIErrorResult_Timeout(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", &executor.TimeoutError{
+		Command: "zaia logs --service api",
+		Timeout: time.Minute,
+		Elapsed: 61 * time.Second,
+	})
+	result, _, _ := cliErrorResult(err)
+	if !result.IsError {
+		t.Fatal("timeout should set IsError=true")
+	}
+	var errObj map[string]interface{}
+	if err := json.Unmarshal([]byte(mustText(t, result)), &errObj); err != nil {
+		t.Fatalf("timeout text should be JSON: %v", err)
+	}
+	if errObj["code"] != "TIMEOUT" {
+		t.Errorf("got code %v, want TIMEOUT", errObj["code"])
+	}
+	if errObj["command"] != "zaia logs --service api" {
+		t.Errorf("got command %v", errObj["command"])
+	}
+	if errObj["elapsedMs"] != float64(61000) {
+		t.Errorf("got elapsedMs %v, want 61000", errObj["elapsedMs"])
+	}
+}
+
+func TestCLIErrorResult_Generic(t *testing.T) {
+	result, _, _ := cliErrorResult(errors.New("exec: not found"))
+	if !result.IsError {
+		t.Fatal("expected IsError=true")
+	}
+	if text := mustText(t, result); !strings.HasPrefix(text, "CLI execution failed:") {
+		t.Errorf("got text %q", text)
+	}
+}
+
 func mustText(t *testing.T, result *mcp.CallToolResult) string {
 	t.Helper()
 	if len(result.Content) == 0 {
@@ -9,8 +9,11 @@ import (
 
 // DeleteInput is the input schema for zerops_delete.
 type DeleteInput struct {
-	ServiceHostname string `json:"serviceHostname"`
-	Confirm         bool   `json:"confirm"`
+	ServiceHostname   string `json:"serviceHostname"`
+	Confirm           bool   `json:"confirm,omitempty" jsonschema:"must be true when the client cannot ask the user to confirm"`
+	WaitForCompletion bool   `json:"waitForCompletion,omitempty" jsonschema:"poll the returned processes until they end and return their final states"`
+	TimeoutSeconds    int    `json:"timeoutSeconds,omitempty" jsonschema:"max wait with waitForCompletion in seconds (default 300, max 1800)"`
+	Profile           string `json:"profile,omitempty" jsonschema:"config profile to use (see zerops_profiles)"`
 }
 
 // RegisterDelete registers the zerops_delete tool on the server.
@@ -29,14 +32,29 @@ Only deletes services, NOT the project itself.
 
 Parameters:
 - serviceHostname (required)
-- confirm (required, must be true)
+- confirm (must be true when the client has no elicitation support)
+- profile: Config profile to use (optional, see zerops_profiles)
 
-Returns process ID for tracking via zerops_process.`,
+Clients with elicitation support ask the user to confirm by typing the
+hostname; confirm is ignored there.
+
+Returns process ID for tracking via zerops_process, or with
+waitForCompletion=true waits for the process and returns its final state.`,
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input DeleteInput) (*mcp.CallToolResult, any, error) {
+		ctx = executor.WithProfile(ctx, input.Profile)
 		if input.ServiceHostname == "" {
 			return errorResult("serviceHostname is required"), nil, nil
 		}
-		if !input.Confirm {
+		if elicitationSupported(req) {
+			op := destructiveOp{
+				verb:     "Delete",
+				hostname: input.ServiceHostname,
+				loss:     "The service, its containers, stored data (files, database contents) and env vars are removed permanently. This cannot be undone.",
+			}
+			if res := confirmDestructive(ctx, req, exec, op); res != nil {
+				return res, nil, nil
+			}
+		} else if !input.Confirm {
 			return errorResult("confirm must be true to delete a service"), nil, nil
 		}
 
@@ -45,7 +63,6 @@ Returns process ID for tracking via zerops_process.`,
 		if err != nil {
 			return cliErrorResult(err)
 		}
-		mcpResult, _ := ResultFromCLI(result)
-		return mcpResult, nil, nil
+		return asyncResult(ctx, req, exec, result, newWaitOptions(input.WaitForCompletion, input.TimeoutSeconds))
 	})
 }
@@ -11,6 +11,7 @@ import (
 type DeployInput struct {
 	WorkingDir string `json:"workingDir,omitempty"`
 	ServiceID  string `json:"serviceId,omitempty"`
+	Profile    string `json:"profile,omitempty" jsonschema:"config profile to use (see zerops_profiles)"`
 }
 
 // RegisterDeploy registers the zerops_deploy tool on the server.
@@ -26,13 +27,17 @@ func RegisterDeploy(srv *mcp.Server, exec executor.Executor) {
 
 Uses the zerops.yml in the working directory.
 Requires separate zcli authentication.
+Build and deploy output is streamed as log messages (logger "zcli") and,
+when the request has a progress token, as progress notifications.
 
 Parameters:
 - workingDir: Directory with zerops.yml (optional)
 - serviceId: Target service ID (optional, reads zerops.yml)
+- profile: Config profile to use (optional, see zerops_profiles)
 
 Returns deployment process info.`,
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input DeployInput) (*mcp.CallToolResult, any, error) {
+		ctx = executor.WithProfile(ctx, input.Profile)
 		args := []string{"push"}
 		if input.ServiceID != "" {
 			args = append(args, "--serviceId", input.ServiceID)
@@ -41,7 +46,7 @@ Returns deployment process info.`,
 			args = append(args, "--workingDir", input.WorkingDir)
 		}
 
-		result, err := exec.RunZcli(ctx, args...)
+		result, err := exec.RunZcli(withOutputNotifications(ctx, req, "zcli"), args...)
 		if err != nil {
 			return zcliErrorResult(err)
 		}
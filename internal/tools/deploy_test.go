@@ -1,8 +1,12 @@
 package tools_test
 
 import (
+	"context"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/zeropsio/zaia-mcp/internal/executor"
 	"github.com/zeropsio/zaia-mcp/internal/tools"
 )
@@ -32,3 +36,73 @@ func TestDeploy_WithServiceID(t *testing.T) {
 	assertContains(t, mock.Calls[0].Args, "--serviceId")
 	assertContains(t, mock.Calls[0].Args, "--workingDir")
 }
+
+func TestDeploy_StreamsOutputAsNotifications(t *testing.T) {
+	mock := executor.NewMockExecutor().WithZcliResponse("push", &executor.Result{
+		Stdout: []byte(`{"type":"sync","status":"ok","data":{"deployed":true}}`),
+		Stderr: []byte("Uploading application files\nBuilding application\n"),
+	})
+	srv := testServer(t, tools.RegisterDeploy, mock)
+
+	var mu sync.Mutex
+	var logs, progress []string
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0.0.1"}, &mcp.ClientOptions{
+		LoggingMessageHandler: func(_ context.Context, req *mcp.LoggingMessageRequest) {
+			mu.Lock()
+			defer mu.Unlock()
+			line, _ := req.Params.Data.(string)
+			logs = append(logs, req.Params.Logger+": "+line)
+		},
+		ProgressNotificationHandler: func(_ context.Context, req *mcp.ProgressNotificationClientRequest) {
+			mu.Lock()
+			defer mu.Unlock()
+			progress = append(progress, req.Params.Message)
+		},
+	})
+	ctx := t.Context()
+	t1, t2 := mcp.NewInMemoryTransports()
+	if _, err := srv.Connect(ctx, t1, nil); err != nil {
+		t.Fatal(err)
+	}
+	session, err := client.Connect(ctx, t2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+	if err := session.SetLoggingLevel(ctx, &mcp.SetLoggingLevelParams{Level: "info"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// SetProgressToken needs an existing Meta map.
+	params := &mcp.CallToolParams{Name: "zerops_deploy", Arguments: map[string]any{}, Meta: mcp.Meta{}}
+	params.SetProgressToken("deploy-1")
+	result, err := session.CallTool(ctx, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", getTextContent(t, result))
+	}
+
+	// Notifications are handled asynchronously on the client.
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mu.Lock()
+		n := min(len(logs), len(progress))
+		mu.Unlock()
+		if n >= 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	wantLogs := []string{"zcli: Uploading application files", "zcli: Building application"}
+	if len(logs) != len(wantLogs) || logs[0] != wantLogs[0] || logs[1] != wantLogs[1] {
+		t.Errorf("logs: got %q, want %q (result envelope must not be forwarded)", logs, wantLogs)
+	}
+	if len(progress) != 2 || progress[1] != "Building application" {
+		t.Errorf("progress: got %q", progress)
+	}
+}
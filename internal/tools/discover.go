@@ -11,12 +11,42 @@ import (
 type DiscoverInput struct {
 	Service     string `json:"service,omitempty"`
 	IncludeEnvs bool   `json:"includeEnvs,omitempty"`
+	Fresh       bool   `json:"fresh,omitempty"`
+	Profile     string `json:"profile,omitempty" jsonschema:"config profile to use (see zerops_profiles)"`
 }
 
+// DiscoverOutput is the structured output of zerops_discover.
+type DiscoverOutput struct {
+	Project  ProjectInfo   `json:"project"`
+	Services []ServiceInfo `json:"services,omitempty"`
+	partial
+}
+
+// ProjectInfo describes the current project.
+type ProjectInfo struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+// ServiceInfo describes one service of the project.
+type ServiceInfo struct {
+	ServiceID    string            `json:"serviceId"`
+	Hostname     string            `json:"hostname"`
+	Type         string            `json:"type"`
+	Status       string            `json:"status"`
+	Mode         string            `json:"mode,omitempty" jsonschema:"HA or NON_HA"`
+	SubdomainURL string            `json:"subdomainUrl,omitempty"`
+	Envs         map[string]string `json:"envs,omitempty" jsonschema:"env vars, with includeEnvs=true"`
+}
+
+var discoverOutput = newToolOutput[DiscoverOutput]()
+
 // RegisterDiscover registers the zerops_discover tool on the server.
 func RegisterDiscover(srv *mcp.Server, exec executor.Executor) {
 	mcp.AddTool(srv, &mcp.Tool{
-		Name: "zerops_discover",
+		Name:         "zerops_discover",
+		OutputSchema: discoverOutput.schema,
 		Annotations: &mcp.ToolAnnotations{
 			Title:          "Discover Services",
 			ReadOnlyHint:   true,
@@ -26,11 +56,17 @@ func RegisterDiscover(srv *mcp.Server, exec executor.Executor) {
 
 Call this first to get service hostnames for other tools.
 
+Parameters:
+- profile: Config profile to use (optional, see zerops_profiles)
+
 Returns:
 - project: Current project info (id, name, status)
 - services: List with hostname, type, status
-- Optional: env vars per service`,
+- Optional: env vars per service
+
+Results are cached briefly and refreshed after mutations; set fresh=true to bypass the cache.`,
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input DiscoverInput) (*mcp.CallToolResult, any, error) {
+		ctx = executor.WithProfile(ctx, input.Profile)
 		args := []string{"discover"}
 		if input.Service != "" {
 			args = append(args, "--service", input.Service)
@@ -39,12 +75,12 @@ Returns:
 			args = append(args, "--include-envs")
 		}
 
-		result, err := exec.RunZaia(ctx, args...)
+		result, err := exec.RunZaia(freshContext(ctx, input.Fresh), args...)
 		if err != nil {
 			return cliErrorResult(err)
 		}
 		mcpResult, _ := ResultFromCLI(result)
-		return mcpResult, nil, nil
+		return mcpResult, discoverOutput.structured(mcpResult, syncData(result)), nil
 	})
 }
 
@@ -3,6 +3,8 @@ package tools_test
 import (
 	"testing"
 
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
 	"github.com/zeropsio/zaia-mcp/internal/executor"
 	"github.com/zeropsio/zaia-mcp/internal/tools"
 )
@@ -59,3 +61,25 @@ func TestDiscover_Error(t *testing.T) {
 		t.Error("expected error result")
 	}
 }
+
+func TestDiscover_FreshBypassesCache(t *testing.T) {
+	mock := executor.NewMockExecutor().
+		WithDefault(executor.SyncResult(`{"services":[]}`))
+	cached := executor.NewCachingExecutor(mock, nil)
+	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.0.1"}, nil)
+	tools.RegisterDiscover(srv, cached)
+
+	callTool(t, srv, "zerops_discover", nil)
+	result := callTool(t, srv, "zerops_discover", nil)
+	if len(mock.Calls) != 1 {
+		t.Fatalf("got %d calls, want 1", len(mock.Calls))
+	}
+	if result.Meta["cached"] != true {
+		t.Errorf("cached result meta: got %v", result.Meta)
+	}
+
+	callTool(t, srv, "zerops_discover", map[string]interface{}{"fresh": true})
+	if len(mock.Calls) != 2 {
+		t.Errorf("got %d calls, want 2 with fresh=true", len(mock.Calls))
+	}
+}
@@ -0,0 +1,48 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/zeropsio/zaia-mcp/internal/doctor"
+	"github.com/zeropsio/zaia-mcp/internal/executor"
+)
+
+// DoctorInput is the input schema for zerops_doctor.
+type DoctorInput struct {
+	Profile string `json:"profile,omitempty" jsonschema:"config profile to use (see zerops_profiles)"`
+}
+
+// RegisterDoctor registers the zerops_doctor tool on the server.
+func RegisterDoctor(srv *mcp.Server, checker *doctor.Checker) {
+	mcp.AddTool(srv, &mcp.Tool{
+		Name: "zerops_doctor",
+		Annotations: &mcp.ToolAnnotations{
+			Title:          "Diagnose Setup",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+		},
+		Description: `Diagnose the zaia/zcli setup of this MCP server.
+
+Use when tools fail with "CLI execution failed", "not found" or auth errors.
+
+Parameters:
+- profile: Config profile to use (optional, see zerops_profiles)
+
+Returns:
+- ok: true when both binaries are found, recent enough and authenticated
+- path: PATH used for CLI subprocesses (resolved from login shell)
+- binaries: location, version and auth status of zaia and zcli
+- remediation: concrete steps to fix detected problems`,
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input DoctorInput) (*mcp.CallToolResult, any, error) {
+		report := checker.Check(executor.WithProfile(ctx, input.Profile))
+		b, err := json.Marshal(report)
+		if err != nil {
+			return errorResult("encoding doctor report: " + err.Error()), nil, nil
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: string(b)}},
+		}, nil, nil
+	})
+}
@@ -2,6 +2,7 @@ package tools
 
 import (
 	"context"
+	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/zeropsio/zaia-mcp/internal/executor"
@@ -9,16 +10,40 @@ import (
 
 // EnvInput is the input schema for zerops_env.
 type EnvInput struct {
-	Action          string   `json:"action"`
-	ServiceHostname string   `json:"serviceHostname,omitempty"`
-	Project         bool     `json:"project,omitempty"`
-	Variables       []string `json:"variables,omitempty"`
+	Action            string   `json:"action"`
+	ServiceHostname   string   `json:"serviceHostname,omitempty"`
+	Project           bool     `json:"project,omitempty"`
+	Variables         []string `json:"variables,omitempty"`
+	WaitForCompletion bool     `json:"waitForCompletion,omitempty" jsonschema:"poll the returned processes until they end and return their final states"`
+	TimeoutSeconds    int      `json:"timeoutSeconds,omitempty" jsonschema:"max wait with waitForCompletion in seconds (default 300, max 1800)"`
+	Confirm           bool     `json:"confirm,omitempty" jsonschema:"delete only: must be true when the client cannot ask the user to confirm"`
+	Profile           string   `json:"profile,omitempty" jsonschema:"config profile to use (see zerops_profiles)"`
 }
 
+// EnvOutput is the structured output of zerops_env: the env vars for
+// action=get, the started (or with waitForCompletion, final) processes
+// for set and delete.
+type EnvOutput struct {
+	ServiceHostname string          `json:"serviceHostname,omitempty" jsonschema:"empty for project env vars"`
+	Envs            []EnvVar        `json:"envs,omitempty"`
+	Processes       []ProcessOutput `json:"processes,omitempty" jsonschema:"set and delete"`
+	TimedOut        bool            `json:"timedOut,omitempty" jsonschema:"waitForCompletion ended before all processes did"`
+	partial
+}
+
+// EnvVar is one environment variable.
+type EnvVar struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+var envOutput = newToolOutput[EnvOutput]()
+
 // RegisterEnv registers the zerops_env tool on the server.
 func RegisterEnv(srv *mcp.Server, exec executor.Executor) {
 	mcp.AddTool(srv, &mcp.Tool{
-		Name: "zerops_env",
+		Name:         "zerops_env",
+		OutputSchema: envOutput.schema,
 		Annotations: &mcp.ToolAnnotations{
 			Title:           "Manage Env Vars",
 			DestructiveHint: boolPtr(false),
@@ -28,15 +53,22 @@ func RegisterEnv(srv *mcp.Server, exec executor.Executor) {
 Actions:
 - get: Read env vars (sync response)
 - set: Set env vars (async - returns process ID)
-- delete: Delete env var (async - returns process ID)
+- delete: Delete env var (async - returns process ID; requires confirm=true,
+  clients with elicitation support ask the user to confirm instead)
 
 Scope: Provide serviceHostname for service env, or project=true for project env.
 
+Parameters:
+- profile: Config profile to use (optional, see zerops_profiles)
+
 Set format: ["KEY=value", "ANOTHER=value2"]
 Delete format: ["KEY"]
 
-Note: Use ${service_hostname} for cross-service references (underscore, not dash).`,
+Note: Use ${service_hostname} for cross-service references (underscore, not dash).
+
+set/delete with waitForCompletion=true wait for the process and return its final state.`,
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input EnvInput) (*mcp.CallToolResult, any, error) {
+		ctx = executor.WithProfile(ctx, input.Profile)
 		if input.Action == "" {
 			return errorResult("action is required (get, set, delete)"), nil, nil
 		}
@@ -44,6 +76,21 @@ Note: Use ${service_hostname} for cross-service references (underscore, not dash
 			return errorResult("serviceHostname or project=true is required"), nil, nil
 		}
 
+		if input.Action == "delete" && len(input.Variables) > 0 {
+			if elicitationSupported(req) {
+				op := destructiveOp{
+					verb:     "Delete env vars " + strings.Join(input.Variables, ", ") + " of",
+					hostname: input.ServiceHostname,
+					loss:     "Their current values are removed permanently. Services pick up the change on their next restart or deploy.",
+				}
+				if res := confirmDestructive(ctx, req, exec, op); res != nil {
+					return res, nil, nil
+				}
+			} else if !input.Confirm {
+				return errorResult("confirm must be true to delete env vars"), nil, nil
+			}
+		}
+
 		args := []string{"env", input.Action}
 		if input.ServiceHostname != "" {
 			args = append(args, "--service", input.ServiceHostname)
@@ -57,7 +104,11 @@ Note: Use ${service_hostname} for cross-service references (underscore, not dash
 		if err != nil {
 			return cliErrorResult(err)
 		}
-		mcpResult, _ := ResultFromCLI(result)
-		return mcpResult, nil, nil
+		if input.Action == "get" {
+			mcpResult, _ := ResultFromCLI(result)
+			return mcpResult, envOutput.structured(mcpResult, syncData(result)), nil
+		}
+		mcpResult, processes := awaitAsync(ctx, req, exec, result, newWaitOptions(input.WaitForCompletion, input.TimeoutSeconds))
+		return mcpResult, envOutput.structured(mcpResult, processes), nil
 	})
 }
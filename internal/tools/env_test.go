@@ -1,6 +1,7 @@
 package tools_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/zeropsio/zaia-mcp/internal/executor"
@@ -77,8 +78,25 @@ func TestEnv_Delete(t *testing.T) {
 		"action":          "delete",
 		"serviceHostname": "api",
 		"variables":       []interface{}{"OLD_KEY"},
+		"confirm":         true,
 	})
 	args := mock.Calls[0].Args
 	assertArgs(t, args, "env", "delete", "--service", "api")
 	assertContains(t, args, "OLD_KEY")
 }
+
+func TestEnv_DeleteNotConfirmed(t *testing.T) {
+	mock := executor.NewMockExecutor()
+	srv := testServer(t, tools.RegisterEnv, mock)
+	result := callTool(t, srv, "zerops_env", map[string]interface{}{
+		"action":          "delete",
+		"serviceHostname": "api",
+		"variables":       []interface{}{"OLD_KEY"},
+	})
+	if !result.IsError || !strings.Contains(getTextContent(t, result), "confirm must be true") {
+		t.Errorf("got %s, want a confirm error", getTextContent(t, result))
+	}
+	if len(mock.Calls) != 0 {
+		t.Errorf("delete ran without confirm: %+v", mock.Calls)
+	}
+}
@@ -12,12 +12,35 @@ import (
 type EventsInput struct {
 	ServiceHostname string `json:"serviceHostname,omitempty"`
 	Limit           int    `json:"limit,omitempty"`
+	Profile         string `json:"profile,omitempty" jsonschema:"config profile to use (see zerops_profiles)"`
 }
 
+// EventsOutput is the structured output of zerops_events.
+type EventsOutput struct {
+	Events  []Event        `json:"events,omitempty" jsonschema:"newest first"`
+	Summary map[string]int `json:"summary,omitempty" jsonschema:"event counts"`
+	partial
+}
+
+// Event is one entry of the project timeline.
+type Event struct {
+	Type            string `json:"type" jsonschema:"process or build"`
+	Action          string `json:"action"`
+	Status          string `json:"status"`
+	ServiceHostname string `json:"serviceHostname,omitempty"`
+	ProcessID       string `json:"processId,omitempty"`
+	BuildID         string `json:"buildId,omitempty"`
+	Timestamp       string `json:"timestamp"`
+	Duration        string `json:"duration,omitempty"`
+}
+
+var eventsOutput = newToolOutput[EventsOutput]()
+
 // RegisterEvents registers the zerops_events tool on the server.
 func RegisterEvents(srv *mcp.Server, exec executor.Executor) {
 	mcp.AddTool(srv, &mcp.Tool{
-		Name: "zerops_events",
+		Name:         "zerops_events",
+		OutputSchema: eventsOutput.schema,
 		Annotations: &mcp.ToolAnnotations{
 			Title:          "Project Activity Log",
 			ReadOnlyHint:   true,
@@ -30,11 +53,13 @@ Aggregates processes (start/stop/restart/scale/import/delete/env) and build/depl
 Parameters:
 - serviceHostname: Filter by service (optional)
 - limit: Max events (default 50)
+- profile: Config profile to use (optional, see zerops_profiles)
 
 Returns:
 - events: Unified timeline with timestamp, action, status, service, duration
 - summary: Event counts`,
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input EventsInput) (*mcp.CallToolResult, any, error) {
+		ctx = executor.WithProfile(ctx, input.Profile)
 		args := []string{"events"}
 		if input.ServiceHostname != "" {
 			args = append(args, "--service", input.ServiceHostname)
@@ -48,6 +73,6 @@ Returns:
 			return cliErrorResult(err)
 		}
 		mcpResult, _ := ResultFromCLI(result)
-		return mcpResult, nil, nil
+		return mcpResult, eventsOutput.structured(mcpResult, syncData(result)), nil
 	})
 }
@@ -1,6 +1,7 @@
 package tools_test
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
 
@@ -45,6 +46,33 @@ func callTool(t *testing.T, srv *mcp.Server, name string, args map[string]interf
 	return result
 }
 
+// callToolElicit calls the named tool from a client that supports
+// elicitation; elicit answers the server's elicitation requests.
+func callToolElicit(t *testing.T, srv *mcp.Server, name string, args map[string]interface{}, elicit func(*mcp.ElicitParams) *mcp.ElicitResult) *mcp.CallToolResult {
+	t.Helper()
+	ctx := t.Context()
+	t1, t2 := mcp.NewInMemoryTransports()
+	if _, err := srv.Connect(ctx, t1, nil); err != nil {
+		t.Fatalf("server connect: %v", err)
+	}
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0.0.1"}, &mcp.ClientOptions{
+		ElicitationHandler: func(_ context.Context, req *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+			return elicit(req.Params), nil
+		},
+	})
+	session, err := client.Connect(ctx, t2, nil)
+	if err != nil {
+		t.Fatalf("client connect: %v", err)
+	}
+	defer session.Close()
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: name, Arguments: args})
+	if err != nil {
+		t.Fatalf("CallTool(%q): %v", name, err)
+	}
+	return result
+}
+
 // callToolExpectError calls a tool and expects a protocol-level error (e.g. missing required params).
 func callToolExpectError(t *testing.T, srv *mcp.Server, name string, args map[string]interface{}) {
 	t.Helper()
@@ -9,9 +9,12 @@ import (
 
 // ImportInput is the input schema for zerops_import.
 type ImportInput struct {
-	Content  string `json:"content,omitempty"`
-	FilePath string `json:"filePath,omitempty"`
-	DryRun   bool   `json:"dryRun,omitempty"`
+	Content           string `json:"content,omitempty"`
+	FilePath          string `json:"filePath,omitempty"`
+	DryRun            bool   `json:"dryRun,omitempty"`
+	WaitForCompletion bool   `json:"waitForCompletion,omitempty" jsonschema:"poll the returned processes until they end and return their final states"`
+	TimeoutSeconds    int    `json:"timeoutSeconds,omitempty" jsonschema:"max wait with waitForCompletion in seconds (default 300, max 1800)"`
+	Profile           string `json:"profile,omitempty" jsonschema:"config profile to use (see zerops_profiles)"`
 }
 
 // RegisterImport registers the zerops_import tool on the server.
@@ -36,8 +39,13 @@ Example YAML:
     - hostname: db
       type: postgresql@16
 
-Returns process ID for tracking via zerops_process.`,
+Parameters:
+- profile: Config profile to use (optional, see zerops_profiles)
+
+Returns process IDs for tracking via zerops_process, or with
+waitForCompletion=true waits for all processes and returns their final states.`,
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input ImportInput) (*mcp.CallToolResult, any, error) {
+		ctx = executor.WithProfile(ctx, input.Profile)
 		if input.Content == "" && input.FilePath == "" {
 			return errorResult("content or filePath is required"), nil, nil
 		}
@@ -59,7 +67,6 @@ Returns process ID for tracking via zerops_process.`,
 		if err != nil {
 			return cliErrorResult(err)
 		}
-		mcpResult, _ := ResultFromCLI(result)
-		return mcpResult, nil, nil
+		return asyncResult(ctx, req, exec, result, newWaitOptions(input.WaitForCompletion, input.TimeoutSeconds))
 	})
 }
@@ -10,14 +10,36 @@ import (
 
 // KnowledgeInput is the input schema for zerops_knowledge.
 type KnowledgeInput struct {
-	Query string `json:"query"`
-	Limit int    `json:"limit,omitempty"`
+	Query   string `json:"query"`
+	Limit   int    `json:"limit,omitempty"`
+	Fresh   bool   `json:"fresh,omitempty"`
+	Profile string `json:"profile,omitempty" jsonschema:"config profile to use (see zerops_profiles)"`
 }
 
+// KnowledgeOutput is the structured output of zerops_knowledge.
+type KnowledgeOutput struct {
+	Query     string         `json:"query"`
+	Results   []SearchResult `json:"results,omitempty" jsonschema:"best match first"`
+	TopResult *SearchResult  `json:"topResult,omitempty"`
+	partial
+}
+
+// SearchResult is one knowledge base match.
+type SearchResult struct {
+	URI     string  `json:"uri" jsonschema:"read the full document as a resource"`
+	Title   string  `json:"title"`
+	Score   float64 `json:"score"`
+	Snippet string  `json:"snippet,omitempty"`
+	Content string  `json:"content,omitempty"`
+}
+
+var knowledgeOutput = newToolOutput[KnowledgeOutput]()
+
 // RegisterKnowledge registers the zerops_knowledge tool on the server.
 func RegisterKnowledge(srv *mcp.Server, exec executor.Executor) {
 	mcp.AddTool(srv, &mcp.Tool{
-		Name: "zerops_knowledge",
+		Name:         "zerops_knowledge",
+		OutputSchema: knowledgeOutput.schema,
 		Annotations: &mcp.ToolAnnotations{
 			Title:          "Search Knowledge",
 			ReadOnlyHint:   true,
@@ -31,8 +53,13 @@ This is KEYWORD search (BM25). Use specific terms:
 - Config: zerops.yml, import.yml, build
 - Errors: redirect loop, connection refused
 
-Returns topResult with full document content of best match.`,
+Parameters:
+- profile: Config profile to use (optional, see zerops_profiles)
+
+Returns topResult with full document content of best match.
+Results are cached; set fresh=true to bypass the cache.`,
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input KnowledgeInput) (*mcp.CallToolResult, any, error) {
+		ctx = executor.WithProfile(ctx, input.Profile)
 		if input.Query == "" {
 			return errorResult("query is required"), nil, nil
 		}
@@ -42,11 +69,11 @@ Returns topResult with full document content of best match.`,
 			args = append(args, "--limit", fmt.Sprintf("%d", input.Limit))
 		}
 
-		result, err := exec.RunZaia(ctx, args...)
+		result, err := exec.RunZaia(freshContext(ctx, input.Fresh), args...)
 		if err != nil {
 			return cliErrorResult(err)
 		}
 		mcpResult, _ := ResultFromCLI(result)
-		return mcpResult, nil, nil
+		return mcpResult, knowledgeOutput.structured(mcpResult, syncData(result)), nil
 	})
 }
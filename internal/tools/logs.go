@@ -16,12 +16,30 @@ type LogsInput struct {
 	Limit           int    `json:"limit,omitempty"`
 	Search          string `json:"search,omitempty"`
 	BuildID         string `json:"buildId,omitempty"`
+	Profile         string `json:"profile,omitempty" jsonschema:"config profile to use (see zerops_profiles)"`
 }
 
+// LogsOutput is the structured output of zerops_logs.
+type LogsOutput struct {
+	Entries []LogEntry `json:"entries,omitempty"`
+	HasMore bool       `json:"hasMore" jsonschema:"more entries match than limit; narrow since/severity/search"`
+	partial
+}
+
+// LogEntry is one log line.
+type LogEntry struct {
+	Timestamp string `json:"timestamp"`
+	Severity  string `json:"severity"`
+	Message   string `json:"message"`
+}
+
+var logsOutput = newToolOutput[LogsOutput]()
+
 // RegisterLogs registers the zerops_logs tool on the server.
 func RegisterLogs(srv *mcp.Server, exec executor.Executor) {
 	mcp.AddTool(srv, &mcp.Tool{
-		Name: "zerops_logs",
+		Name:         "zerops_logs",
+		OutputSchema: logsOutput.schema,
 		Annotations: &mcp.ToolAnnotations{
 			Title:          "Fetch Logs",
 			ReadOnlyHint:   true,
@@ -35,8 +53,10 @@ Parameters:
 - since: Time range (30m, 1h, 24h, 7d, or ISO 8601)
 - limit: Max entries (default 100)
 - search: Text search
-- buildId: Get build logs`,
+- buildId: Get build logs
+- profile: Config profile to use (optional, see zerops_profiles)`,
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input LogsInput) (*mcp.CallToolResult, any, error) {
+		ctx = executor.WithProfile(ctx, input.Profile)
 		if input.ServiceHostname == "" {
 			return errorResult("serviceHostname is required"), nil, nil
 		}
@@ -63,6 +83,6 @@ Parameters:
 			return cliErrorResult(err)
 		}
 		mcpResult, _ := ResultFromCLI(result)
-		return mcpResult, nil, nil
+		return mcpResult, logsOutput.structured(mcpResult, syncData(result)), nil
 	})
 }
@@ -10,18 +10,22 @@ import (
 
 // ManageInput is the input schema for zerops_manage.
 type ManageInput struct {
-	Action          string  `json:"action"`
-	ServiceHostname string  `json:"serviceHostname"`
-	CPUMode         string  `json:"cpuMode,omitempty"`
-	MinCPU          int     `json:"minCpu,omitempty"`
-	MaxCPU          int     `json:"maxCpu,omitempty"`
-	MinRAM          float64 `json:"minRam,omitempty"`
-	MaxRAM          float64 `json:"maxRam,omitempty"`
-	MinDisk         float64 `json:"minDisk,omitempty"`
-	MaxDisk         float64 `json:"maxDisk,omitempty"`
-	StartContainers int     `json:"startContainers,omitempty"`
-	MinContainers   int     `json:"minContainers,omitempty"`
-	MaxContainers   int     `json:"maxContainers,omitempty"`
+	Action            string  `json:"action"`
+	ServiceHostname   string  `json:"serviceHostname"`
+	CPUMode           string  `json:"cpuMode,omitempty"`
+	MinCPU            int     `json:"minCpu,omitempty"`
+	MaxCPU            int     `json:"maxCpu,omitempty"`
+	MinRAM            float64 `json:"minRam,omitempty"`
+	MaxRAM            float64 `json:"maxRam,omitempty"`
+	MinDisk           float64 `json:"minDisk,omitempty"`
+	MaxDisk           float64 `json:"maxDisk,omitempty"`
+	StartContainers   int     `json:"startContainers,omitempty"`
+	MinContainers     int     `json:"minContainers,omitempty"`
+	MaxContainers     int     `json:"maxContainers,omitempty"`
+	WaitForCompletion bool    `json:"waitForCompletion,omitempty" jsonschema:"poll the returned processes until they end and return their final states"`
+	TimeoutSeconds    int     `json:"timeoutSeconds,omitempty" jsonschema:"max wait with waitForCompletion in seconds (default 300, max 1800)"`
+	Confirm           bool    `json:"confirm,omitempty" jsonschema:"stop only: must be true when the client cannot ask the user to confirm"`
+	Profile           string  `json:"profile,omitempty" jsonschema:"config profile to use (see zerops_profiles)"`
 }
 
 // RegisterManage registers the zerops_manage tool on the server.
@@ -36,7 +40,8 @@ func RegisterManage(srv *mcp.Server, exec executor.Executor) {
 
 Actions:
 - start: Start a stopped service
-- stop: Stop a running service
+- stop: Stop a running service (requires confirm=true; clients with elicitation
+  support ask the user to confirm instead)
 - restart: Restart a service
 - scale: Change CPU/RAM/disk/container scaling
 
@@ -45,8 +50,13 @@ Scale parameters (only for action=scale):
 - minCpu/maxCpu, minRam/maxRam, minDisk/maxDisk
 - startContainers, minContainers, maxContainers
 
-Returns process ID for status tracking via zerops_process.`,
+Parameters:
+- profile: Config profile to use (optional, see zerops_profiles)
+
+Returns process ID for status tracking via zerops_process, or with
+waitForCompletion=true waits for the process and returns its final state.`,
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input ManageInput) (*mcp.CallToolResult, any, error) {
+		ctx = executor.WithProfile(ctx, input.Profile)
 		if input.Action == "" {
 			return errorResult("action is required (start, stop, restart, scale)"), nil, nil
 		}
@@ -54,6 +64,21 @@ Returns process ID for status tracking via zerops_process.`,
 			return errorResult("serviceHostname is required"), nil, nil
 		}
 
+		if input.Action == "stop" {
+			if elicitationSupported(req) {
+				op := destructiveOp{
+					verb:     "Stop",
+					hostname: input.ServiceHostname,
+					loss:     "The service stops serving traffic until it is started again. Its containers are shut down and in-memory state (sessions, caches, anything not written to disk) is lost.",
+				}
+				if res := confirmDestructive(ctx, req, exec, op); res != nil {
+					return res, nil, nil
+				}
+			} else if !input.Confirm {
+				return errorResult("confirm must be true to stop a service"), nil, nil
+			}
+		}
+
 		args := []string{input.Action, "--service", input.ServiceHostname}
 
 		if input.Action == "scale" {
@@ -93,7 +118,6 @@ Returns process ID for status tracking via zerops_process.`,
 		if err != nil {
 			return cliErrorResult(err)
 		}
-		mcpResult, _ := ResultFromCLI(result)
-		return mcpResult, nil, nil
+		return asyncResult(ctx, req, exec, result, newWaitOptions(input.WaitForCompletion, input.TimeoutSeconds))
 	})
 }
@@ -1,8 +1,14 @@
 package tools_test
 
 import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/zeropsio/zaia-mcp/internal/executor"
 	"github.com/zeropsio/zaia-mcp/internal/tools"
 )
@@ -94,3 +100,127 @@ func TestManage_ScaleWithDisk(t *testing.T) {
 	assertContains(t, args, "--min-disk")
 	assertContains(t, args, "--max-disk")
 }
+
+func TestManage_WaitForCompletion(t *testing.T) {
+	mock := executor.NewMockExecutor()
+	mock.ExpectZaia("start", "--service", "api").Return(executor.AsyncResult(`[{"processId":"p1","status":"PENDING"}]`))
+	mock.ExpectZaia("process", "p1").Return(
+		executor.SyncResult(`{"processId":"p1","actionName":"start","status":"RUNNING"}`),
+		executor.SyncResult(`{"processId":"p1","actionName":"start","status":"FINISHED"}`),
+	)
+	srv := testServer(t, tools.RegisterManage, mock)
+	result := callTool(t, srv, "zerops_manage", map[string]interface{}{
+		"action":            "start",
+		"serviceHostname":   "api",
+		"waitForCompletion": true,
+	})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", getTextContent(t, result))
+	}
+	text := getTextContent(t, result)
+	if !strings.Contains(text, `"processId":"p1"`) || !strings.Contains(text, `"status":"FINISHED"`) {
+		t.Errorf("result = %s, want the final process state", text)
+	}
+}
+
+func TestManage_WaitForCompletion_Failed(t *testing.T) {
+	mock := executor.NewMockExecutor()
+	mock.ExpectZaia("stop", "--service", "api").Return(executor.AsyncResult(`[{"processId":"p1"}]`))
+	mock.ExpectZaia("process", "p1").Return(
+		executor.SyncResult(`{"processId":"p1","status":"FAILED","failReason":"service api no longer exists"}`))
+	srv := testServer(t, tools.RegisterManage, mock)
+	result := callTool(t, srv, "zerops_manage", map[string]interface{}{
+		"action":            "stop",
+		"serviceHostname":   "api",
+		"waitForCompletion": true,
+		"confirm":           true,
+	})
+	if !result.IsError {
+		t.Error("expected an error result for a failed process")
+	}
+	if text := getTextContent(t, result); !strings.Contains(text, "no longer exists") {
+		t.Errorf("result = %s, want the fail reason", text)
+	}
+}
+
+func TestManage_WaitForCompletion_Progress(t *testing.T) {
+	mock := executor.NewMockExecutor()
+	mock.ExpectZaia("restart", "--service", "api").Return(executor.AsyncResult(`[{"processId":"p1"}]`))
+	mock.ExpectZaia("process", "p1").Return(
+		executor.SyncResult(`{"processId":"p1","status":"RUNNING"}`),
+		executor.SyncResult(`{"processId":"p1","status":"FINISHED"}`),
+	)
+	srv := testServer(t, tools.RegisterManage, mock)
+
+	var mu sync.Mutex
+	var progress []string
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0.0.1"}, &mcp.ClientOptions{
+		ProgressNotificationHandler: func(_ context.Context, req *mcp.ProgressNotificationClientRequest) {
+			mu.Lock()
+			defer mu.Unlock()
+			progress = append(progress, req.Params.Message)
+		},
+	})
+	ctx := t.Context()
+	t1, t2 := mcp.NewInMemoryTransports()
+	if _, err := srv.Connect(ctx, t1, nil); err != nil {
+		t.Fatal(err)
+	}
+	session, err := client.Connect(ctx, t2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	params := &mcp.CallToolParams{
+		Name:      "zerops_manage",
+		Arguments: map[string]any{"action": "restart", "serviceHostname": "api", "waitForCompletion": true},
+		Meta:      mcp.Meta{},
+	}
+	params.SetProgressToken("restart-1")
+	if _, err := session.CallTool(ctx, params); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"0/1 processes done: p1 RUNNING", "1/1 processes done: p1 FINISHED"}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mu.Lock()
+		n := len(progress)
+		mu.Unlock()
+		if n >= len(want) || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !slices.Equal(progress, want) {
+		t.Errorf("progress = %q, want %q", progress, want)
+	}
+}
+
+func TestManage_StopNotConfirmed(t *testing.T) {
+	mock := executor.NewMockExecutor().
+		WithDefault(executor.AsyncResult(`[{"processId":"p1"}]`))
+	srv := testServer(t, tools.RegisterManage, mock)
+	result := callTool(t, srv, "zerops_manage", map[string]interface{}{
+		"action":          "stop",
+		"serviceHostname": "api",
+	})
+	if !result.IsError || !strings.Contains(getTextContent(t, result), "confirm must be true") {
+		t.Errorf("got %s, want a confirm error", getTextContent(t, result))
+	}
+	if len(mock.Calls) != 0 {
+		t.Errorf("stop ran without confirm: %+v", mock.Calls)
+	}
+
+	result = callTool(t, srv, "zerops_manage", map[string]interface{}{
+		"action":          "stop",
+		"serviceHostname": "api",
+		"confirm":         true,
+	})
+	if result.IsError {
+		t.Errorf("unexpected error: %s", getTextContent(t, result))
+	}
+}
@@ -0,0 +1,98 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/zeropsio/zaia-mcp/internal/executor"
+)
+
+// partial is embedded in every output type. It marks structured content
+// that does not carry the CLI result, which the text content then holds.
+type partial struct {
+	Partial bool `json:"partial,omitempty" jsonschema:"the output was truncated or not in the expected shape: fields may be missing or empty; read the text content instead"`
+}
+
+func (p *partial) markPartial() { p.Partial = true }
+
+// partialMarker is implemented by output types embedding partial.
+type partialMarker interface{ markPartial() }
+
+// toolOutput is the structured output of a tool with output type T. Every
+// non-error result of such a tool carries structured content that conforms
+// to the schema, as the MCP spec requires once a tool declares one.
+type toolOutput[T any] struct {
+	schema   *jsonschema.Schema
+	resolved *jsonschema.Resolved
+}
+
+// newToolOutput derives the output schema from T. Objects in the schema
+// accept properties T does not declare, so data from a newer CLI still
+// conforms. Slices and maps in output types are omitempty: the schema
+// does not accept null for them. T must embed partial.
+func newToolOutput[T any]() *toolOutput[T] {
+	if _, ok := any(new(T)).(partialMarker); !ok {
+		panic(fmt.Sprintf("output type %T does not embed partial", *new(T)))
+	}
+	s, err := jsonschema.For[T](nil)
+	if err != nil {
+		panic(err) // output types are static; a test registers every tool
+	}
+	allowAdditionalProperties(s)
+	resolved, err := s.Resolve(nil)
+	if err != nil {
+		panic(fmt.Sprintf("output schema of %T: %v", *new(T), err))
+	}
+	return &toolOutput[T]{schema: s, resolved: resolved}
+}
+
+// allowAdditionalProperties drops the additionalProperties=false that
+// jsonschema.For puts on struct objects. Map schemas keep theirs.
+func allowAdditionalProperties(s *jsonschema.Schema) {
+	if s == nil {
+		return
+	}
+	if s.Properties != nil {
+		s.AdditionalProperties = nil
+	}
+	for _, p := range s.Properties {
+		allowAdditionalProperties(p)
+	}
+	allowAdditionalProperties(s.Items)
+	allowAdditionalProperties(s.AdditionalProperties)
+}
+
+// structured returns the structured content of res: nil for error results;
+// otherwise data itself when it is a JSON object conforming to the schema,
+// unknown fields included. Else (no data, such as truncated output or an
+// async envelope, or data of another shape) it is T decoded from whatever
+// of data fits, marked partial: it conforms, but only the text content
+// has the result.
+func (o *toolOutput[T]) structured(res *mcp.CallToolResult, data []byte) any {
+	if res.IsError {
+		return nil
+	}
+	var instance map[string]any
+	if json.Unmarshal(data, &instance) == nil && instance != nil && o.resolved.Validate(instance) == nil {
+		return json.RawMessage(data)
+	}
+	out := new(T)
+	_ = json.Unmarshal(data, out) // fields of the wrong type stay zero
+	any(out).(partialMarker).markPartial()
+	return out
+}
+
+// syncData returns the data of a sync CLI envelope, or nil for other
+// envelopes and truncated output.
+func syncData(result *executor.Result) []byte {
+	if result.StdoutOverflow != nil {
+		return nil
+	}
+	resp, err := ParseCLIResponse(result)
+	if err != nil || resp.Type != "sync" {
+		return nil
+	}
+	return resp.Data
+}
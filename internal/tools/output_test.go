@@ -0,0 +1,259 @@
+package tools_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/zeropsio/zaia-mcp/internal/executor"
+	"github.com/zeropsio/zaia-mcp/internal/fakezerops"
+	"github.com/zeropsio/zaia-mcp/internal/tools"
+)
+
+// structuredTools lists the tools with an output schema.
+var structuredTools = map[string]func(*mcp.Server, executor.Executor){
+	"zerops_discover":  tools.RegisterDiscover,
+	"zerops_logs":      tools.RegisterLogs,
+	"zerops_events":    tools.RegisterEvents,
+	"zerops_process":   tools.RegisterProcess,
+	"zerops_env":       tools.RegisterEnv,
+	"zerops_validate":  tools.RegisterValidate,
+	"zerops_knowledge": tools.RegisterKnowledge,
+}
+
+// fakeCLI runs zaia commands against the fakezerops simulation, which
+// emits the envelopes of the real CLI.
+type fakeCLI struct{ state string }
+
+func (f fakeCLI) RunZaia(_ context.Context, args ...string) (*executor.Result, error) {
+	var stdout, stderr bytes.Buffer
+	code := fakezerops.RunZaia(f.state, args, &stdout, &stderr)
+	return &executor.Result{Stdout: stdout.Bytes(), Stderr: stderr.Bytes(), ExitCode: code}, nil
+}
+
+func (fakeCLI) RunZcli(context.Context, ...string) (*executor.Result, error) {
+	return nil, errors.New("zcli is not simulated")
+}
+
+func connectAll(t *testing.T, exec executor.Executor) *mcp.ClientSession {
+	t.Helper()
+	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "0.0.1"}, nil)
+	for _, register := range structuredTools {
+		register(srv, exec)
+	}
+	tools.RegisterManage(srv, exec)
+	t1, t2 := mcp.NewInMemoryTransports()
+	if _, err := srv.Connect(t.Context(), t1, nil); err != nil {
+		t.Fatal(err)
+	}
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0.0.1"}, nil).Connect(t.Context(), t2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cs.Close() })
+	return cs
+}
+
+func call(t *testing.T, cs *mcp.ClientSession, name string, args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+	result, err := cs.CallTool(t.Context(), &mcp.CallToolParams{Name: name, Arguments: args})
+	if err != nil {
+		t.Fatalf("CallTool(%q): %v", name, err)
+	}
+	return result
+}
+
+// asyncID returns the ID of the single process of an async envelope.
+func asyncID(t *testing.T, result *executor.Result) string {
+	t.Helper()
+	resp, err := tools.ParseCLIResponse(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var processes []struct {
+		ProcessID string `json:"processId"`
+	}
+	if err := json.Unmarshal(resp.Processes, &processes); err != nil || len(processes) != 1 {
+		t.Fatalf("processes %s: %v", resp.Processes, err)
+	}
+	return processes[0].ProcessID
+}
+
+func TestOutputSchemas(t *testing.T) {
+	cs := connectAll(t, executor.NewMockExecutor())
+	list, err := cs.ListTools(t.Context(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tool := range list.Tools {
+		_, structured := structuredTools[tool.Name]
+		schema, _ := tool.OutputSchema.(map[string]any)
+		switch {
+		case structured && (schema == nil || schema["type"] != "object"):
+			t.Errorf("%s: output schema %v, want an object schema", tool.Name, tool.OutputSchema)
+		case !structured && tool.OutputSchema != nil:
+			t.Errorf("%s: unexpected output schema", tool.Name)
+		}
+	}
+}
+
+func TestStructuredOutput_FakeCLI(t *testing.T) {
+	exec := fakeCLI{state: filepath.Join(t.TempDir(), "state.json")}
+	cs := connectAll(t, exec)
+
+	importYAML := "services:\n  - hostname: db\n    type: postgresql@16\n    mode: NON_HA\n"
+	imported, _ := exec.RunZaia(t.Context(), "import", "--content", importYAML)
+	id := asyncID(t, imported)
+	for range 3 { // every CLI call advances the process one step
+		_, _ = exec.RunZaia(t.Context(), "process", id)
+	}
+
+	tests := []struct {
+		tool string
+		args map[string]any
+	}{
+		{"zerops_discover", map[string]any{"includeEnvs": true}},
+		{"zerops_logs", map[string]any{"serviceHostname": "db"}},
+		{"zerops_events", map[string]any{}},
+		{"zerops_process", map[string]any{"processId": id}},
+		{"zerops_env", map[string]any{"action": "get", "serviceHostname": "db"}},
+		{"zerops_validate", map[string]any{"content": importYAML, "type": "import.yml"}},
+		{"zerops_knowledge", map[string]any{"query": "postgresql"}},
+	}
+	for _, tt := range tests {
+		result := call(t, cs, tt.tool, tt.args)
+		if result.IsError {
+			t.Errorf("%s: unexpected error: %s", tt.tool, getTextContent(t, result))
+			continue
+		}
+		if out, _ := result.StructuredContent.(map[string]any); out == nil || out["partial"] != nil {
+			t.Errorf("%s: structured content %v for %s", tt.tool, result.StructuredContent, getTextContent(t, result))
+		}
+	}
+
+	result := call(t, cs, "zerops_discover", map[string]any{})
+	out, _ := result.StructuredContent.(map[string]any)
+	services, _ := out["services"].([]any)
+	if len(services) != 1 || services[0].(map[string]any)["type"] != "postgresql@16" {
+		t.Errorf("discover structured content = %v", out)
+	}
+}
+
+func TestStructuredOutput_KeepsUnknownFields(t *testing.T) {
+	mock := executor.NewMockExecutor().WithZaiaResponse("discover", executor.SyncResult(
+		`{"project":{"id":"p","name":"demo","status":"ACTIVE"},"services":[{"serviceId":"s","hostname":"db","type":"postgresql@16","status":"ACTIVE","containers":3}]}`))
+	cs := connectAll(t, mock)
+
+	result := call(t, cs, "zerops_discover", map[string]any{})
+	out, _ := result.StructuredContent.(map[string]any)
+	services, _ := out["services"].([]any)
+	if result.IsError || len(services) != 1 || services[0].(map[string]any)["containers"] != float64(3) {
+		t.Errorf("got isError=%v structured=%v, want the full CLI data", result.IsError, result.StructuredContent)
+	}
+}
+
+func TestStructuredOutput_NonConformingData(t *testing.T) {
+	mock := executor.NewMockExecutor().WithZaiaResponse("process", executor.SyncResult(`{"processId":"p1","status":3}`))
+	cs := connectAll(t, mock)
+
+	result := call(t, cs, "zerops_process", map[string]any{"processId": "p1"})
+	if out, _ := result.StructuredContent.(map[string]any); result.IsError || out["processId"] != "p1" || out["partial"] != true {
+		t.Errorf("got isError=%v structured=%v, want the fields that fit, marked partial", result.IsError, result.StructuredContent)
+	}
+}
+
+func TestStructuredOutput_NotForErrors(t *testing.T) {
+	mock := executor.NewMockExecutor().
+		WithZaiaResponse("logs", executor.ErrorResult("SERVICE_NOT_FOUND", "Service x not found", "", 1))
+	cs := connectAll(t, mock)
+
+	if result := call(t, cs, "zerops_logs", map[string]any{"serviceHostname": "x"}); !result.IsError || result.StructuredContent != nil {
+		t.Errorf("logs error: isError=%v structured=%v", result.IsError, result.StructuredContent)
+	}
+}
+
+func TestStructuredOutput_EnvSet(t *testing.T) {
+	mock := executor.NewMockExecutor().
+		WithZaiaResponse("env set", executor.AsyncResult(`[{"processId":"p1","actionName":"serviceStackEnvUpdate","status":"PENDING"}]`))
+	mock.ExpectZaia("process", "p1").Return(executor.SyncResult(`{"processId":"p1","status":"FINISHED"}`))
+	cs := connectAll(t, mock)
+
+	for _, wait := range []bool{false, true} {
+		result := call(t, cs, "zerops_env", map[string]any{
+			"action":            "set",
+			"serviceHostname":   "api",
+			"variables":         []any{"A=1"},
+			"waitForCompletion": wait,
+		})
+		out, _ := result.StructuredContent.(map[string]any)
+		processes, _ := out["processes"].([]any)
+		if result.IsError || len(processes) != 1 {
+			t.Fatalf("wait=%v: isError=%v structured=%v text=%s", wait, result.IsError, result.StructuredContent, getTextContent(t, result))
+		}
+		want := map[bool]string{false: "PENDING", true: "FINISHED"}[wait]
+		if p := processes[0].(map[string]any); p["processId"] != "p1" || p["status"] != want {
+			t.Errorf("wait=%v: process = %v, want status %s", wait, p, want)
+		}
+	}
+}
+
+func TestStructuredOutput_ProcessCancel(t *testing.T) {
+	exec := fakeCLI{state: filepath.Join(t.TempDir(), "state.json")}
+	cs := connectAll(t, exec)
+
+	imported, _ := exec.RunZaia(t.Context(), "import", "--content", "services:\n  - hostname: db\n    type: postgresql@16\n")
+	id := asyncID(t, imported)
+	result := call(t, cs, "zerops_process", map[string]any{"processId": id, "action": "cancel"})
+	if result.IsError || result.StructuredContent == nil {
+		t.Fatalf("cancel: isError=%v structured=%v text=%s", result.IsError, result.StructuredContent, getTextContent(t, result))
+	}
+	if out := result.StructuredContent.(map[string]any); out["processId"] != id {
+		t.Errorf("cancel structured content = %v", out)
+	}
+}
+
+func TestStructuredOutput_TruncatedIsPartial(t *testing.T) {
+	truncated := func(stdout string) *executor.Result {
+		return &executor.Result{
+			Stdout:         []byte(stdout),
+			StdoutOverflow: &executor.Overflow{Size: 1 << 20, Path: filepath.Join(t.TempDir(), "stdout.json")},
+		}
+	}
+	mock := executor.NewMockExecutor().
+		WithZaiaResponse("discover", truncated(`{"type":"sync","status":"ok","data":{"project":{"id":"p","name":"demo","status":"ACTIVE"},"services":[{"serviceId":"s1","hostn`)).
+		WithZaiaResponse("logs", truncated(`{"type":"sync","status":"ok","data":{"entries":[{"timestamp":"2026-01-01T00:00:00Z","severity":"info","message":"starting`))
+	cs := connectAll(t, mock)
+
+	for _, tt := range []struct {
+		tool string
+		args map[string]any
+	}{
+		{"zerops_discover", map[string]any{}},
+		{"zerops_logs", map[string]any{"serviceHostname": "api"}},
+	} {
+		result := call(t, cs, tt.tool, tt.args)
+		out, _ := result.StructuredContent.(map[string]any)
+		if result.IsError || out["partial"] != true {
+			t.Errorf("%s: isError=%v structured=%v, want partial", tt.tool, result.IsError, result.StructuredContent)
+		}
+		if len(result.Content) < 2 {
+			t.Errorf("%s: got %d content blocks, want the truncation note and the output", tt.tool, len(result.Content))
+		}
+	}
+}
+
+func TestStructuredOutput_ProcessCancelAsync(t *testing.T) {
+	mock := executor.NewMockExecutor().
+		WithZaiaResponse("cancel p1", executor.AsyncResult(`[{"processId":"p1","status":"CANCELING"}]`))
+	cs := connectAll(t, mock)
+
+	result := call(t, cs, "zerops_process", map[string]any{"processId": "p1", "action": "cancel"})
+	out, _ := result.StructuredContent.(map[string]any)
+	if result.IsError || out["partial"] != true {
+		t.Errorf("isError=%v structured=%v, want partial", result.IsError, result.StructuredContent)
+	}
+}
@@ -0,0 +1,365 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/zeropsio/zaia-mcp/internal/executor"
+)
+
+// Process statuses reported by `zaia process`, and statusUnknown for a
+// process whose status was not read yet.
+const (
+	statusPending  = "PENDING"
+	statusFinished = "FINISHED"
+	statusFailed   = "FAILED"
+	statusCanceled = "CANCELED"
+	statusUnknown  = "UNKNOWN"
+)
+
+// Wait timeouts for timeoutSeconds.
+const (
+	defaultWaitTimeout = 5 * time.Minute
+	maxWaitTimeout     = 30 * time.Minute
+)
+
+// maxStatusReads caps the concurrent `zaia process` calls of one wait,
+// well below executor.DefaultMaxConcurrent, so a wait for many processes
+// leaves CLI slots for other tool calls.
+const maxStatusReads = 2
+
+// pollBackoff spaces status polls of one process; tests shorten it.
+var pollBackoff = backoff{base: time.Second, max: 10 * time.Second}
+
+// backoff grows the delay by half per attempt, from base up to max.
+type backoff struct {
+	base, max time.Duration
+}
+
+// delay returns the wait after poll attempt n (n >= 1).
+func (b backoff) delay(n int) time.Duration {
+	d := b.base
+	for i := 1; i < n && d < b.max; i++ {
+		d += d / 2
+	}
+	return min(d, b.max)
+}
+
+// processState is the last known state of a waited-for process.
+type processState struct {
+	ProcessID       string `json:"processId"`
+	ActionName      string `json:"actionName,omitempty"`
+	ServiceHostname string `json:"serviceHostname,omitempty"`
+	Status          string `json:"status"`
+	// FailReason is the reason a process failed (including a status read
+	// that failed permanently), or for a process still running at the
+	// timeout the error of its last status read, if that failed.
+	FailReason string `json:"failReason,omitempty"`
+	// DurationMs is created→finished when zaia reports both, otherwise
+	// the time spent waiting.
+	DurationMs int64 `json:"durationMs"`
+}
+
+func (p processState) terminal() bool {
+	switch p.Status {
+	case statusFinished, statusFailed, statusCanceled:
+		return true
+	}
+	return false
+}
+
+func (p processState) failed() bool {
+	return p.terminal() && p.Status != statusFinished
+}
+
+// waitOutcome is the result of waitForProcesses.
+type waitOutcome struct {
+	Processes []processState `json:"processes"`
+	// TimedOut is set when the timeout passed first; unfinished processes
+	// keep running and can be checked with zerops_process.
+	TimedOut bool `json:"timedOut,omitempty"`
+}
+
+func (o waitOutcome) failed() int {
+	n := 0
+	for _, p := range o.Processes {
+		if p.failed() {
+			n++
+		}
+	}
+	return n
+}
+
+// waitOptions are the waitForCompletion/timeoutSeconds inputs of async tools.
+type waitOptions struct {
+	wait    bool
+	timeout time.Duration
+}
+
+func newWaitOptions(wait bool, timeoutSeconds int) waitOptions {
+	return waitOptions{wait: wait, timeout: waitTimeout(timeoutSeconds)}
+}
+
+// waitTimeout converts timeoutSeconds, applying the default and the cap.
+func waitTimeout(seconds int) time.Duration {
+	if seconds <= 0 {
+		return defaultWaitTimeout
+	}
+	return min(time.Duration(seconds)*time.Second, maxWaitTimeout)
+}
+
+// asyncResult converts the CLI result of an async tool. With opts.wait it
+// polls the returned processes until all end or the timeout passes and
+// returns their final states; the result is an error if any failed.
+func asyncResult(ctx context.Context, req *mcp.CallToolRequest, exec executor.Executor, result *executor.Result, opts waitOptions) (*mcp.CallToolResult, any, error) {
+	mcpResult, _ := awaitAsync(ctx, req, exec, result, opts)
+	return mcpResult, nil, nil
+}
+
+// awaitAsync is asyncResult for tools with an output schema. It also
+// returns the processes as a JSON object, {"processes":[...]}: as the CLI
+// returned them, or with opts.wait their final states and timedOut.
+func awaitAsync(ctx context.Context, req *mcp.CallToolRequest, exec executor.Executor, result *executor.Result, opts waitOptions) (*mcp.CallToolResult, []byte) {
+	mcpResult, _ := ResultFromCLI(result)
+	if mcpResult.IsError {
+		return mcpResult, nil
+	}
+	ids := asyncProcessIDs(result)
+	if !opts.wait || len(ids) == 0 {
+		return mcpResult, asyncProcesses(result)
+	}
+	outcome := waitForProcesses(ctx, exec, ids, true, opts.timeout, progressNotifier(ctx, req))
+	data, _ := json.Marshal(outcome)
+	return outcomeResult(outcome), data
+}
+
+// asyncProcesses returns the processes of an async CLI envelope as
+// {"processes":[...]}, or nil for other envelopes.
+func asyncProcesses(result *executor.Result) []byte {
+	resp, err := ParseCLIResponse(result)
+	if err != nil || resp.Type != "async" || len(resp.Processes) == 0 {
+		return nil
+	}
+	data, _ := json.Marshal(struct {
+		Processes json.RawMessage `json:"processes"`
+	}{resp.Processes})
+	return data
+}
+
+// asyncProcessIDs returns the process IDs of an async CLI envelope.
+func asyncProcessIDs(result *executor.Result) []string {
+	resp, err := ParseCLIResponse(result)
+	if err != nil || resp.Type != "async" {
+		return nil
+	}
+	var processes []struct {
+		ProcessID string `json:"processId"`
+	}
+	if json.Unmarshal(resp.Processes, &processes) != nil {
+		return nil
+	}
+	var ids []string
+	for _, p := range processes {
+		if p.ProcessID != "" {
+			ids = append(ids, p.ProcessID)
+		}
+	}
+	return ids
+}
+
+func outcomeResult(outcome waitOutcome) *mcp.CallToolResult {
+	b, _ := json.Marshal(outcome)
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(b)}},
+		IsError: outcome.failed() > 0,
+	}
+}
+
+// waitForProcesses polls `zaia process <id>` for every id, each with
+// pollBackoff and at most maxStatusReads at a time, until all processes (or with all unset, the first
+// one) reach a terminal state, timeout passes or ctx ends. notify is called
+// on every status change.
+func waitForProcesses(ctx context.Context, exec executor.Executor, ids []string, all bool, timeout time.Duration, notify func(done, total int, p processState)) waitOutcome {
+	start := time.Now()
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	states := make(map[string]processState, len(ids))
+	for _, id := range ids {
+		states[id] = processState{ProcessID: id, Status: statusUnknown}
+	}
+
+	updates := make(chan processState)
+	reads := make(chan struct{}, maxStatusReads)
+	var wg sync.WaitGroup
+	for _, id := range ids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pollProcess(waitCtx, exec, id, start, reads, updates)
+		}()
+	}
+
+	done := 0
+	outcome := waitOutcome{}
+loop:
+	for done < len(ids) {
+		select {
+		case st := <-updates:
+			prev := states[st.ProcessID]
+			states[st.ProcessID] = st
+			if st.terminal() {
+				done++
+			}
+			// A process starts out pending, so a first PENDING read is no change.
+			changed := st.Status != prev.Status && (prev.Status != statusUnknown || st.Status != statusPending)
+			if changed && notify != nil {
+				notify(done, len(ids), st)
+			}
+			if st.terminal() && !all {
+				break loop
+			}
+		case <-waitCtx.Done():
+			outcome.TimedOut = ctx.Err() == nil
+			break loop
+		}
+	}
+	cancel()
+	wg.Wait()
+
+	for _, id := range ids {
+		st := states[id]
+		if !st.terminal() {
+			st.DurationMs = time.Since(start).Milliseconds()
+			if !outcome.TimedOut {
+				st.FailReason = ""
+			}
+		}
+		outcome.Processes = append(outcome.Processes, st)
+	}
+	return outcome
+}
+
+// pollProcess sends the state of process id to updates after every poll
+// until it is terminal or ctx ends. A status read failing with a transient
+// CLI error sends the last known state with the error as FailReason and
+// polls on; any other failed read (unknown process, missing binary, auth)
+// ends the process as FAILED. Each read holds a slot of reads.
+func pollProcess(ctx context.Context, exec executor.Executor, id string, start time.Time, reads chan struct{}, updates chan<- processState) {
+	last := processState{ProcessID: id, Status: statusUnknown}
+	for attempt := 1; ; attempt++ {
+		select {
+		case reads <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+		st, err := processStatus(ctx, exec, id)
+		<-reads
+		switch {
+		case err != nil && ctx.Err() != nil:
+			return
+		case err != nil:
+			st = last
+			st.FailReason = err.Error()
+			if !transientStatusError(err) {
+				st.Status = statusFailed
+			}
+		default:
+			last = st
+		}
+		if st.terminal() && st.DurationMs == 0 {
+			st.DurationMs = time.Since(start).Milliseconds()
+		}
+		select {
+		case updates <- st:
+		case <-ctx.Done():
+			return
+		}
+		if st.terminal() {
+			return
+		}
+		select {
+		case <-time.After(pollBackoff.delay(attempt)):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// processStatus reads the state of process id via `zaia process`.
+func processStatus(ctx context.Context, exec executor.Executor, id string) (processState, error) {
+	result, err := exec.RunZaia(ctx, "process", id)
+	if err != nil {
+		return processState{}, err
+	}
+	resp, err := ParseCLIResponse(result)
+	if err != nil {
+		return processState{}, err
+	}
+	if resp.Type == "error" {
+		return processState{}, &statusError{code: resp.Code, message: resp.Error}
+	}
+	var data struct {
+		processState
+		Created  string `json:"created"`
+		Finished string `json:"finished"`
+		Error    string `json:"error"`
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil || data.Status == "" {
+		return processState{}, fmt.Errorf("unrecognized process status: %s", resp.Data)
+	}
+	st := data.processState
+	st.ProcessID = id
+	if st.FailReason == "" && st.failed() {
+		st.FailReason = data.Error
+	}
+	created, err1 := time.Parse(time.RFC3339, data.Created)
+	finished, err2 := time.Parse(time.RFC3339, data.Finished)
+	if err1 == nil && err2 == nil && !finished.Before(created) {
+		st.DurationMs = finished.Sub(created).Milliseconds()
+	}
+	return st, nil
+}
+
+// statusError is an error envelope returned by `zaia process`.
+type statusError struct {
+	code, message string
+}
+
+func (e *statusError) Error() string {
+	return e.code + ": " + e.message
+}
+
+// transientStatusError reports whether a failed status read may succeed
+// when repeated: the CLI returned one of the error codes RetryExecutor
+// treats as transient.
+func transientStatusError(err error) bool {
+	var se *statusError
+	return errors.As(err, &se) && executor.DefaultRetryPolicy().Codes[se.code]
+}
+
+// progressNotifier returns a notify func for waitForProcesses that sends
+// notifications/progress when the request carries a progress token.
+func progressNotifier(ctx context.Context, req *mcp.CallToolRequest) func(done, total int, p processState) {
+	if req == nil || req.Session == nil || req.Params == nil {
+		return nil
+	}
+	token := req.Params.GetProgressToken()
+	if token == nil {
+		return nil
+	}
+	var progress float64
+	return func(done, total int, p processState) {
+		progress++
+		_ = req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+			ProgressToken: token,
+			Progress:      progress,
+			Message:       fmt.Sprintf("%d/%d processes done: %s %s", done, total, p.ProcessID, p.Status),
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zeropsio/zaia-mcp/internal/executor"
+)
+
+// Shorten status polling for every test in the package, including the
+// tools_test ones that call zerops_wait or use waitForCompletion.
+func init() {
+	pollBackoff = backoff{base: time.Millisecond, max: 5 * time.Millisecond}
+}
+
+func processResult(id, status string) *executor.Result {
+	return executor.SyncResult(`{"processId":"` + id + `","actionName":"start","status":"` + status + `"}`)
+}
+
+func TestBackoff_Delay(t *testing.T) {
+	b := backoff{base: time.Second, max: 3 * time.Second}
+	want := []time.Duration{time.Second, 1500 * time.Millisecond, 2250 * time.Millisecond, 3 * time.Second, 3 * time.Second}
+	for i, w := range want {
+		if got := b.delay(i + 1); got != w {
+			t.Errorf("delay(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+}
+
+func TestWaitTimeout(t *testing.T) {
+	tests := []struct {
+		seconds int
+		want    time.Duration
+	}{
+		{0, defaultWaitTimeout},
+		{-5, defaultWaitTimeout},
+		{60, time.Minute},
+		{100000, maxWaitTimeout},
+	}
+	for _, tt := range tests {
+		if got := waitTimeout(tt.seconds); got != tt.want {
+			t.Errorf("waitTimeout(%d) = %v, want %v", tt.seconds, got, tt.want)
+		}
+	}
+}
+
+func TestWaitForProcesses_All(t *testing.T) {
+	mock := executor.NewMockExecutor()
+	mock.ExpectZaia("process", "p1").Return(processResult("p1", "RUNNING"), executor.SyncResult(
+		`{"processId":"p1","status":"FINISHED","created":"2026-01-01T10:00:00Z","finished":"2026-01-01T10:00:42Z"}`))
+	mock.ExpectZaia("process", "p2").Return(processResult("p2", "PENDING"), processResult("p2", "PENDING"),
+		executor.SyncResult(`{"processId":"p2","status":"FAILED","error":"build failed"}`))
+
+	var notified []string
+	outcome := waitForProcesses(t.Context(), mock, []string{"p1", "p2"}, true, time.Second, func(done, total int, p processState) {
+		notified = append(notified, p.ProcessID+" "+p.Status)
+	})
+
+	if outcome.TimedOut || len(outcome.Processes) != 2 {
+		t.Fatalf("outcome = %+v", outcome)
+	}
+	p1, p2 := outcome.Processes[0], outcome.Processes[1]
+	if p1.Status != statusFinished || p1.DurationMs != 42000 {
+		t.Errorf("p1 = %+v", p1)
+	}
+	if p2.Status != statusFailed || p2.FailReason != "build failed" {
+		t.Errorf("p2 = %+v", p2)
+	}
+	if outcome.failed() != 1 {
+		t.Errorf("failed() = %d, want 1", outcome.failed())
+	}
+	// RUNNING→FINISHED for p1 and FAILED for p2; repeated PENDING is not a change.
+	if len(notified) != 3 {
+		t.Errorf("notified %v, want 3 status changes", notified)
+	}
+}
+
+func TestWaitForProcesses_Any(t *testing.T) {
+	mock := executor.NewMockExecutor()
+	mock.ExpectZaia("process", "p1").Return(processResult("p1", "RUNNING"))
+	mock.ExpectZaia("process", "p2").Return(processResult("p2", "RUNNING"), processResult("p2", "FINISHED"))
+
+	outcome := waitForProcesses(t.Context(), mock, []string{"p1", "p2"}, false, time.Second, nil)
+
+	if outcome.TimedOut {
+		t.Fatal("timed out")
+	}
+	// p2 needs two polls, so the wait took at least one backoff delay.
+	if got := outcome.Processes[0]; got.terminal() || got.DurationMs <= 0 {
+		t.Errorf("p1 = %+v, want unfinished with the waited duration", got)
+	}
+	if got := outcome.Processes[1]; got.Status != statusFinished {
+		t.Errorf("p2 = %+v, want FINISHED", got)
+	}
+}
+
+func TestWaitForProcesses_Timeout(t *testing.T) {
+	mock := executor.NewMockExecutor()
+	mock.ExpectZaia("process", "p1").Return(processResult("p1", "RUNNING"))
+
+	outcome := waitForProcesses(t.Context(), mock, []string{"p1"}, true, 30*time.Millisecond, nil)
+
+	if !outcome.TimedOut || outcome.Processes[0].Status != "RUNNING" {
+		t.Errorf("outcome = %+v, want timed out while RUNNING", outcome)
+	}
+	if outcome.failed() != 0 {
+		t.Error("an unfinished process must not count as failed")
+	}
+}
+
+func TestWaitForProcesses_StatusErrors(t *testing.T) {
+	mock := executor.NewMockExecutor()
+	mock.ExpectZaia("process", "p1").Return(executor.ErrorResult("PROCESS_NOT_FOUND", "no such process", "", 1))
+	mock.ExpectZaia("process", "p2").ReturnError(errors.New("zaia not found"))
+	mock.ExpectZaia("process", "p3").Return(executor.ErrorResult("NETWORK_ERROR", "connection reset", "", 1))
+
+	outcome := waitForProcesses(t.Context(), mock, []string{"p1", "p2"}, true, time.Minute, nil)
+
+	if outcome.TimedOut {
+		t.Error("permanent status errors should end the wait at once")
+	}
+	if got := outcome.Processes[0]; got.Status != statusFailed || got.FailReason != "PROCESS_NOT_FOUND: no such process" {
+		t.Errorf("p1 = %+v", got)
+	}
+	if got := outcome.Processes[1]; got.Status != statusFailed || got.FailReason != "zaia not found" {
+		t.Errorf("p2 = %+v", got)
+	}
+	if n := mock.CallCount("zaia", "process p1"); n != 1 {
+		t.Errorf("p1 polled %d times, want 1", n)
+	}
+
+	// A transient error is retried until the timeout; the process was never
+	// read, so its status is unknown.
+	outcome = waitForProcesses(t.Context(), mock, []string{"p3"}, true, 100*time.Millisecond, nil)
+	if got := outcome.Processes[0]; !outcome.TimedOut || got.Status != statusUnknown || got.FailReason != "NETWORK_ERROR: connection reset" {
+		t.Errorf("p3 outcome = %+v", outcome)
+	}
+	if outcome.failed() != 0 {
+		t.Errorf("failed() = %d, want 0", outcome.failed())
+	}
+	if n := mock.CallCount("zaia", "process p3"); n < 2 {
+		t.Errorf("p3 polled %d times, want retries until the timeout", n)
+	}
+}
+
+func TestWaitForProcesses_StatusErrorRecovers(t *testing.T) {
+	mock := executor.NewMockExecutor()
+	mock.ExpectZaia("process", "p1").Return(
+		executor.ErrorResult("NETWORK_ERROR", "connection reset", "", 1),
+		executor.SyncResult(`{"processId":"p1","status":"RUNNING"}`),
+		executor.ErrorResult("NETWORK_ERROR", "connection reset", "", 1),
+		executor.SyncResult(`{"processId":"p1","status":"FINISHED"}`),
+	)
+
+	outcome := waitForProcesses(t.Context(), mock, []string{"p1"}, true, time.Second, nil)
+
+	if got := outcome.Processes[0]; outcome.TimedOut || got.Status != statusFinished || got.FailReason != "" {
+		t.Errorf("outcome = %+v, want p1 FINISHED without failReason", outcome)
+	}
+}
+
+func TestWaitForProcesses_CapsStatusReads(t *testing.T) {
+	mock := executor.NewMockExecutor().WithDefault(processResult("p", "FINISHED"))
+	var running, peak atomic.Int32
+	exec := executor.Intercept(func(ctx context.Context, binary string, args []string, next executor.RunFunc) (*executor.Result, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+		}
+		time.Sleep(5 * time.Millisecond)
+		return next(ctx, args...)
+	})(mock)
+
+	ids := []string{"p1", "p2", "p3", "p4", "p5", "p6"}
+	outcome := waitForProcesses(t.Context(), exec, ids, true, time.Second, nil)
+
+	if outcome.TimedOut || len(mock.Calls) != len(ids) {
+		t.Fatalf("outcome = %+v after %d calls", outcome, len(mock.Calls))
+	}
+	if got := peak.Load(); got > maxStatusReads {
+		t.Errorf("%d concurrent status reads, want at most %d", got, maxStatusReads)
+	}
+}
@@ -11,12 +11,33 @@ import (
 type ProcessInput struct {
 	ProcessID string `json:"processId"`
 	Action    string `json:"action,omitempty"` // "status" (default) or "cancel"
+	Profile   string `json:"profile,omitempty" jsonschema:"config profile to use (see zerops_profiles)"`
 }
 
+// ProcessOutput is the structured output of zerops_process.
+type ProcessOutput struct {
+	ProcessID       string `json:"processId"`
+	ActionName      string `json:"actionName,omitempty"`
+	ServiceHostname string `json:"serviceHostname,omitempty"`
+	Status          string `json:"status" jsonschema:"PENDING, RUNNING, FINISHED, FAILED or CANCELED"`
+	Created         string `json:"created,omitempty"`
+	Finished        string `json:"finished,omitempty"`
+	FailReason      string `json:"failReason,omitempty"`
+}
+
+// processToolOutput is ProcessOutput as returned by zerops_process.
+type processToolOutput struct {
+	ProcessOutput
+	partial
+}
+
+var processOutput = newToolOutput[processToolOutput]()
+
 // RegisterProcess registers the zerops_process tool on the server.
 func RegisterProcess(srv *mcp.Server, exec executor.Executor) {
 	mcp.AddTool(srv, &mcp.Tool{
-		Name: "zerops_process",
+		Name:         "zerops_process",
+		OutputSchema: processOutput.schema,
 		Annotations: &mcp.ToolAnnotations{
 			Title:          "Check Process",
 			ReadOnlyHint:   true,
@@ -28,11 +49,15 @@ Actions:
 - status (default): Check process status
 - cancel: Cancel a running or pending process
 
-Used internally by MCP for waitForCompletion polling.
+Async tools poll it themselves with waitForCompletion=true.
 Can also be used directly by agent to check operation status.
 
+Parameters:
+- profile: Config profile to use (optional, see zerops_profiles)
+
 Process statuses: PENDING, RUNNING, FINISHED, FAILED, CANCELED`,
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input ProcessInput) (*mcp.CallToolResult, any, error) {
+		ctx = executor.WithProfile(ctx, input.Profile)
 		if input.ProcessID == "" {
 			return errorResult("processId is required"), nil, nil
 		}
@@ -57,6 +82,6 @@ Process statuses: PENDING, RUNNING, FINISHED, FAILED, CANCELED`,
 			return cliErrorResult(err)
 		}
 		mcpResult, _ := ResultFromCLI(result)
-		return mcpResult, nil, nil
+		return mcpResult, processOutput.structured(mcpResult, syncData(result)), nil
 	})
 }
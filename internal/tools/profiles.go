@@ -0,0 +1,46 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/zeropsio/zaia-mcp/internal/executor"
+)
+
+// RegisterProfiles registers the zerops_profiles tool on the server.
+// profiles may be nil when no profiles are configured.
+func RegisterProfiles(srv *mcp.Server, profiles *executor.ProfileExecutor) {
+	mcp.AddTool(srv, &mcp.Tool{
+		Name: "zerops_profiles",
+		Annotations: &mcp.ToolAnnotations{
+			Title:          "List Profiles",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+			OpenWorldHint:  boolPtr(false),
+		},
+		Description: `List configured profiles (Zerops projects this server can target).
+
+Pass profile=<name> to any other tool to run it against that project.
+Calls without a profile use the default profile.
+
+Returns profiles with name, description, projectId and default flag.
+Credentials are never shown.`,
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, any, error) {
+		list := []executor.ProfileInfo{}
+		if profiles != nil {
+			list = profiles.Profiles()
+		}
+		out := map[string]any{"profiles": list, "count": len(list)}
+		if len(list) == 0 {
+			out["note"] = "No profiles configured; all tools use the project zaia is logged into."
+		}
+		b, err := json.Marshal(out)
+		if err != nil {
+			return errorResult("encoding profiles: " + err.Error()), nil, nil
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: string(b)}},
+		}, nil, nil
+	})
+}
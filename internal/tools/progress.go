@@ -0,0 +1,51 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/zeropsio/zaia-mcp/internal/executor"
+)
+
+// withOutputNotifications streams CLI output of the call to the client:
+// each line becomes a notifications/message log entry from logger and, when
+// the request carries a progress token, a notifications/progress update.
+// The JSON result envelope on stdout is not forwarded.
+func withOutputNotifications(ctx context.Context, req *mcp.CallToolRequest, logger string) context.Context {
+	if req == nil || req.Session == nil {
+		return ctx
+	}
+	session := req.Session
+	var token any
+	if req.Params != nil {
+		token = req.Params.GetProgressToken()
+	}
+	var progress float64
+	return executor.WithLineHandler(ctx, func(stream executor.Stream, line string) {
+		if stream == executor.Stdout && isJSONObject(line) {
+			return
+		}
+		// Notification failures (client gone, level filtered) must not
+		// disturb the running command.
+		_ = session.Log(ctx, &mcp.LoggingMessageParams{
+			Level:  "info",
+			Logger: logger,
+			Data:   line,
+		})
+		if token != nil {
+			progress++
+			_ = session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+				ProgressToken: token,
+				Progress:      progress,
+				Message:       line,
+			})
+		}
+	})
+}
+
+func isJSONObject(line string) bool {
+	line = strings.TrimSpace(line)
+	return strings.HasPrefix(line, "{") && json.Valid([]byte(line))
+}
@@ -9,8 +9,11 @@ import (
 
 // SubdomainInput is the input schema for zerops_subdomain.
 type SubdomainInput struct {
-	ServiceHostname string `json:"serviceHostname"`
-	Action          string `json:"action"` // "enable" or "disable"
+	ServiceHostname   string `json:"serviceHostname"`
+	Action            string `json:"action"` // "enable" or "disable"
+	WaitForCompletion bool   `json:"waitForCompletion,omitempty" jsonschema:"poll the returned processes until they end and return their final states"`
+	TimeoutSeconds    int    `json:"timeoutSeconds,omitempty" jsonschema:"max wait with waitForCompletion in seconds (default 300, max 1800)"`
+	Profile           string `json:"profile,omitempty" jsonschema:"config profile to use (see zerops_profiles)"`
 }
 
 // RegisterSubdomain registers the zerops_subdomain tool on the server.
@@ -28,8 +31,14 @@ Actions:
 - enable: Create a *.zerops.app subdomain
 - disable: Remove the subdomain
 
-Idempotent: enabling an already enabled subdomain returns success.`,
+Parameters:
+- profile: Config profile to use (optional, see zerops_profiles)
+
+Idempotent: enabling an already enabled subdomain returns success.
+
+With waitForCompletion=true waits for the process and returns its final state.`,
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input SubdomainInput) (*mcp.CallToolResult, any, error) {
+		ctx = executor.WithProfile(ctx, input.Profile)
 		if input.ServiceHostname == "" {
 			return errorResult("serviceHostname is required"), nil, nil
 		}
@@ -42,7 +51,6 @@ Idempotent: enabling an already enabled subdomain returns success.`,
 		if err != nil {
 			return cliErrorResult(err)
 		}
-		mcpResult, _ := ResultFromCLI(result)
-		return mcpResult, nil, nil
+		return asyncResult(ctx, req, exec, result, newWaitOptions(input.WaitForCompletion, input.TimeoutSeconds))
 	})
 }
@@ -12,12 +12,24 @@ type ValidateInput struct {
 	Content  string `json:"content,omitempty"`
 	FilePath string `json:"filePath,omitempty"`
 	Type     string `json:"type,omitempty"`
+	Profile  string `json:"profile,omitempty" jsonschema:"config profile to use (see zerops_profiles)"`
 }
 
+// ValidateOutput is the structured output of zerops_validate.
+type ValidateOutput struct {
+	Valid    bool   `json:"valid"`
+	Type     string `json:"type" jsonschema:"zerops.yml or import.yml"`
+	Services int    `json:"services,omitempty" jsonschema:"services defined, for import.yml"`
+	partial
+}
+
+var validateOutput = newToolOutput[ValidateOutput]()
+
 // RegisterValidate registers the zerops_validate tool on the server.
 func RegisterValidate(srv *mcp.Server, exec executor.Executor) {
 	mcp.AddTool(srv, &mcp.Tool{
-		Name: "zerops_validate",
+		Name:         "zerops_validate",
+		OutputSchema: validateOutput.schema,
 		Annotations: &mcp.ToolAnnotations{
 			Title:          "Validate Config",
 			ReadOnlyHint:   true,
@@ -35,8 +47,12 @@ Validates zerops.yml or import.yml for:
 
 In project-scoped context, import.yml must NOT contain 'project:' section.
 
+Parameters:
+- profile: Config profile to use (optional, see zerops_profiles)
+
 Returns errors with fix suggestions.`,
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input ValidateInput) (*mcp.CallToolResult, any, error) {
+		ctx = executor.WithProfile(ctx, input.Profile)
 		args := []string{"validate"}
 		if input.Content != "" {
 			args = append(args, "--content", input.Content)
@@ -52,6 +68,6 @@ Returns errors with fix suggestions.`,
 			return cliErrorResult(err)
 		}
 		mcpResult, _ := ResultFromCLI(result)
-		return mcpResult, nil, nil
+		return mcpResult, validateOutput.structured(mcpResult, syncData(result)), nil
 	})
 }
@@ -0,0 +1,76 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/zeropsio/zaia-mcp/internal/executor"
+)
+
+// maxWaitProcesses caps the process IDs of one zerops_wait call.
+const maxWaitProcesses = 50
+
+// WaitInput is the input schema for zerops_wait.
+type WaitInput struct {
+	ProcessIDs     []string `json:"processIds" jsonschema:"IDs of the processes to wait for"`
+	Mode           string   `json:"mode,omitempty" jsonschema:"all (default): return when every process ended; any: return when the first one ended"`
+	TimeoutSeconds int      `json:"timeoutSeconds,omitempty" jsonschema:"max wait in seconds (default 300, max 1800)"`
+	Profile        string   `json:"profile,omitempty" jsonschema:"config profile to use (see zerops_profiles)"`
+}
+
+// RegisterWait registers the zerops_wait tool on the server.
+func RegisterWait(srv *mcp.Server, exec executor.Executor) {
+	mcp.AddTool(srv, &mcp.Tool{
+		Name: "zerops_wait",
+		Annotations: &mcp.ToolAnnotations{
+			Title:          "Wait for Processes",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+		},
+		Description: `Wait for several async processes at once.
+
+Polls the processes (two status reads at a time) until all of them
+(mode=all, default) or the first one (mode=any) end, or timeoutSeconds
+passes (default 300). An unknown process ID fails at once.
+Each status change is reported as a progress notification.
+
+Use it after zerops_import or several async calls instead of calling
+zerops_process once per process.
+
+Parameters:
+- profile: Config profile to use (optional, see zerops_profiles)
+
+Returns per process: processId, actionName, serviceHostname, status,
+failReason, durationMs; timedOut is set when the timeout passed first.
+The result is an error if any ended process FAILED or was CANCELED.`,
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input WaitInput) (*mcp.CallToolResult, any, error) {
+		ctx = executor.WithProfile(ctx, input.Profile)
+
+		var ids []string
+		for _, id := range input.ProcessIDs {
+			if id != "" && !slices.Contains(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) == 0 {
+			return errorResult("processIds must contain at least one process ID"), nil, nil
+		}
+		if len(ids) > maxWaitProcesses {
+			return errorResult(fmt.Sprintf("at most %d processIds per call", maxWaitProcesses)), nil, nil
+		}
+
+		var all bool
+		switch input.Mode {
+		case "", "all":
+			all = true
+		case "any":
+		default:
+			return errorResult("mode must be 'all' or 'any'"), nil, nil
+		}
+
+		outcome := waitForProcesses(ctx, exec, ids, all, waitTimeout(input.TimeoutSeconds), progressNotifier(ctx, req))
+		return outcomeResult(outcome), nil, nil
+	})
+}
@@ -0,0 +1,154 @@
+package tools_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zeropsio/zaia-mcp/internal/executor"
+	"github.com/zeropsio/zaia-mcp/internal/tools"
+)
+
+type waitResult struct {
+	Processes []struct {
+		ProcessID  string `json:"processId"`
+		Status     string `json:"status"`
+		FailReason string `json:"failReason"`
+		DurationMs int64  `json:"durationMs"`
+	} `json:"processes"`
+	TimedOut bool `json:"timedOut"`
+}
+
+func parseWait(t *testing.T, text string) waitResult {
+	t.Helper()
+	var r waitResult
+	if err := json.Unmarshal([]byte(text), &r); err != nil {
+		t.Fatalf("result %q: %v", text, err)
+	}
+	return r
+}
+
+func TestWait_All(t *testing.T) {
+	mock := executor.NewMockExecutor()
+	mock.ExpectZaia("process", "p1").Return(
+		executor.SyncResult(`{"processId":"p1","status":"RUNNING"}`),
+		executor.SyncResult(`{"processId":"p1","status":"FINISHED","created":"2026-01-01T10:00:00Z","finished":"2026-01-01T10:01:00Z"}`),
+	)
+	mock.ExpectZaia("process", "p2").Return(executor.SyncResult(`{"processId":"p2","status":"FINISHED"}`))
+	srv := testServer(t, tools.RegisterWait, mock)
+
+	result := callTool(t, srv, "zerops_wait", map[string]interface{}{
+		"processIds": []any{"p1", "p2", "p1"},
+	})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", getTextContent(t, result))
+	}
+	r := parseWait(t, getTextContent(t, result))
+	if r.TimedOut || len(r.Processes) != 2 {
+		t.Fatalf("result = %+v, want 2 processes (duplicates dropped)", r)
+	}
+	if p := r.Processes[0]; p.ProcessID != "p1" || p.Status != "FINISHED" || p.DurationMs != 60000 {
+		t.Errorf("p1 = %+v", p)
+	}
+	if p := r.Processes[1]; p.ProcessID != "p2" || p.Status != "FINISHED" {
+		t.Errorf("p2 = %+v", p)
+	}
+}
+
+func TestWait_Any(t *testing.T) {
+	mock := executor.NewMockExecutor()
+	mock.ExpectZaia("process", "p1").Return(executor.SyncResult(`{"processId":"p1","status":"RUNNING"}`))
+	mock.ExpectZaia("process", "p2").Return(executor.SyncResult(`{"processId":"p2","status":"FINISHED"}`))
+	srv := testServer(t, tools.RegisterWait, mock)
+
+	result := callTool(t, srv, "zerops_wait", map[string]interface{}{
+		"processIds": []any{"p1", "p2"},
+		"mode":       "any",
+	})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", getTextContent(t, result))
+	}
+	r := parseWait(t, getTextContent(t, result))
+	// p1 is PENDING or RUNNING depending on whether it was polled yet.
+	if r.TimedOut || r.Processes[0].Status == "FINISHED" || r.Processes[1].Status != "FINISHED" {
+		t.Errorf("result = %+v, want p1 unfinished and p2 FINISHED", r)
+	}
+}
+
+func TestWait_Failed(t *testing.T) {
+	mock := executor.NewMockExecutor()
+	mock.ExpectZaia("process", "p1").Return(executor.SyncResult(`{"processId":"p1","status":"FINISHED"}`))
+	mock.ExpectZaia("process", "p2").Return(
+		executor.SyncResult(`{"processId":"p2","status":"FAILED","failReason":"service db no longer exists"}`))
+	srv := testServer(t, tools.RegisterWait, mock)
+
+	result := callTool(t, srv, "zerops_wait", map[string]interface{}{
+		"processIds": []any{"p1", "p2"},
+	})
+	if !result.IsError {
+		t.Error("expected an error result for a failed process")
+	}
+	r := parseWait(t, getTextContent(t, result))
+	if p := r.Processes[1]; p.Status != "FAILED" || p.FailReason != "service db no longer exists" {
+		t.Errorf("p2 = %+v", p)
+	}
+}
+
+func TestWait_UnknownProcessFailsAtOnce(t *testing.T) {
+	mock := executor.NewMockExecutor()
+	mock.ExpectZaia("process", "p1").Return(executor.SyncResult(`{"processId":"p1","status":"FINISHED"}`))
+	mock.ExpectZaia("process", "typo").Return(executor.ErrorResult("PROCESS_NOT_FOUND", "process typo not found", "", 1))
+	srv := testServer(t, tools.RegisterWait, mock)
+
+	start := time.Now()
+	result := callTool(t, srv, "zerops_wait", map[string]interface{}{
+		"processIds": []any{"p1", "typo"},
+	})
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("wait took %s, want an immediate failure", elapsed)
+	}
+	if !result.IsError {
+		t.Error("expected an error result for an unknown process")
+	}
+	r := parseWait(t, getTextContent(t, result))
+	if p := r.Processes[1]; r.TimedOut || p.Status != "FAILED" || !strings.Contains(p.FailReason, "PROCESS_NOT_FOUND") {
+		t.Errorf("result = %+v", r)
+	}
+}
+
+func TestWait_Timeout(t *testing.T) {
+	mock := executor.NewMockExecutor()
+	mock.ExpectZaia("process", "p1").Return(executor.SyncResult(`{"processId":"p1","status":"RUNNING"}`))
+	srv := testServer(t, tools.RegisterWait, mock)
+
+	result := callTool(t, srv, "zerops_wait", map[string]interface{}{
+		"processIds":     []any{"p1"},
+		"timeoutSeconds": 1,
+	})
+	if result.IsError {
+		t.Errorf("a timeout is not an error: %s", getTextContent(t, result))
+	}
+	if r := parseWait(t, getTextContent(t, result)); !r.TimedOut || r.Processes[0].DurationMs < 1000 {
+		t.Errorf("result = %+v, want timedOut after 1s", r)
+	}
+}
+
+func TestWait_InvalidInput(t *testing.T) {
+	srv := testServer(t, tools.RegisterWait, executor.NewMockExecutor())
+	tests := []struct {
+		args map[string]interface{}
+		want string
+	}{
+		{map[string]interface{}{"processIds": []any{}}, "at least one"},
+		{map[string]interface{}{"processIds": []any{""}}, "at least one"},
+		{map[string]interface{}{"processIds": []any{"p1"}, "mode": "first"}, "mode must be"},
+	}
+	for _, tt := range tests {
+		result := callTool(t, srv, "zerops_wait", tt.args)
+		if !result.IsError || !strings.Contains(getTextContent(t, result), tt.want) {
+			t.Errorf("%v: got %s, want error containing %q", tt.args, getTextContent(t, result), tt.want)
+		}
+	}
+	callToolExpectError(t, srv, "zerops_wait", nil)
+}